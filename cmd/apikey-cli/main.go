@@ -0,0 +1,99 @@
+// cmd/apikey-cli/main.go
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/sammyqtran/url-shortener/internal/auth"
+	pb "github.com/sammyqtran/url-shortener/proto"
+)
+
+const usage = `Usage:
+  apikey-cli [-addr host:port] issue -user <user_id> [-name <label>]
+  apikey-cli [-addr host:port] revoke -user <user_id> -id <key_id>`
+
+func main() {
+	addr := flag.String("addr", getEnv("URL_SERVICE_ADDR", "localhost:50051"), "url-service gRPC address")
+	flag.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		fail(fmt.Errorf("failed to connect to %s: %w", *addr, err))
+	}
+	defer conn.Close()
+
+	client := pb.NewAPIKeyServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = auth.WithAdminToken(ctx, getEnv("ADMIN_TOKEN", ""))
+
+	switch cmd, args := flag.Arg(0), flag.Args()[1:]; cmd {
+	case "issue":
+		err = issue(ctx, client, args)
+	case "revoke":
+		err = revoke(ctx, client, args)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func issue(ctx context.Context, client pb.APIKeyServiceClient, args []string) error {
+	fs := flag.NewFlagSet("issue", flag.ExitOnError)
+	userID := fs.String("user", "", "user ID that owns the key")
+	name := fs.String("name", "", "optional label for the key")
+	fs.Parse(args)
+
+	resp, err := client.IssueAPIKey(ctx, &pb.IssueAPIKeyRequest{UserId: *userID, Name: *name})
+	if err != nil {
+		return fmt.Errorf("issue failed: %w", err)
+	}
+
+	fmt.Printf("key id:  %d\n", resp.KeyId)
+	fmt.Printf("prefix:  %s\n", resp.Prefix)
+	fmt.Printf("api key: %s\n", resp.ApiKey)
+	fmt.Println("Store the key now, it cannot be shown again.")
+	return nil
+}
+
+func revoke(ctx context.Context, client pb.APIKeyServiceClient, args []string) error {
+	fs := flag.NewFlagSet("revoke", flag.ExitOnError)
+	userID := fs.String("user", "", "user ID that owns the key")
+	keyID := fs.Int64("id", 0, "ID of the key to revoke")
+	fs.Parse(args)
+
+	if _, err := client.RevokeAPIKey(ctx, &pb.RevokeAPIKeyRequest{KeyId: *keyID, UserId: *userID}); err != nil {
+		return fmt.Errorf("revoke failed: %w", err)
+	}
+
+	fmt.Printf("revoked key %d\n", *keyID)
+	return nil
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(1)
+}
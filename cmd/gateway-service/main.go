@@ -2,16 +2,19 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
-	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
+	"github.com/sammyqtran/url-shortener/internal/auth"
 	"github.com/sammyqtran/url-shortener/internal/gateway"
 	"github.com/sammyqtran/url-shortener/internal/metrics"
 	"github.com/sammyqtran/url-shortener/internal/queue"
+	"github.com/sammyqtran/url-shortener/internal/ratelimit"
 	pb "github.com/sammyqtran/url-shortener/proto"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -58,6 +61,7 @@ func main() {
 	publisher := queue.NewPublisher(messageQueue, streamConfig.URLEventsStream)
 
 	grpcClient := pb.NewURLServiceClient(conn)
+	authClient := pb.NewAPIKeyServiceClient(conn)
 
 	// create prometheus object and add to struct
 
@@ -65,11 +69,70 @@ func main() {
 	// TODO Add metrics collector here
 	server := &gateway.GatewayServer{
 		GrpcClient: grpcClient,
+		AuthClient: authClient,
 		Publisher:  publisher,
 		Logger:     logger,
 		Metrics:    metrics,
 	}
 
+	// forwarding headers are ignored unless the peer is a listed proxy
+	trustedProxies, err := gateway.ParseTrustedProxies(getEnv("TRUSTED_PROXIES", ""))
+	if err != nil {
+		logger.Fatal("Invalid TRUSTED_PROXIES", zap.Error(err))
+	}
+	server.TrustedProxies = trustedProxies
+
+	// rate limits are shared across replicas through the same Redis instance
+	server.RateLimiter = ratelimit.NewLimiter(redisClient)
+	server.CreateLimit = ratelimit.Limit{
+		Requests: getEnvAsInt("RATE_LIMIT_CREATE_REQUESTS", 30),
+		Window:   getEnvAsDuration("RATE_LIMIT_CREATE_WINDOW", time.Minute),
+	}
+	server.RedirectLimit = ratelimit.Limit{
+		Requests: getEnvAsInt("RATE_LIMIT_REDIRECT_REQUESTS", 600),
+		Window:   getEnvAsDuration("RATE_LIMIT_REDIRECT_WINDOW", time.Minute),
+	}
+	server.AuthLimit = ratelimit.Limit{
+		Requests: getEnvAsInt("RATE_LIMIT_AUTH_REQUESTS", 300),
+		Window:   getEnvAsDuration("RATE_LIMIT_AUTH_WINDOW", time.Minute),
+	}
+	for name, limit := range map[string]ratelimit.Limit{
+		"create":   server.CreateLimit,
+		"redirect": server.RedirectLimit,
+		"auth":     server.AuthLimit,
+	} {
+		if err := limit.Validate(); err != nil {
+			logger.Fatal("Invalid rate limit", zap.String("policy", name), zap.Error(err))
+		}
+	}
+
+	// 301 and 308 redirects may be cached by clients, skipping click counting
+	server.PermanentRedirectMaxAge = getEnvAsDuration("PERMANENT_REDIRECT_MAX_AGE", 365*24*time.Hour)
+
+	// visitor cookies are signed so visitors can't choose their A/B variant,
+	// replicas need the same secret to accept each other's cookies
+	if secret := getEnv("VISITOR_COOKIE_SECRET", ""); secret != "" {
+		server.VisitorSecret = []byte(secret)
+	} else {
+		server.VisitorSecret = make([]byte, 32)
+		if _, err := rand.Read(server.VisitorSecret); err != nil {
+			logger.Fatal("Failed to generate visitor cookie secret", zap.Error(err))
+		}
+		logger.Warn("VISITOR_COOKIE_SECRET is not set, visitor cookies won't survive a restart or work across replicas")
+	}
+
+	// JWT authentication is enabled when a JWKS file path or URL is configured
+	if jwksSource := getEnv("JWKS_URL", ""); jwksSource != "" {
+		jwks, err := auth.NewJWKS(ctx, jwksSource, logger)
+		if err != nil {
+			logger.Fatal("Failed to load JWKS", zap.Error(err))
+		}
+		go jwks.Run(context.Background(), getEnvAsDuration("JWKS_REFRESH_INTERVAL", 15*time.Minute))
+
+		server.JWTVerifier = auth.NewJWTVerifier(jwks, getEnv("JWT_ISSUER", ""), getEnv("JWT_AUDIENCE", ""))
+		logger.Info("JWT authentication enabled", zap.String("jwks", jwksSource))
+	}
+
 	//go routine to serve metrics on 2112
 	go func() {
 		http.Handle("/metrics", promhttp.Handler())
@@ -78,11 +141,7 @@ func main() {
 		}
 	}()
 
-	r := mux.NewRouter()
-
-	r.HandleFunc("/create", server.HandleCreateShortURL).Methods("POST")
-	r.HandleFunc("/healthz", server.HandleHealthCheck).Methods("GET")
-	r.HandleFunc("/{shortCode}", server.HandleGetOriginalURL).Methods("GET")
+	r := server.NewRouter()
 
 	logger.Info("Gateway service listening on :8080")
 	err = http.ListenAndServe(":8080", r)
@@ -99,3 +158,21 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvAsInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil && duration > 0 {
+			return duration
+		}
+	}
+	return defaultValue
+}
@@ -13,6 +13,10 @@ import (
 
 func main() {
 	baseURL := "http://localhost:8080"
+	apiKey := os.Getenv("API_KEY")
+	if apiKey == "" {
+		fail(fmt.Errorf("API_KEY must be set, issue one with apikey-cli"))
+	}
 
 	fmt.Println("[*] Starting health check test...")
 	if err := testHealth(baseURL + "/healthz"); err != nil {
@@ -21,7 +25,7 @@ func main() {
 	fmt.Println("[+] Health check passed")
 
 	fmt.Println("[*] Starting create short URL test...")
-	shortCode, err := testCreate(baseURL + "/create")
+	shortCode, err := testCreate(baseURL+"/create", apiKey)
 	if err != nil {
 		fail(err)
 	}
@@ -53,12 +57,19 @@ func testHealth(url string) error {
 	return nil
 }
 
-func testCreate(url string) (string, error) {
+func testCreate(url, apiKey string) (string, error) {
 	payload := map[string]string{"url": "https://example.com"}
 	body, _ := json.Marshal(payload)
 
 	fmt.Printf("-> Sending POST request to %s with body: %s\n", url, string(body))
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(body))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to build create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("create request failed: %w", err)
 	}
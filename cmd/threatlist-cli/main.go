@@ -0,0 +1,125 @@
+// cmd/threatlist-cli/main.go
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/sammyqtran/url-shortener/internal/auth"
+	"github.com/sammyqtran/url-shortener/internal/threatlist"
+	pb "github.com/sammyqtran/url-shortener/proto"
+)
+
+const usage = `Usage:
+  threatlist-cli [-addr host:port] add -file <prefixes.txt>
+  threatlist-cli [-addr host:port] remove -file <prefixes.txt>
+  threatlist-cli [-addr host:port] replace -file <prefixes.txt>
+  threatlist-cli hash -url <url>
+
+Prefix files hold one hex-encoded SHA-256 hash prefix (4-32 bytes) per line.`
+
+func main() {
+	addr := flag.String("addr", getEnv("URL_SERVICE_ADDR", "localhost:50051"), "url-service gRPC address")
+	flag.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cmd, args := flag.Arg(0), flag.Args()[1:]
+	if cmd == "hash" {
+		if err := hash(args); err != nil {
+			fail(err)
+		}
+		return
+	}
+	if cmd != "add" && cmd != "remove" && cmd != "replace" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		fail(fmt.Errorf("failed to connect to %s: %w", *addr, err))
+	}
+	defer conn.Close()
+
+	client := pb.NewThreatListServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	ctx = auth.WithAdminToken(ctx, getEnv("ADMIN_TOKEN", ""))
+
+	if err := update(ctx, client, cmd, args); err != nil {
+		fail(err)
+	}
+}
+
+func update(ctx context.Context, client pb.ThreatListServiceClient, cmd string, args []string) error {
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	path := fs.String("file", "", "file of hex-encoded hash prefixes")
+	fs.Parse(args)
+
+	file, err := os.Open(*path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	prefixes, err := threatlist.ParsePrefixes(file)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *path, err)
+	}
+
+	req := &pb.UpdateThreatListRequest{Additions: prefixes}
+	switch cmd {
+	case "remove":
+		req = &pb.UpdateThreatListRequest{Removals: prefixes}
+	case "replace":
+		req.Replace = true
+	}
+
+	resp, err := client.UpdateThreatList(ctx, req)
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", cmd, err)
+	}
+
+	fmt.Printf("added: %d, removed: %d, total: %d\n", resp.Added, resp.Removed, resp.Total)
+	return nil
+}
+
+// hash prints the expressions of a URL with their full hashes, ready to be listed
+func hash(args []string) error {
+	fs := flag.NewFlagSet("hash", flag.ExitOnError)
+	rawURL := fs.String("url", "", "URL to hash")
+	fs.Parse(args)
+
+	expressions, err := threatlist.Expressions(*rawURL)
+	if err != nil {
+		return err
+	}
+	for _, expression := range expressions {
+		fmt.Printf("%s  %s\n", hex.EncodeToString(threatlist.HashPrefix(expression, threatlist.MaxPrefixLength)), expression)
+	}
+	return nil
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(1)
+}
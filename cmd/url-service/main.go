@@ -4,8 +4,10 @@ import (
 	"context"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -14,10 +16,14 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
+	"github.com/sammyqtran/url-shortener/internal/auth"
 	"github.com/sammyqtran/url-shortener/internal/database"
+	"github.com/sammyqtran/url-shortener/internal/geoip"
 	"github.com/sammyqtran/url-shortener/internal/metrics"
 	"github.com/sammyqtran/url-shortener/internal/repository/postgres"
 	"github.com/sammyqtran/url-shortener/internal/service"
+	"github.com/sammyqtran/url-shortener/internal/threatlist"
+	"github.com/sammyqtran/url-shortener/internal/urlpolicy"
 	pb "github.com/sammyqtran/url-shortener/proto"
 )
 
@@ -56,6 +62,7 @@ func main() {
 
 	// create a new URL repository instance
 	urlRepo := postgres.NewPostgresURLRepository(db, logger)
+	apiKeyRepo := postgres.NewPostgresAPIKeyRepository(db, logger)
 
 	// Create a Redis client and connect to Redis
 	cache := redis.NewClient(&redis.Options{
@@ -71,18 +78,114 @@ func main() {
 	}
 	logger.Info("Connected to Redis successfully")
 
+	// short code strategy: random, sequence, snowflake or hashids
+	codeConfig := service.DefaultCodeGeneratorConfig()
+	codeConfig.Strategy = getEnv("SHORT_CODE_STRATEGY", codeConfig.Strategy)
+	codeConfig.Alphabet = getEnv("SHORT_CODE_ALPHABET", codeConfig.Alphabet)
+	codeConfig.Length = getEnvAsInt("SHORT_CODE_LENGTH", codeConfig.Length)
+	codeConfig.WorkerID = int64(getEnvAsInt("SHORT_CODE_WORKER_ID", 0))
+	codeConfig.Salt = getEnv("SHORT_CODE_SALT", "")
+	codeGenerator, err := service.NewCodeGenerator(codeConfig, urlRepo)
+	if err != nil {
+		logger.Fatal("Invalid short code configuration", zap.Error(err))
+	}
+	logger.Info("Short code strategy", zap.String("strategy", codeConfig.Strategy), zap.Int("length", codeConfig.Length))
+
 	metrics := metrics.NewPrometheusMetrics()
-	// create service instance (uses default baseURL from service package)
-	urlService := service.NewURLService(urlRepo, cache, logger, metrics)
+
+	// optionally hand out codes pre-generated by a background refill loop
+	if getEnv("CODE_POOL_ENABLED", "false") == "true" {
+		poolConfig := service.DefaultCodePoolConfig()
+		poolConfig.LowWater = int64(getEnvAsInt("CODE_POOL_LOW_WATER", int(poolConfig.LowWater)))
+		poolConfig.Size = int64(getEnvAsInt("CODE_POOL_SIZE", int(poolConfig.Size)))
+		poolConfig.RefillInterval = getEnvAsDuration("CODE_POOL_REFILL_INTERVAL", poolConfig.RefillInterval)
+		if poolConfig.Size <= poolConfig.LowWater {
+			logger.Fatal("CODE_POOL_SIZE must be greater than CODE_POOL_LOW_WATER")
+		}
+
+		codePool := service.NewCodePool(postgres.NewPostgresCodePoolRepository(db, logger), codeGenerator, poolConfig, logger, metrics)
+		go codePool.Run(context.Background())
+		codeGenerator = codePool
+		logger.Info("Code pool enabled", zap.Int64("lowWater", poolConfig.LowWater), zap.Int64("size", poolConfig.Size))
+	}
+
+	// create service instance
+	urlService := service.NewURLService(urlRepo, codeGenerator, cache, logger, metrics)
+	baseURL := getEnv("BASE_URL", "http://localhost:8080/")
+	urlService.SetBaseURL(baseURL)
+	urlService.URLOptions.StripFragment = getEnv("URL_STRIP_FRAGMENT", "false") == "true"
+	urlService.URLOptions.SortQuery = getEnv("URL_SORT_QUERY", "false") == "true"
+	urlService.URLOptions.CleanQuery = getEnv("URL_CLEAN_QUERY", "false") == "true"
+
+	// destination policy, links back to BASE_URL's host are always rejected
+	policyConfig := urlpolicy.Config{
+		SelfHosts: selfHosts(baseURL, getEnv("SELF_HOSTS", "")),
+		RulesFile: getEnv("DESTINATION_RULES_FILE", ""),
+		// lenient mode for networks where outbound DNS is unreliable
+		AllowUnresolved: getEnv("DESTINATION_ALLOW_UNRESOLVED", "false") == "true",
+	}
+	if getEnv("DESTINATION_RESOLVE_HOSTS", "true") == "true" {
+		policyConfig.Resolver = net.DefaultResolver
+	}
+	policy, err := urlpolicy.New(policyConfig, logger)
+	if err != nil {
+		logger.Fatal("Failed to load destination policy", zap.Error(err))
+	}
+	go policy.Watch(context.Background(), getEnvAsDuration("DESTINATION_RULES_RELOAD_INTERVAL", 30*time.Second))
+	urlService.Policy = policy
+
+	// local threat list, checked on create and re-checked against existing links in the background
+	threats := threatlist.NewList()
+	threatConfig := service.DefaultThreatListConfig()
+	threatConfig.ScanInterval = getEnvAsDuration("THREAT_SCAN_INTERVAL", threatConfig.ScanInterval)
+	threatListService := service.NewThreatListService(postgres.NewPostgresThreatListRepository(db, logger), urlRepo, threats, cache, threatConfig, logger, metrics)
+	if path := getEnv("THREAT_LIST_FILE", ""); path != "" {
+		if err := ingestThreatList(threatListService, path); err != nil {
+			logger.Fatal("Failed to ingest threat list", zap.String("file", path), zap.Error(err))
+		}
+	} else if err := threatListService.Reload(context.Background()); err != nil {
+		logger.Warn("Failed to load threat list", zap.Error(err))
+	}
+	go threatListService.Run(context.Background())
+	urlService.Threats = threats
+
+	// UTM campaigns, their parameters are added to the destination of their links on redirect
+	campaignService := service.NewCampaignService(postgres.NewPostgresCampaignRepository(db, logger), cache, logger, metrics)
+	urlService.Campaigns = campaignService
+
+	// offline GeoIP database for geo-routed links, without one everyone gets the default destination
+	if path := getEnv("GEOIP_DATABASE_FILE", ""); path != "" {
+		geoDB, err := geoip.Open(path, logger)
+		if err != nil {
+			logger.Fatal("Failed to load GeoIP database", zap.String("file", path), zap.Error(err))
+		}
+		go geoDB.Watch(context.Background(), getEnvAsDuration("GEOIP_RELOAD_INTERVAL", time.Minute))
+		urlService.GeoIP = geoDB
+	}
+
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, logger, metrics)
 
 	//start minimal http server for metrics
 	startMetricsServer()
 
 	// create a new gRPC server
 	logger.Info("Starting gRPC server on port 50051...")
-	grpcServer := grpc.NewServer()
+	// issuing and revoking keys acts for any user and the threat list guards
+	// every link, so both need the admin token
+	adminToken := getEnv("ADMIN_TOKEN", "")
+	if adminToken == "" {
+		logger.Warn("ADMIN_TOKEN is not set, admin RPCs are disabled")
+	}
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(auth.AdminInterceptor(adminToken,
+		pb.APIKeyService_IssueAPIKey_FullMethodName,
+		pb.APIKeyService_RevokeAPIKey_FullMethodName,
+		pb.ThreatListService_UpdateThreatList_FullMethodName,
+	)))
 
 	pb.RegisterURLServiceServer(grpcServer, urlService)
+	pb.RegisterAPIKeyServiceServer(grpcServer, apiKeyService)
+	pb.RegisterThreatListServiceServer(grpcServer, threatListService)
+	pb.RegisterCampaignServiceServer(grpcServer, campaignService)
 	reflection.Register(grpcServer)
 
 	// listen on port 50051
@@ -121,6 +224,44 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil && duration > 0 {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+// ingestThreatList adds the hash prefixes in a hex file to the stored threat list
+func ingestThreatList(threatListService *service.ThreatListService, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	prefixes, err := threatlist.ParsePrefixes(file)
+	if err != nil {
+		return err
+	}
+	return threatListService.Ingest(context.Background(), prefixes)
+}
+
+// selfHosts returns the host of baseURL plus the comma-separated extra hosts
+func selfHosts(baseURL, extra string) []string {
+	var hosts []string
+	if u, err := url.Parse(baseURL); err == nil && u.Hostname() != "" {
+		hosts = append(hosts, u.Hostname())
+	}
+	for _, host := range strings.Split(extra, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
 func startMetricsServer() {
 	http.Handle("/metrics", promhttp.Handler())
 	go http.ListenAndServe(":2112", nil)
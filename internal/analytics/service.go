@@ -78,10 +78,23 @@ func (a *AnalyticsService) handleURLAccessed(ctx context.Context, data []byte) e
 		zap.String("originalURL", event.OriginalURL),
 		zap.String("userAgent", event.UserAgent),
 		zap.String("ip", event.IPAddress),
+		zap.String("country", event.Country),
 		zap.String("referrer", event.Referrer),
+		zap.Int64("campaignID", event.CampaignID),
+		zap.String("variant", event.Variant),
 		zap.String("timestamp", event.Timestamp.Format(time.RFC3339)),
 	)
 
+	// per-campaign counts come from the logged events, a campaign_id label
+	// would add a series for every campaign ever created
+	if event.CampaignID != 0 {
+		a.Metrics.IncCampaignClick("analytics-service")
+	}
+	// likewise per-link variant counts belong with the stored events
+	if event.Variant != "" {
+		a.Metrics.IncVariantClick("analytics-service", event.Variant)
+	}
+
 	// TODO: Store analytics data in database
 	// Example: Insert into url_accesses table with all the tracking data
 
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AdminTokenMetadataKey is the gRPC metadata key carrying the admin token
+const AdminTokenMetadataKey = "x-admin-token"
+
+// AdminInterceptor guards admin RPCs, like issuing API keys, that act for any
+// user. Calls to the listed full method names need the shared admin token,
+// other methods pass through. With an empty token admin RPCs are refused.
+func AdminInterceptor(token string, methods ...string) grpc.UnaryServerInterceptor {
+	admin := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		admin[method] = true
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !admin[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		if token == "" {
+			return nil, status.Error(codes.PermissionDenied, "admin RPCs are disabled, no admin token is configured")
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(AdminTokenMetadataKey)
+		if len(values) != 1 || subtle.ConstantTimeCompare([]byte(values[0]), []byte(token)) != 1 {
+			return nil, status.Error(codes.Unauthenticated, "missing or invalid admin token")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// WithAdminToken attaches the admin token to outgoing calls made with ctx
+func WithAdminToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, AdminTokenMetadataKey, token)
+}
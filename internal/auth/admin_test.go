@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdminInterceptor(t *testing.T) {
+	const adminMethod = "/urlservice.APIKeyService/IssueAPIKey"
+	const publicMethod = "/urlservice.APIKeyService/ValidateAPIKey"
+
+	tests := []struct {
+		name     string
+		token    string
+		method   string
+		sent     []string
+		wantCode codes.Code
+	}{
+		{name: "admin call with token", token: "s3cret", method: adminMethod, sent: []string{"s3cret"}, wantCode: codes.OK},
+		{name: "admin call without token", token: "s3cret", method: adminMethod, wantCode: codes.Unauthenticated},
+		{name: "admin call with wrong token", token: "s3cret", method: adminMethod, sent: []string{"guess"}, wantCode: codes.Unauthenticated},
+		{name: "admin call with two tokens", token: "s3cret", method: adminMethod, sent: []string{"guess", "s3cret"}, wantCode: codes.Unauthenticated},
+		{name: "admin disabled", method: adminMethod, sent: []string{""}, wantCode: codes.PermissionDenied},
+		{name: "other methods pass through", token: "s3cret", method: publicMethod, wantCode: codes.OK},
+		{name: "other methods pass through when disabled", method: publicMethod, wantCode: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.sent != nil {
+				pairs := []string{}
+				for _, value := range tt.sent {
+					pairs = append(pairs, AdminTokenMetadataKey, value)
+				}
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(pairs...))
+			}
+
+			called := false
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				called = true
+				return "ok", nil
+			}
+
+			interceptor := AdminInterceptor(tt.token, adminMethod)
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			require.Equal(t, tt.wantCode, status.Code(err))
+			require.Equal(t, tt.wantCode == codes.OK, called)
+		})
+	}
+}
+
+func TestWithAdminToken(t *testing.T) {
+	md, ok := metadata.FromOutgoingContext(WithAdminToken(context.Background(), "s3cret"))
+	require.True(t, ok)
+	require.Equal(t, []string{"s3cret"}, md.Get(AdminTokenMetadataKey))
+}
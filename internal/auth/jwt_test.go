@@ -0,0 +1,268 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func b64(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA", "kid": kid, "use": "sig", "alg": "RS256",
+		"n": b64(key.N), "e": b64(big.NewInt(int64(key.E))),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "EC", "kid": kid, "use": "sig", "alg": "ES256", "crv": "P-256",
+		"x": b64(key.X), "y": b64(key.Y),
+	}
+}
+
+func jwksJSON(t *testing.T, keys ...map[string]string) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]interface{}{"keys": keys})
+	require.NoError(t, err)
+	return data
+}
+
+// jwksServer serves whatever key set was last passed to set
+type jwksServer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	body []byte
+	hits int
+}
+
+func newJWKSServer(t *testing.T, body []byte) *jwksServer {
+	s := &jwksServer{body: body}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.hits++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(s.body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *jwksServer) set(body []byte) {
+	s.mu.Lock()
+	s.body = body
+	s.mu.Unlock()
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "user123",
+		"iss":   "https://sso.example.com",
+		"aud":   "url-shortener",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "links:read links:write",
+	}
+}
+
+func TestJWTVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	server := newJWKSServer(t, jwksJSON(t, rsaJWK("rsa-1", rsaKey), ecJWK("ec-1", ecKey)))
+	keys, err := NewJWKS(context.Background(), server.URL, zap.NewNop())
+	require.NoError(t, err)
+	verifier := NewJWTVerifier(keys, "https://sso.example.com", "url-shortener")
+
+	withClaims := func(changes jwt.MapClaims) jwt.MapClaims {
+		claims := validClaims()
+		for k, v := range changes {
+			if v == nil {
+				delete(claims, k)
+				continue
+			}
+			claims[k] = v
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantErr    bool
+		wantScopes []string
+	}{
+		{
+			name:       "rs256",
+			token:      signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, validClaims()),
+			wantScopes: []string{ScopeLinksRead, ScopeLinksWrite},
+		},
+		{
+			name:       "es256",
+			token:      signToken(t, jwt.SigningMethodES256, "ec-1", ecKey, validClaims()),
+			wantScopes: []string{ScopeLinksRead, ScopeLinksWrite},
+		},
+		{
+			name:       "scp array",
+			token:      signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, withClaims(jwt.MapClaims{"scope": nil, "scp": []string{ScopeAnalyticsRead}})),
+			wantScopes: []string{ScopeAnalyticsRead},
+		},
+		{
+			name:    "expired",
+			token:   signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, withClaims(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})),
+			wantErr: true,
+		},
+		{
+			name:    "missing exp",
+			token:   signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, withClaims(jwt.MapClaims{"exp": nil})),
+			wantErr: true,
+		},
+		{
+			name:    "missing sub",
+			token:   signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, withClaims(jwt.MapClaims{"sub": nil})),
+			wantErr: true,
+		},
+		{
+			name:    "wrong issuer",
+			token:   signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, withClaims(jwt.MapClaims{"iss": "https://evil.example.com"})),
+			wantErr: true,
+		},
+		{
+			name:    "wrong audience",
+			token:   signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, withClaims(jwt.MapClaims{"aud": "other-app"})),
+			wantErr: true,
+		},
+		{
+			name:    "key from another kid",
+			token:   signToken(t, jwt.SigningMethodES256, "rsa-1", ecKey, validClaims()),
+			wantErr: true,
+		},
+		{
+			name:    "hs256 not allowed",
+			token:   signToken(t, jwt.SigningMethodHS256, "rsa-1", []byte("secret"), validClaims()),
+			wantErr: true,
+		},
+		{
+			name:    "garbage",
+			token:   "a.b.c",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			claims, err := verifier.Verify(context.Background(), tc.token)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "user123", claims.Subject)
+			require.ElementsMatch(t, tc.wantScopes, claims.Scopes)
+		})
+	}
+}
+
+func TestJWKS_RefreshesOnUnknownKid(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	server := newJWKSServer(t, jwksJSON(t, rsaJWK("old", oldKey)))
+	keys, err := NewJWKS(context.Background(), server.URL, zap.NewNop())
+	require.NoError(t, err)
+	verifier := NewJWTVerifier(keys, "", "")
+
+	server.set(jwksJSON(t, rsaJWK("new", newKey)))
+	token := signToken(t, jwt.SigningMethodRS256, "new", newKey, validClaims())
+
+	// a reload was just done, so the rotated key isn't fetched yet
+	_, err = verifier.Verify(context.Background(), token)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	require.Equal(t, 1, server.hits)
+
+	keys.mu.Lock()
+	keys.lastRefresh = time.Now().Add(-time.Minute)
+	keys.mu.Unlock()
+
+	claims, err := verifier.Verify(context.Background(), token)
+	require.NoError(t, err)
+	require.Equal(t, "user123", claims.Subject)
+	require.Equal(t, 2, server.hits)
+}
+
+func TestJWKS_Run(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	server := newJWKSServer(t, jwksJSON(t, ecJWK("ec-1", key)))
+	keys, err := NewJWKS(context.Background(), server.URL, zap.NewNop())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go keys.Run(ctx, 10*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return server.hits >= 3
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestNewJWKS_FromFile(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwksJSON(t, ecJWK("ec-1", key)), 0o600))
+
+	keys, err := NewJWKS(context.Background(), path, zap.NewNop())
+	require.NoError(t, err)
+
+	got, err := keys.Key(context.Background(), "ec-1")
+	require.NoError(t, err)
+	require.True(t, key.PublicKey.Equal(got))
+}
+
+func TestParseJWKS_Invalid(t *testing.T) {
+	_, err := parseJWKS([]byte(`{"keys":[]}`))
+	require.Error(t, err)
+
+	_, err = parseJWKS([]byte(`{"keys":[{"kty":"EC","kid":"bad","crv":"P-256","x":"AQ","y":"AQ"}]}`))
+	require.Error(t, err)
+
+	_, err = parseJWKS([]byte(`not json`))
+	require.Error(t, err)
+}
@@ -74,6 +74,70 @@ func RunMigrations(db *sqlx.DB) error {
          BEFORE UPDATE ON urls 
          FOR EACH ROW 
          EXECUTE FUNCTION update_updated_at_column()`,
+		// widen short_code to fit custom aliases
+		`ALTER TABLE urls ALTER COLUMN short_code TYPE VARCHAR(32)`,
+		`CREATE TABLE IF NOT EXISTS api_keys (
+            id BIGSERIAL PRIMARY KEY,
+            user_id TEXT NOT NULL,
+            name VARCHAR(100) NOT NULL DEFAULT '',
+            key_hash CHAR(64) UNIQUE NOT NULL,
+            prefix VARCHAR(16) NOT NULL,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            revoked_at TIMESTAMP WITH TIME ZONE
+        )`,
+		`CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id)`,
+		// counter behind the sequence and hashids short code strategies
+		`CREATE SEQUENCE IF NOT EXISTS short_code_seq START WITH 1`,
+		// pre-generated short codes handed out by the key pool
+		`CREATE TABLE IF NOT EXISTS code_pool (
+            code VARCHAR(32) PRIMARY KEY,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+        )`,
+		// SHA-256 of the normalized destination, looked up per user by dedupe creates
+		`ALTER TABLE urls ADD COLUMN IF NOT EXISTS url_hash CHAR(64)`,
+		`CREATE INDEX IF NOT EXISTS idx_urls_user_url_hash ON urls (user_id, url_hash)`,
+		// SHA-256 hash prefixes of unsafe URL expressions, and links found to match one
+		`CREATE TABLE IF NOT EXISTS threat_hash_prefixes (
+            prefix BYTEA PRIMARY KEY,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+        )`,
+		`ALTER TABLE urls ADD COLUMN IF NOT EXISTS quarantined_at TIMESTAMP WITH TIME ZONE`,
+		// links that show a "you are leaving" page instead of redirecting straight away
+		`ALTER TABLE urls ADD COLUMN IF NOT EXISTS interstitial BOOLEAN NOT NULL DEFAULT FALSE`,
+		// HTTP status each link redirects with
+		`ALTER TABLE urls ADD COLUMN IF NOT EXISTS redirect_type SMALLINT NOT NULL DEFAULT 302`,
+		// whether redirects forward the incoming query string and the path after the short code
+		`ALTER TABLE urls ADD COLUMN IF NOT EXISTS query_passthrough TEXT NOT NULL DEFAULT 'off'`,
+		`ALTER TABLE urls ADD COLUMN IF NOT EXISTS path_passthrough BOOLEAN NOT NULL DEFAULT FALSE`,
+		// UTM parameters added to the destination of a campaign's links on redirect
+		`CREATE TABLE IF NOT EXISTS campaigns (
+            id BIGSERIAL PRIMARY KEY,
+            user_id TEXT NOT NULL,
+            name VARCHAR(255) NOT NULL,
+            utm_source VARCHAR(255) NOT NULL DEFAULT '',
+            utm_medium VARCHAR(255) NOT NULL DEFAULT '',
+            utm_campaign VARCHAR(255) NOT NULL DEFAULT '',
+            utm_term VARCHAR(255) NOT NULL DEFAULT '',
+            utm_content VARCHAR(255) NOT NULL DEFAULT '',
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+        )`,
+		`CREATE INDEX IF NOT EXISTS idx_campaigns_user_id ON campaigns (user_id)`,
+		`ALTER TABLE urls ADD COLUMN IF NOT EXISTS campaign_id BIGINT REFERENCES campaigns (id) ON DELETE SET NULL`,
+		// per-country destinations, {"DE": "https://example.de/"}
+		`ALTER TABLE urls ADD COLUMN IF NOT EXISTS geo_routes JSONB`,
+		// per-platform destinations, {"ios": "https://apps.apple.com/..."}
+		`ALTER TABLE urls ADD COLUMN IF NOT EXISTS device_routes JSONB`,
+		// A/B split, [{"name": "a", "url": "https://example.com/a", "weight": 50}, ...]
+		`ALTER TABLE urls ADD COLUMN IF NOT EXISTS variants JSONB`,
+		// launch links don't resolve before active_from
+		`ALTER TABLE urls ADD COLUMN IF NOT EXISTS active_from TIMESTAMP WITH TIME ZONE`,
+		// time windows with their own destination, [{"start": ..., "end": ..., "url": ...}]
+		`ALTER TABLE urls ADD COLUMN IF NOT EXISTS schedule JSONB`,
+		// click-limited links stop redirecting once click_count reaches max_clicks
+		`ALTER TABLE urls ADD COLUMN IF NOT EXISTS max_clicks BIGINT`,
+		// user IDs are JWT and API key subjects, which aren't length-limited
+		`ALTER TABLE urls ALTER COLUMN user_id TYPE TEXT`,
 	}
 
 	for _, migration := range migrations {
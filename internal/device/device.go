@@ -0,0 +1,115 @@
+// Package device sorts visitors into the platforms links can target from
+// their User-Agent, and checks the Android intent URLs app links use.
+package device
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Platform is what a link's device routes are keyed by
+type Platform string
+
+const (
+	PlatformIOS     Platform = "ios"
+	PlatformAndroid Platform = "android"
+	PlatformDesktop Platform = "desktop"
+	// PlatformUnknown covers bots, empty and unrecognized User-Agents, they
+	// get the link's default destination
+	PlatformUnknown Platform = ""
+)
+
+var (
+	ErrInvalidPlatform  = errors.New("invalid platform")
+	ErrInvalidIntentURL = errors.New("invalid intent URL")
+)
+
+// ParsePlatform checks name is a platform routes can target
+func ParsePlatform(name string) (Platform, error) {
+	switch p := Platform(strings.ToLower(strings.TrimSpace(name))); p {
+	case PlatformIOS, PlatformAndroid, PlatformDesktop:
+		return p, nil
+	}
+	return PlatformUnknown, fmt.Errorf("%w: %q, must be %s, %s or %s", ErrInvalidPlatform, name, PlatformIOS, PlatformAndroid, PlatformDesktop)
+}
+
+// tokens are checked in order against the lower-cased User-Agent, the first
+// match wins. Windows Phone claims to be Android and iPhone, and bots often
+// claim a browser, so both come first.
+var tokens = []struct {
+	token    string
+	platform Platform
+}{
+	{"bot/", PlatformUnknown},
+	{"bot-", PlatformUnknown},
+	{"facebookexternalhit", PlatformUnknown},
+	{"crawler", PlatformUnknown},
+	{"spider", PlatformUnknown},
+	{"curl/", PlatformUnknown},
+	{"wget/", PlatformUnknown},
+	{"windows phone", PlatformUnknown},
+	{"android", PlatformAndroid},
+	{"iphone", PlatformIOS},
+	{"ipad", PlatformIOS},
+	{"ipod", PlatformIOS},
+	{"windows nt", PlatformDesktop},
+	{"macintosh", PlatformDesktop},
+	{"cros ", PlatformDesktop},
+	{"x11", PlatformDesktop},
+	{"linux", PlatformDesktop},
+}
+
+// Classify returns the platform of a User-Agent. iPads asking for the
+// desktop site send a Mac User-Agent and are classified as desktops.
+func Classify(userAgent string) Platform {
+	ua := strings.ToLower(userAgent)
+	for _, t := range tokens {
+		if strings.Contains(ua, t.token) {
+			return t.platform
+		}
+	}
+	return PlatformUnknown
+}
+
+// IsIntentURL reports whether rawURL uses the Android intent: scheme
+func IsIntentURL(rawURL string) bool {
+	scheme, _, ok := strings.Cut(rawURL, ":")
+	return ok && strings.EqualFold(scheme, "intent")
+}
+
+// ParseIntentURL checks rawURL is an Android intent URL naming the app
+// package to open, intent://path#Intent;scheme=...;package=...;end, and
+// returns its S.browser_fallback_url, "" if it has none.
+func ParseIntentURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidIntentURL, err)
+	}
+	if !strings.EqualFold(u.Scheme, "intent") {
+		return "", fmt.Errorf("%w: scheme must be intent", ErrInvalidIntentURL)
+	}
+
+	extras, ok := strings.CutPrefix(u.EscapedFragment(), "Intent;")
+	if !ok || !strings.HasSuffix(extras, ";end") {
+		return "", fmt.Errorf("%w: fragment must be Intent;...;end", ErrInvalidIntentURL)
+	}
+
+	var pkg, fallback string
+	for _, extra := range strings.Split(strings.TrimSuffix(extras, ";end"), ";") {
+		key, value, _ := strings.Cut(extra, "=")
+		switch key {
+		case "package":
+			pkg = value
+		case "S.browser_fallback_url":
+			if fallback, err = url.QueryUnescape(value); err != nil {
+				return "", fmt.Errorf("%w: browser_fallback_url: %v", ErrInvalidIntentURL, err)
+			}
+		}
+	}
+	if pkg == "" {
+		return "", fmt.Errorf("%w: package is required", ErrInvalidIntentURL)
+	}
+	return fallback, nil
+}
@@ -0,0 +1,174 @@
+package device
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		expected  Platform
+	}{
+		{
+			name:      "iPhone Safari",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+			expected:  PlatformIOS,
+		},
+		{
+			name:      "iPhone Chrome",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/123.0.6312.52 Mobile/15E148 Safari/604.1",
+			expected:  PlatformIOS,
+		},
+		{
+			name:      "iPad",
+			userAgent: "Mozilla/5.0 (iPad; CPU OS 16_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.6 Mobile/15E148 Safari/604.1",
+			expected:  PlatformIOS,
+		},
+		{
+			name:      "Instagram in-app browser on iOS",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 16_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 Instagram 290.0.0.13.76",
+			expected:  PlatformIOS,
+		},
+		{
+			name:      "Android Chrome",
+			userAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/123.0.6312.99 Mobile Safari/537.36",
+			expected:  PlatformAndroid,
+		},
+		{
+			name:      "Android tablet",
+			userAgent: "Mozilla/5.0 (Linux; Android 13; SM-X710) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/122.0.0.0 Safari/537.36",
+			expected:  PlatformAndroid,
+		},
+		{
+			name:      "Android phone brand containing bot",
+			userAgent: "Mozilla/5.0 (Linux; Android 9; CUBOT_X19) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36",
+			expected:  PlatformAndroid,
+		},
+		{
+			name:      "Windows Edge",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/123.0.0.0 Safari/537.36 Edg/123.0.2420.65",
+			expected:  PlatformDesktop,
+		},
+		{
+			name:      "Mac Safari",
+			userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15",
+			expected:  PlatformDesktop,
+		},
+		{
+			name:      "Linux Firefox",
+			userAgent: "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:124.0) Gecko/20100101 Firefox/124.0",
+			expected:  PlatformDesktop,
+		},
+		{
+			name:      "ChromeOS",
+			userAgent: "Mozilla/5.0 (X11; CrOS x86_64 14541.0.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/123.0.0.0 Safari/537.36",
+			expected:  PlatformDesktop,
+		},
+		{
+			name:      "Windows Phone",
+			userAgent: "Mozilla/5.0 (Windows Phone 10.0; Android 6.0.1; Microsoft; Lumia 950) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/52.0.2743.116 Mobile Safari/537.36 Edge/15.14977",
+			expected:  PlatformUnknown,
+		},
+		{
+			name:      "Googlebot smartphone",
+			userAgent: "Mozilla/5.0 (Linux; Android 6.0.1; Nexus 5X Build/MMB29P) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/123.0.0.0 Mobile Safari/537.36 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			expected:  PlatformUnknown,
+		},
+		{
+			name:      "Slack unfurler",
+			userAgent: "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)",
+			expected:  PlatformUnknown,
+		},
+		{
+			name:      "curl",
+			userAgent: "curl/8.5.0",
+			expected:  PlatformUnknown,
+		},
+		{
+			name:      "empty",
+			userAgent: "",
+			expected:  PlatformUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, Classify(tt.userAgent))
+		})
+	}
+}
+
+func TestParsePlatform(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected Platform
+		wantErr  bool
+	}{
+		{name: "ios", expected: PlatformIOS},
+		{name: "Android", expected: PlatformAndroid},
+		{name: " desktop ", expected: PlatformDesktop},
+		{name: "windows", wantErr: true},
+		{name: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePlatform(tt.name)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidPlatform)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestParseIntentURL(t *testing.T) {
+	tests := []struct {
+		name             string
+		rawURL           string
+		expectedFallback string
+		wantErr          bool
+	}{
+		{
+			name:   "package only",
+			rawURL: "intent://scan/#Intent;scheme=zxing;package=com.google.zxing.client.android;end",
+		},
+		{
+			name:             "with fallback",
+			rawURL:           "intent://open/item/42#Intent;scheme=shop;package=com.example.shop;S.browser_fallback_url=https%3A%2F%2Fexample.com%2Fitem%2F42;end",
+			expectedFallback: "https://example.com/item/42",
+		},
+		{
+			name:    "missing package",
+			rawURL:  "intent://scan/#Intent;scheme=zxing;end",
+			wantErr: true,
+		},
+		{
+			name:    "missing end",
+			rawURL:  "intent://scan/#Intent;package=com.example",
+			wantErr: true,
+		},
+		{
+			name:    "not an intent",
+			rawURL:  "https://play.google.com/store/apps/details?id=com.example",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fallback, err := ParseIntentURL(tt.rawURL)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidIntentURL)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedFallback, fallback)
+		})
+	}
+}
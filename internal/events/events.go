@@ -29,6 +29,7 @@ type URLCreatedEventData struct {
 	OriginalURL string     `json:"original_url"`
 	CreatedBy   string     `json:"created_by,omitempty"`
 	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CampaignID  int64      `json:"campaign_id,omitempty"`
 }
 
 // URLAccessedEventData represents data for URL access events
@@ -38,7 +39,12 @@ type URLAccessedEventData struct {
 	OriginalURL string `json:"original_url"`
 	UserAgent   string `json:"user_agent,omitempty"`
 	IPAddress   string `json:"ip_address,omitempty"`
-	Referrer    string `json:"referrer,omitempty"`
+	// Country is the ISO 3166-1 alpha-2 code resolved from IPAddress
+	Country    string `json:"country,omitempty"`
+	Referrer   string `json:"referrer,omitempty"`
+	CampaignID int64  `json:"campaign_id,omitempty"`
+	// Variant is the name of the A/B split variant the visitor was sent to
+	Variant string `json:"variant,omitempty"`
 }
 
 // ToJSON serializes the event to JSON
@@ -0,0 +1,155 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sammyqtran/url-shortener/internal/auth"
+	pb "github.com/sammyqtran/url-shortener/proto"
+	"go.uber.org/zap"
+)
+
+type contextKey string
+
+const principalContextKey contextKey = "principal"
+
+// principal is the authenticated caller of a request
+type principal struct {
+	UserID string
+	// KeyID is the API key used, zero for JWTs
+	KeyID int64
+	// Scopes granted by a JWT, nil for API keys which act with the owner's full access
+	Scopes []string
+}
+
+func (p *principal) hasScope(scope string) bool {
+	if p.Scopes == nil {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticate rejects requests without a valid API key or JWT and stores the
+// caller in the request context for getUserID and RequireScope.
+func (s *GatewayServer) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpoint := routeTemplate(r)
+
+		credential := extractCredential(r)
+		if credential == "" {
+			s.respondUnauthorized(w, r, endpoint, "missing credentials")
+			return
+		}
+
+		var (
+			caller *principal
+			ok     bool
+		)
+		if s.JWTVerifier != nil && auth.LooksLikeJWT(credential) {
+			caller, ok = s.authenticateJWT(r, credential)
+			if !ok {
+				s.respondUnauthorized(w, r, endpoint, "invalid token")
+				return
+			}
+		} else {
+			caller, ok = s.authenticateAPIKey(w, r, endpoint, credential)
+			if !ok {
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalContextKey, caller)))
+	})
+}
+
+// RequireScope wraps a handler so it only runs for callers granted scope
+func (s *GatewayServer) RequireScope(scope string, next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := principalFromContext(r.Context())
+		if !ok {
+			s.respondUnauthorized(w, r, routeTemplate(r), "missing credentials")
+			return
+		}
+		if !caller.hasScope(scope) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="url-shortener", error="insufficient_scope", scope="`+scope+`"`)
+			s.respondWithHTTPError(w, r, routeTemplate(r), http.StatusForbidden, "missing scope "+scope)
+			return
+		}
+		next(w, r)
+	})
+}
+
+func (s *GatewayServer) authenticateJWT(r *http.Request, token string) (*principal, bool) {
+	claims, err := s.JWTVerifier.Verify(r.Context(), token)
+	if err != nil {
+		s.Logger.Warn("Rejected invalid JWT", zap.String("client_ip", s.getClientIP(r)), zap.Error(err))
+		return nil, false
+	}
+	scopes := claims.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return &principal{UserID: claims.Subject, Scopes: scopes}, true
+}
+
+// authenticateAPIKey resolves a key through the url-service, writing the error response itself on failure
+func (s *GatewayServer) authenticateAPIKey(w http.ResponseWriter, r *http.Request, endpoint, apiKey string) (*principal, bool) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	s.Metrics.IncGRPCCall("gateway", "ValidateAPIKey")
+	grpcTimer := time.Now()
+	response, err := s.AuthClient.ValidateAPIKey(ctx, &pb.ValidateAPIKeyRequest{ApiKey: apiKey})
+	s.Metrics.ObserveGRPCLatency("gateway", "ValidateAPIKey", time.Since(grpcTimer).Seconds())
+	if err != nil {
+		s.respondWithGRPCError(w, r, endpoint, "ValidateAPIKey", err)
+		return nil, false
+	}
+	if !response.Valid {
+		s.Logger.Warn("Rejected invalid API key", zap.String("client_ip", s.getClientIP(r)))
+		s.respondUnauthorized(w, r, endpoint, "invalid API key")
+		return nil, false
+	}
+	return &principal{UserID: response.UserId, KeyID: response.KeyId}, true
+}
+
+func (s *GatewayServer) respondUnauthorized(w http.ResponseWriter, r *http.Request, endpoint, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="url-shortener"`)
+	s.respondWithHTTPError(w, r, endpoint, http.StatusUnauthorized, message)
+}
+
+// extractCredential reads an API key or JWT from "Authorization: Bearer <token>", or an API key from X-API-Key
+func extractCredential(r *http.Request) string {
+	if authHeader := r.Header.Get("Authorization"); authHeader != "" {
+		scheme, token, found := strings.Cut(authHeader, " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+// principalFromContext returns the authenticated caller, if any
+func principalFromContext(ctx context.Context) (*principal, bool) {
+	caller, ok := ctx.Value(principalContextKey).(*principal)
+	return caller, ok && caller.UserID != ""
+}
+
+// routeTemplate returns the matched mux route template for metrics labels, falling back to the path
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tmpl, err := route.GetPathTemplate(); err == nil {
+			return tmpl
+		}
+	}
+	return r.URL.Path
+}
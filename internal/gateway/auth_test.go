@@ -0,0 +1,363 @@
+package gateway
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sammyqtran/url-shortener/internal/auth"
+	"github.com/sammyqtran/url-shortener/internal/metrics"
+	pb "github.com/sammyqtran/url-shortener/proto"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testAPIKey = "usk_testkey"
+
+// mock grpc client for the API key service
+type MockAPIKeyServiceClient struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyServiceClient) IssueAPIKey(ctx context.Context,
+	in *pb.IssueAPIKeyRequest, opts ...grpc.CallOption) (*pb.IssueAPIKeyResponse, error) {
+
+	args := m.Called(ctx, in, opts)
+	if resp, ok := args.Get(0).(*pb.IssueAPIKeyResponse); ok {
+		return resp, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAPIKeyServiceClient) RevokeAPIKey(ctx context.Context,
+	in *pb.RevokeAPIKeyRequest, opts ...grpc.CallOption) (*pb.RevokeAPIKeyResponse, error) {
+
+	args := m.Called(ctx, in, opts)
+	if resp, ok := args.Get(0).(*pb.RevokeAPIKeyResponse); ok {
+		return resp, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAPIKeyServiceClient) ValidateAPIKey(ctx context.Context,
+	in *pb.ValidateAPIKeyRequest, opts ...grpc.CallOption) (*pb.ValidateAPIKeyResponse, error) {
+
+	args := m.Called(ctx, in, opts)
+	if resp, ok := args.Get(0).(*pb.ValidateAPIKeyResponse); ok {
+		return resp, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// newAuthMock accepts testAPIKey as user123 and rejects any other key
+func newAuthMock() *MockAPIKeyServiceClient {
+	m := new(MockAPIKeyServiceClient)
+	m.On("ValidateAPIKey", mock.Anything, mock.MatchedBy(func(req *pb.ValidateAPIKeyRequest) bool {
+		return req.ApiKey == testAPIKey
+	}), mock.Anything).Return(&pb.ValidateAPIKeyResponse{Valid: true, UserId: "user123", KeyId: 1}, nil).Maybe()
+	m.On("ValidateAPIKey", mock.Anything, mock.Anything, mock.Anything).
+		Return(&pb.ValidateAPIKeyResponse{Valid: false}, nil).Maybe()
+	return m
+}
+
+func TestExtractCredential(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{name: "bearer", headers: map[string]string{"Authorization": "Bearer usk_abc"}, want: "usk_abc"},
+		{name: "bearer lowercase scheme", headers: map[string]string{"Authorization": "bearer usk_abc"}, want: "usk_abc"},
+		{name: "x-api-key", headers: map[string]string{"X-API-Key": "usk_abc"}, want: "usk_abc"},
+		{name: "other scheme", headers: map[string]string{"Authorization": "Basic dXNlcjpwYXNz"}, want: ""},
+		{name: "none", headers: map[string]string{}, want: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			require.Equal(t, tc.want, extractCredential(req))
+		})
+	}
+}
+
+func TestAuthenticate_APIKey(t *testing.T) {
+	tests := []struct {
+		name         string
+		apiKey       string
+		authErr      error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "missing key",
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: `{"error":"missing credentials","code":"unauthenticated"}`,
+		},
+		{
+			name:         "invalid key",
+			apiKey:       "usk_wrong",
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: `{"error":"invalid API key","code":"unauthenticated"}`,
+		},
+		{
+			name:         "auth service unavailable",
+			apiKey:       testAPIKey,
+			authErr:      status.Error(codes.Unavailable, "connection refused"),
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"error":"service unavailable","code":"unavailable"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			authClient := newAuthMock()
+			if tc.authErr != nil {
+				authClient = new(MockAPIKeyServiceClient)
+				authClient.On("ValidateAPIKey", mock.Anything, mock.Anything, mock.Anything).Return(nil, tc.authErr)
+			}
+			mockClient := new(MockURLServiceClient)
+			server := &GatewayServer{
+				GrpcClient: mockClient,
+				AuthClient: authClient,
+				Logger:     zap.NewNop(),
+				Metrics:    &metrics.NoopMetrics{},
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/create", strings.NewReader(`{"url": "https://example.com"}`))
+			if tc.apiKey != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.apiKey)
+			}
+			w := httptest.NewRecorder()
+
+			server.NewRouter().ServeHTTP(w, req)
+
+			require.Equal(t, tc.expectedCode, w.Code)
+			require.JSONEq(t, tc.expectedBody, w.Body.String())
+			if tc.expectedCode == http.StatusUnauthorized {
+				require.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+			// rejected requests never reach the url-service
+			mockClient.AssertNotCalled(t, "CreateShortURL", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAuthenticate_PropagatesUser(t *testing.T) {
+	mockClient := new(MockURLServiceClient)
+	mockPublisher := newMockPublisher()
+	server := &GatewayServer{
+		GrpcClient: mockClient,
+		AuthClient: newAuthMock(),
+		Publisher:  mockPublisher,
+		Logger:     zap.NewNop(),
+		Metrics:    &metrics.NoopMetrics{},
+	}
+
+	mockClient.
+		On("CreateShortURL", mock.Anything, mock.MatchedBy(func(req *pb.CreateURLRequest) bool {
+			return req.UserId == "user123"
+		}), mock.Anything).
+		Return(&pb.CreateURLResponse{ShortCode: "test123"}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/create", strings.NewReader(`{"url": "https://example.com"}`))
+	req.Header.Set("X-API-Key", testAPIKey)
+	w := httptest.NewRecorder()
+
+	server.NewRouter().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	mockClient.AssertExpectations(t)
+
+	mockPublisher.waitPublished(t)
+	require.Equal(t, "user123", mockPublisher.PublishedUser)
+}
+
+func TestRedirectIsPublic(t *testing.T) {
+	mockClient := new(MockURLServiceClient)
+	authClient := new(MockAPIKeyServiceClient)
+	server := &GatewayServer{
+		GrpcClient: mockClient,
+		AuthClient: authClient,
+		Publisher:  new(MockPublisher),
+		Logger:     zap.NewNop(),
+		Metrics:    &metrics.NoopMetrics{},
+	}
+
+	mockClient.
+		On("GetOriginalURL", mock.Anything, mock.Anything, mock.Anything).
+		Return(&pb.GetURLResponse{OriginalUrl: "https://example.com", Found: true}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+	w := httptest.NewRecorder()
+
+	server.NewRouter().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusFound, w.Code)
+	authClient.AssertNotCalled(t, "ValidateAPIKey", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// newTestJWTVerifier serves a fresh ES256 key from an httptest JWKS server
+func newTestJWTVerifier(t *testing.T) (*auth.JWTVerifier, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	body, err := json.Marshal(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "EC", "kid": "test", "use": "sig", "crv": "P-256",
+		"x": base64.RawURLEncoding.EncodeToString(key.X.Bytes()),
+		"y": base64.RawURLEncoding.EncodeToString(key.Y.Bytes()),
+	}}})
+	require.NoError(t, err)
+
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	t.Cleanup(jwksServer.Close)
+
+	keys, err := auth.NewJWKS(context.Background(), jwksServer.URL, zap.NewNop())
+	require.NoError(t, err)
+	return auth.NewJWTVerifier(keys, "", ""), key
+}
+
+func signTestJWT(t *testing.T, key *ecdsa.PrivateKey, sub, scope string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"sub":   sub,
+		"scope": scope,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = "test"
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestAuthenticate_JWTScopes(t *testing.T) {
+	verifier, key := newTestJWTVerifier(t)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	details := &pb.URLDetails{ShortCode: "abc123", UserId: "sso-user", ClickCount: 2}
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		token        string
+		mockSetup    func(m *MockURLServiceClient)
+		expectedCode int
+	}{
+		{
+			name:   "read scope can list",
+			method: http.MethodGet,
+			path:   "/api/v1/links",
+			token:  signTestJWT(t, key, "sso-user", "links:read"),
+			mockSetup: func(m *MockURLServiceClient) {
+				m.On("ListURLs", mock.Anything, mock.MatchedBy(func(req *pb.ListURLsRequest) bool {
+					return req.UserId == "sso-user"
+				}), mock.Anything).Return(&pb.ListURLsResponse{}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "read scope cannot delete",
+			method:       http.MethodDelete,
+			path:         "/api/v1/links/abc123",
+			token:        signTestJWT(t, key, "sso-user", "links:read"),
+			mockSetup:    func(m *MockURLServiceClient) {},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:   "write scope can delete",
+			method: http.MethodDelete,
+			path:   "/api/v1/links/abc123",
+			token:  signTestJWT(t, key, "sso-user", "links:write"),
+			mockSetup: func(m *MockURLServiceClient) {
+				m.On("DeleteShortURL", mock.Anything, mock.Anything, mock.Anything).
+					Return(&pb.DeleteURLResponse{Success: true}, nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "stats need analytics scope",
+			method:       http.MethodGet,
+			path:         "/api/v1/links/abc123/stats",
+			token:        signTestJWT(t, key, "sso-user", "links:read links:write"),
+			mockSetup:    func(m *MockURLServiceClient) {},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:   "analytics scope can read stats",
+			method: http.MethodGet,
+			path:   "/api/v1/links/abc123/stats",
+			token:  signTestJWT(t, key, "sso-user", "analytics:read"),
+			mockSetup: func(m *MockURLServiceClient) {
+				m.On("GetURLDetails", mock.Anything, mock.Anything, mock.Anything).
+					Return(&pb.GetURLDetailsResponse{Url: details}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "create needs write scope",
+			method:       http.MethodPost,
+			path:         "/create",
+			token:        signTestJWT(t, key, "sso-user", "links:read"),
+			mockSetup:    func(m *MockURLServiceClient) {},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "token signed by unknown key",
+			method:       http.MethodGet,
+			path:         "/api/v1/links",
+			token:        signTestJWT(t, otherKey, "sso-user", "links:read"),
+			mockSetup:    func(m *MockURLServiceClient) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient := new(MockURLServiceClient)
+			tc.mockSetup(mockClient)
+			authClient := new(MockAPIKeyServiceClient)
+			server := &GatewayServer{
+				GrpcClient:  mockClient,
+				AuthClient:  authClient,
+				JWTVerifier: verifier,
+				Logger:      zap.NewNop(),
+				Metrics:     &metrics.NoopMetrics{},
+			}
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(`{"url": "https://example.com"}`))
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			w := httptest.NewRecorder()
+
+			server.NewRouter().ServeHTTP(w, req)
+
+			require.Equal(t, tc.expectedCode, w.Code, w.Body.String())
+			if tc.expectedCode == http.StatusForbidden {
+				require.Contains(t, w.Header().Get("WWW-Authenticate"), `error="insufficient_scope"`)
+			}
+			mockClient.AssertExpectations(t)
+			// JWTs never fall through to the API key lookup
+			authClient.AssertNotCalled(t, "ValidateAPIKey", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package gateway
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ParseTrustedProxies parses a comma-separated list of CIDRs or bare IPs
+func ParseTrustedProxies(list string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// getClientIP returns the address of the client that sent the request.
+// Forwarding headers are only believed when the connection comes from a
+// trusted proxy, and the chain is walked right to left so a client can't
+// prepend a spoofed hop.
+func (s *GatewayServer) getClientIP(r *http.Request) string {
+	remote, ok := parseHostIP(r.RemoteAddr)
+	if !ok {
+		return r.RemoteAddr
+	}
+	if !s.isTrustedProxy(remote) {
+		return remote.String()
+	}
+
+	hops := forwardedFor(r.Header)
+	if hops == nil {
+		hops = xForwardedFor(r.Header)
+	}
+	if hops == nil {
+		if ip, ok := parseHostIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ok {
+			return ip.String()
+		}
+		return remote.String()
+	}
+
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip, ok := parseHostIP(hops[i])
+		if !ok {
+			// obfuscated or garbage hop, the last trusted one is the best we know
+			break
+		}
+		client = ip
+		if !s.isTrustedProxy(ip) {
+			break
+		}
+	}
+	return client.String()
+}
+
+func (s *GatewayServer) isTrustedProxy(ip netip.Addr) bool {
+	for _, prefix := range s.TrustedProxies {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// xForwardedFor returns the hops of every X-Forwarded-For header, nearest last
+func xForwardedFor(h http.Header) []string {
+	var hops []string
+	for _, value := range h.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(value, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+	return hops
+}
+
+// forwardedFor returns the for= node of every RFC 7239 Forwarded element, nearest last
+func forwardedFor(h http.Header) []string {
+	var hops []string
+	for _, value := range h.Values("Forwarded") {
+		for _, element := range splitQuoted(value, ',') {
+			node := ""
+			for _, pair := range splitQuoted(element, ';') {
+				key, val, found := strings.Cut(strings.TrimSpace(pair), "=")
+				if found && strings.EqualFold(key, "for") {
+					node = strings.Trim(val, `"`)
+				}
+			}
+			hops = append(hops, node)
+		}
+	}
+	return hops
+}
+
+// splitQuoted splits s on sep outside of double-quoted strings
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	inQuotes := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case sep:
+			if !inQuotes {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// parseHostIP parses an IP that may carry a port or IPv6 brackets,
+// e.g. "203.0.113.7", "203.0.113.7:5555", "::1", "[::1]" or "[::1]:1234".
+func parseHostIP(host string) (netip.Addr, bool) {
+	if host == "" {
+		return netip.Addr{}, false
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap().WithZone(""), true
+}
@@ -0,0 +1,163 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	prefixes, err := ParseTrustedProxies("10.0.0.0/8, 192.168.1.10 ,fd00::/8,::1,")
+	require.NoError(t, err)
+	require.Len(t, prefixes, 4)
+	require.Equal(t, "192.168.1.10/32", prefixes[1].String())
+	require.Equal(t, "::1/128", prefixes[3].String())
+
+	prefixes, err = ParseTrustedProxies("")
+	require.NoError(t, err)
+	require.Empty(t, prefixes)
+
+	_, err = ParseTrustedProxies("10.0.0.0/33")
+	require.Error(t, err)
+	_, err = ParseTrustedProxies("not-an-ip")
+	require.Error(t, err)
+}
+
+func TestGetClientIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies("10.0.0.0/8,fd00::/8")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string][]string
+		expected   string
+	}{
+		{
+			name:       "direct ipv4",
+			remoteAddr: "203.0.113.7:5555",
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "direct ipv6",
+			remoteAddr: "[2001:db8::1]:1234",
+			expected:   "2001:db8::1",
+		},
+		{
+			name:       "ipv6 loopback",
+			remoteAddr: "[::1]:1234",
+			expected:   "::1",
+		},
+		{
+			name:       "ipv4-mapped ipv6",
+			remoteAddr: "[::ffff:203.0.113.7]:1234",
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "untrusted peer can't spoof xff",
+			remoteAddr: "203.0.113.7:5555",
+			headers:    map[string][]string{"X-Forwarded-For": {"1.1.1.1"}},
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "untrusted peer can't spoof x-real-ip",
+			remoteAddr: "203.0.113.7:5555",
+			headers:    map[string][]string{"X-Real-Ip": {"1.1.1.1"}},
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "trusted proxy xff",
+			remoteAddr: "10.0.0.2:5555",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.4"}},
+			expected:   "198.51.100.4",
+		},
+		{
+			name:       "spoofed leftmost xff entry is skipped",
+			remoteAddr: "10.0.0.2:5555",
+			headers:    map[string][]string{"X-Forwarded-For": {"1.1.1.1, 198.51.100.4, 10.0.0.3"}},
+			expected:   "198.51.100.4",
+		},
+		{
+			name:       "multiple xff headers",
+			remoteAddr: "10.0.0.2:5555",
+			headers:    map[string][]string{"X-Forwarded-For": {"1.1.1.1", "198.51.100.4"}},
+			expected:   "198.51.100.4",
+		},
+		{
+			name:       "all hops trusted",
+			remoteAddr: "10.0.0.2:5555",
+			headers:    map[string][]string{"X-Forwarded-For": {"10.0.0.9, 10.0.0.3"}},
+			expected:   "10.0.0.9",
+		},
+		{
+			name:       "garbage hop stops the walk",
+			remoteAddr: "10.0.0.2:5555",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.4, unknown, 10.0.0.3"}},
+			expected:   "10.0.0.3",
+		},
+		{
+			name:       "xff ipv6 hop with port",
+			remoteAddr: "[fd00::2]:443",
+			headers:    map[string][]string{"X-Forwarded-For": {"[2001:db8::7]:4711"}},
+			expected:   "2001:db8::7",
+		},
+		{
+			name:       "trusted proxy x-real-ip",
+			remoteAddr: "10.0.0.2:5555",
+			headers:    map[string][]string{"X-Real-Ip": {"198.51.100.4"}},
+			expected:   "198.51.100.4",
+		},
+		{
+			name:       "forwarded header",
+			remoteAddr: "10.0.0.2:5555",
+			headers: map[string][]string{"Forwarded": {
+				`for=1.1.1.1, for="[2001:db8:cafe::17]:4711";proto=https, for=10.0.0.3;by=10.0.0.2`,
+			}},
+			expected: "2001:db8:cafe::17",
+		},
+		{
+			name:       "forwarded takes precedence over xff",
+			remoteAddr: "10.0.0.2:5555",
+			headers: map[string][]string{
+				"Forwarded":       {"for=198.51.100.4"},
+				"X-Forwarded-For": {"1.1.1.1"},
+			},
+			expected: "198.51.100.4",
+		},
+		{
+			name:       "obfuscated forwarded node",
+			remoteAddr: "10.0.0.2:5555",
+			headers:    map[string][]string{"Forwarded": {"for=_hidden, for=10.0.0.3"}},
+			expected:   "10.0.0.3",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := &GatewayServer{TrustedProxies: trusted}
+
+			req := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+			req.RemoteAddr = tc.remoteAddr
+			for key, values := range tc.headers {
+				for _, value := range values {
+					req.Header.Add(key, value)
+				}
+			}
+
+			require.Equal(t, tc.expected, server.getClientIP(req))
+		})
+	}
+}
+
+func TestGetClientIP_NoTrustedProxies(t *testing.T) {
+	server := &GatewayServer{}
+
+	req := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+	req.RemoteAddr = "10.0.0.2:5555"
+	req.Header.Set("X-Forwarded-For", "198.51.100.4")
+	req.Header.Set("Forwarded", "for=198.51.100.4")
+
+	require.Equal(t, "10.0.0.2", server.getClientIP(req))
+}
@@ -0,0 +1,115 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorResponse is the JSON body of every gateway error.
+// Code is stable and meant for programs, Error is meant for humans.
+// Reason narrows Code down when the url-service gave one.
+type errorResponse struct {
+	Error  string `json:"error"`
+	Code   string `json:"code"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// grpcToHTTPStatus maps url-service status codes to HTTP statuses, anything else is a 500
+var grpcToHTTPStatus = map[codes.Code]int{
+	codes.InvalidArgument:   http.StatusBadRequest,
+	codes.Unauthenticated:   http.StatusUnauthorized,
+	codes.PermissionDenied:  http.StatusForbidden,
+	codes.NotFound:          http.StatusNotFound,
+	codes.AlreadyExists:     http.StatusConflict,
+	codes.ResourceExhausted: http.StatusTooManyRequests,
+	codes.Unavailable:       http.StatusServiceUnavailable,
+	codes.DeadlineExceeded:  http.StatusGatewayTimeout,
+}
+
+// errorCodes are the machine-readable codes returned for each HTTP status
+var errorCodes = map[int]string{
+	http.StatusBadRequest:          "invalid_argument",
+	http.StatusUnauthorized:        "unauthenticated",
+	http.StatusForbidden:           "permission_denied",
+	http.StatusNotFound:            "not_found",
+	http.StatusConflict:            "already_exists",
+	http.StatusGone:                "gone",
+	http.StatusTooManyRequests:     "resource_exhausted",
+	http.StatusInternalServerError: "internal",
+	http.StatusServiceUnavailable:  "unavailable",
+	http.StatusGatewayTimeout:      "deadline_exceeded",
+}
+
+// httpStatusFromGRPC returns the HTTP status for a gRPC error
+func httpStatusFromGRPC(err error) int {
+	if httpStatus, ok := grpcToHTTPStatus[status.Code(err)]; ok {
+		return httpStatus
+	}
+	return http.StatusInternalServerError
+}
+
+// errorCodeForStatus returns the machine-readable code for an HTTP status
+func errorCodeForStatus(httpStatus int) string {
+	if code, ok := errorCodes[httpStatus]; ok {
+		return code
+	}
+	if httpStatus >= http.StatusInternalServerError {
+		return "internal"
+	}
+	return "invalid_argument"
+}
+
+// errorReason returns the lowercased ErrorInfo reason attached to a gRPC error, if any
+func errorReason(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return strings.ToLower(info.Reason)
+		}
+	}
+	return ""
+}
+
+func respondWithError(w http.ResponseWriter, status int, msg string) {
+	writeErrorResponse(w, status, errorResponse{Error: msg, Code: errorCodeForStatus(status)})
+}
+
+func writeErrorResponse(w http.ResponseWriter, status int, body errorResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// respondWithHTTPError writes a JSON error and records it against the endpoint.
+func (s *GatewayServer) respondWithHTTPError(w http.ResponseWriter, r *http.Request, endpoint string, statusCode int, msg string) {
+	respondWithError(w, statusCode, msg)
+	s.Metrics.IncHTTPError("gateway", r.Method, endpoint, statusCode)
+}
+
+// respondWithGRPCError translates a failed gRPC call into an HTTP error response.
+// Client errors pass the url-service message through, server errors get a generic one.
+func (s *GatewayServer) respondWithGRPCError(w http.ResponseWriter, r *http.Request, endpoint, grpcMethod string, err error) {
+	s.Metrics.IncGRPCError("gateway", grpcMethod)
+
+	httpStatus := httpStatusFromGRPC(err)
+	body := errorResponse{
+		Error: status.Convert(err).Message(),
+		Code:  errorCodeForStatus(httpStatus),
+	}
+
+	if httpStatus >= http.StatusInternalServerError {
+		s.Logger.Error("gRPC call failed", zap.String("method", grpcMethod), zap.Error(err))
+		body.Error = strings.ToLower(http.StatusText(httpStatus))
+	} else {
+		s.Logger.Info("gRPC call rejected", zap.String("method", grpcMethod), zap.Error(err))
+		body.Reason = errorReason(err)
+	}
+
+	writeErrorResponse(w, httpStatus, body)
+	s.Metrics.IncHTTPError("gateway", r.Method, endpoint, httpStatus)
+}
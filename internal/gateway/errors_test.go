@@ -0,0 +1,150 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sammyqtran/url-shortener/internal/metrics"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recordingMetrics captures the status codes passed to IncHTTPError
+type recordingMetrics struct {
+	metrics.NoopMetrics
+	httpErrors []int
+	grpcErrors []string
+}
+
+func (m *recordingMetrics) IncHTTPError(service, method, endpoint string, statusCode int) {
+	m.httpErrors = append(m.httpErrors, statusCode)
+}
+
+func (m *recordingMetrics) IncGRPCError(service, method string) {
+	m.grpcErrors = append(m.grpcErrors, method)
+}
+
+func TestRespondWithGRPCError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedCode   string
+		expectedError  string
+		expectedReason string
+	}{
+		{
+			name:           "invalid argument",
+			err:            status.Error(codes.InvalidArgument, "invalid URL: URL must contain a valid domain"),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_argument",
+			expectedError:  "invalid URL: URL must contain a valid domain",
+		},
+		{
+			name:           "not found",
+			err:            status.Error(codes.NotFound, "URL not found"),
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "not_found",
+			expectedError:  "URL not found",
+		},
+		{
+			name:           "already exists",
+			err:            status.Error(codes.AlreadyExists, "alias taken"),
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "already_exists",
+			expectedError:  "alias taken",
+		},
+		{
+			name:           "permission denied",
+			err:            status.Error(codes.PermissionDenied, "URL belongs to another user"),
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "permission_denied",
+			expectedError:  "URL belongs to another user",
+		},
+		{
+			name:           "permission denied with reason",
+			err:            blockedDestinationError(t),
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "permission_denied",
+			expectedError:  "destination bit.ly is blocked",
+			expectedReason: "blocked_domain",
+		},
+		{
+			name:           "resource exhausted",
+			err:            status.Error(codes.ResourceExhausted, "quota exceeded"),
+			expectedStatus: http.StatusTooManyRequests,
+			expectedCode:   "resource_exhausted",
+			expectedError:  "quota exceeded",
+		},
+		{
+			name:           "unavailable hides details",
+			err:            status.Error(codes.Unavailable, "connection refused to 10.0.0.3:50051"),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedCode:   "unavailable",
+			expectedError:  "service unavailable",
+		},
+		{
+			name:           "deadline exceeded",
+			err:            status.FromContextError(context.DeadlineExceeded).Err(),
+			expectedStatus: http.StatusGatewayTimeout,
+			expectedCode:   "deadline_exceeded",
+			expectedError:  "gateway timeout",
+		},
+		{
+			name:           "internal hides details",
+			err:            status.Error(codes.Internal, "failed to create URL: pq: connection reset"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "internal",
+			expectedError:  "internal server error",
+		},
+		{
+			name:           "non status error",
+			err:            errors.New("grpc failure"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "internal",
+			expectedError:  "internal server error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := &recordingMetrics{}
+			server := &GatewayServer{
+				Logger:  zap.NewNop(),
+				Metrics: recorder,
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+			server.respondWithGRPCError(w, req, "/{shortCode}", "GetOriginalURL", tc.err)
+
+			require.Equal(t, tc.expectedStatus, w.Code)
+			require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+			var body errorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			require.Equal(t, tc.expectedCode, body.Code)
+			require.Equal(t, tc.expectedError, body.Error)
+			require.Equal(t, tc.expectedReason, body.Reason)
+
+			require.Equal(t, []int{tc.expectedStatus}, recorder.httpErrors)
+			require.Equal(t, []string{"GetOriginalURL"}, recorder.grpcErrors)
+		})
+	}
+}
+
+func blockedDestinationError(t *testing.T) error {
+	st, err := status.New(codes.PermissionDenied, "destination bit.ly is blocked").WithDetails(&errdetails.ErrorInfo{
+		Reason: "BLOCKED_DOMAIN",
+		Domain: "url-shortener",
+	})
+	require.NoError(t, err)
+	return st.Err()
+}
@@ -3,22 +3,81 @@ package gateway
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"html/template"
 	"net/http"
+	"net/netip"
 	"strings"
 	"time"
 
+	"github.com/gorilla/mux"
+	"github.com/sammyqtran/url-shortener/internal/auth"
 	"github.com/sammyqtran/url-shortener/internal/events"
 	"github.com/sammyqtran/url-shortener/internal/metrics"
+	"github.com/sammyqtran/url-shortener/internal/passthrough"
 	"github.com/sammyqtran/url-shortener/internal/queue"
+	"github.com/sammyqtran/url-shortener/internal/ratelimit"
 	pb "github.com/sammyqtran/url-shortener/proto"
 	"go.uber.org/zap"
 )
 
 type GatewayServer struct {
-	GrpcClient pb.URLServiceClient
-	Publisher  queue.EventPublisher
-	Logger     *zap.Logger
-	Metrics    metrics.Metrics
+	GrpcClient  pb.URLServiceClient
+	AuthClient  pb.APIKeyServiceClient
+	JWTVerifier *auth.JWTVerifier // nil disables JWT authentication
+	Publisher   queue.EventPublisher
+	Logger      *zap.Logger
+	Metrics     metrics.Metrics
+
+	// RateLimiter is shared by all replicas through Redis, nil disables rate limiting
+	RateLimiter   ratelimit.RateLimiter
+	CreateLimit   ratelimit.Limit
+	RedirectLimit ratelimit.Limit
+	// AuthLimit is counted per client IP before credentials are checked, so
+	// made-up API keys can't hammer the key lookup
+	AuthLimit ratelimit.Limit
+
+	// TrustedProxies are the only peers whose forwarding headers are believed
+	TrustedProxies []netip.Prefix
+
+	// PermanentRedirectMaxAge is how long clients may cache 301 and 308
+	// redirects, which then aren't counted. 0 disables caching.
+	PermanentRedirectMaxAge time.Duration
+
+	// VisitorSecret signs visitor_id cookies, nil turns the cookies off and
+	// visitors are told apart by address and User-Agent only
+	VisitorSecret []byte
+}
+
+// NewRouter registers all gateway routes
+func (s *GatewayServer) NewRouter() *mux.Router {
+	r := mux.NewRouter()
+
+	r.Handle("/create", s.authenticate(s.RateLimit(rateLimitPolicyCreate, s.CreateLimit,
+		s.RequireScope(auth.ScopeLinksWrite, s.HandleCreateShortURL)))).Methods("POST")
+	r.HandleFunc("/healthz", s.HandleHealthCheck).Methods("GET")
+
+	// links are scoped to their owner, so every /api/v1 route needs credentials
+	api := r.PathPrefix("/api/v1").Subrouter()
+	api.Use(s.authenticate)
+	api.Handle("/links", s.RequireScope(auth.ScopeLinksRead, s.HandleListLinks)).Methods("GET")
+	api.Handle("/links", s.RateLimit(rateLimitPolicyCreate, s.CreateLimit,
+		s.RequireScope(auth.ScopeLinksWrite, s.HandleCreateLink))).Methods("POST")
+	api.Handle("/links/{code}", s.RequireScope(auth.ScopeLinksRead, s.HandleGetLink)).Methods("GET")
+	api.Handle("/links/{code}", s.RequireScope(auth.ScopeLinksWrite, s.HandleUpdateLink)).Methods("PATCH")
+	api.Handle("/links/{code}", s.RequireScope(auth.ScopeLinksWrite, s.HandleDeleteLink)).Methods("DELETE")
+	api.Handle("/links/{code}/stats", s.RequireScope(auth.ScopeAnalyticsRead, s.HandleGetLinkStats)).Methods("GET")
+
+	// registered first, "/{shortCode}" would also match a trailing "+"
+	r.Handle("/{shortCode}+", s.RateLimit(rateLimitPolicyRedirect, s.RedirectLimit,
+		http.HandlerFunc(s.HandlePreview))).Methods("GET")
+	// POST is only redirected by links that keep the method, 307 and 308.
+	// The path after a short code is only forwarded by wildcard links.
+	redirect := s.RateLimit(rateLimitPolicyRedirect, s.RedirectLimit, http.HandlerFunc(s.HandleGetOriginalURL))
+	r.Handle("/{shortCode}", redirect).Methods("GET", "POST")
+	r.Handle("/{shortCode}/{rest:.*}", redirect).Methods("GET", "POST")
+
+	return r
 }
 
 func (s *GatewayServer) HandleCreateShortURL(w http.ResponseWriter, r *http.Request) {
@@ -37,9 +96,7 @@ func (s *GatewayServer) HandleCreateShortURL(w http.ResponseWriter, r *http.Requ
 	)
 	defer r.Body.Close()
 
-	var req struct {
-		URL string `json:"url"`
-	}
+	var req createLinkRequest
 
 	jsonErr := json.NewDecoder(r.Body).Decode(&req)
 
@@ -60,10 +117,7 @@ func (s *GatewayServer) HandleCreateShortURL(w http.ResponseWriter, r *http.Requ
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	request := &pb.CreateURLRequest{
-		OriginalUrl: req.URL,
-		UserId:      "abc123",
-	}
+	request := toCreateURLRequest(req, s.getUserID(r))
 
 	// increment grpc calls and time call
 	s.Metrics.IncGRPCCall("gateway", "CreateShortURL")
@@ -72,29 +126,22 @@ func (s *GatewayServer) HandleCreateShortURL(w http.ResponseWriter, r *http.Requ
 	s.Metrics.ObserveGRPCLatency("gateway", "CreateShortURL", time.Since(gRPCTimer).Seconds())
 
 	if err != nil {
-		s.Logger.Error("gRPC CreateShortURL failed", zap.Error(err))
-		respondWithError(w, http.StatusInternalServerError, "Failed to create short URL")
-		s.Metrics.IncHTTPError("gateway", r.Method, "/create", http.StatusInternalServerError)
-		s.Metrics.IncGRPCError("gateway", "CreateShortURL")
+		s.respondWithGRPCError(w, r, "/create", "CreateShortURL", err)
 		return
 	}
 
-	// Publish URL created event
-	if s.Publisher != nil {
-		go func() {
-			s.Metrics.IncPublishEvent("gateway", string(events.URLCreatedEvent))
-			ctx := context.Background()
-			eventPublishTimer := time.Now()
-			err := s.Publisher.PublishURLCreated(ctx, response.ShortCode, req.URL, s.getClientInfo(r))
-			s.Metrics.ObservePublishEventLatency("gateway", string(events.URLCreatedEvent), time.Since(eventPublishTimer).Seconds())
-			if err != nil {
-				s.Metrics.IncPublishEventError("gateway", string(events.URLCreatedEvent))
-				s.Logger.Error("Failed to publish URL created event", zap.Error(err))
-			}
-		}()
+	// Publish URL created event, dedupe hits didn't create anything
+	if !response.Existing {
+		s.publishURLCreated(r, response.ShortCode, req.URL, response.CampaignId)
 	}
 
-	resp := map[string]string{"shortcode": response.ShortCode}
+	resp := map[string]interface{}{"shortcode": response.ShortCode}
+	if req.Dedupe {
+		resp["created"] = !response.Existing
+	}
+	if response.ExpiresAt != 0 {
+		resp["expires_at"] = time.Unix(response.ExpiresAt, 0).UTC().Format(time.RFC3339)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 
@@ -117,7 +164,8 @@ func (s *GatewayServer) HandleGetOriginalURL(w http.ResponseWriter, r *http.Requ
 		zap.String("path", r.URL.Path),
 		zap.String("client_ip", s.getClientIP(r)),
 	)
-	shortCode := strings.TrimPrefix(r.URL.Path, "/")
+	// rest stays escaped so it reaches the destination as it was sent
+	shortCode, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.EscapedPath(), "/"), "/")
 
 	if shortCode == "" || shortCode == "create" || shortCode == "healthz" {
 		s.Logger.Warn("Invalid shortCode path requested", zap.String("shortCode", shortCode))
@@ -126,8 +174,14 @@ func (s *GatewayServer) HandleGetOriginalURL(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	clientIP := s.getClientIP(r)
+	visitor, newVisitor := s.visitorID(r, clientIP)
 	request := &pb.GetURLRequest{
 		ShortCode: shortCode,
+		WithPath:  rest != "",
+		ClientIp:  clientIP,
+		UserAgent: r.UserAgent(),
+		VisitorId: visitor,
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
@@ -140,34 +194,84 @@ func (s *GatewayServer) HandleGetOriginalURL(w http.ResponseWriter, r *http.Requ
 	s.Metrics.ObserveGRPCLatency(service, "GetOriginalURL", time.Since(grpcTimer).Seconds())
 
 	if err != nil {
-		s.Logger.Error("gRPC GetOriginalURL failed", zap.Error(err))
-		respondWithError(w, http.StatusInternalServerError, "internal server error")
-		s.Metrics.IncHTTPError(service, method, endpoint, http.StatusInternalServerError)
-		s.Metrics.IncGRPCError(service, "GetOriginalURL")
+		s.respondWithGRPCError(w, r, endpoint, "GetOriginalURL", err)
+		return
+	}
+
+	if response.Expired {
+		respondWithError(w, http.StatusGone, "short URL has expired")
+		s.Metrics.IncHTTPError(service, method, endpoint, http.StatusGone)
+		return
+	}
+
+	if response.Exhausted {
+		respondWithError(w, http.StatusGone, "short URL has no clicks left")
+		s.Metrics.IncHTTPError(service, method, endpoint, http.StatusGone)
+		return
+	}
+
+	// launch links look missing until they go live
+	if response.Inactive {
+		respondWithError(w, http.StatusNotFound, "short URL is not active yet")
+		s.Metrics.IncHTTPError(service, method, endpoint, http.StatusNotFound)
 		return
 	}
 
 	if !response.Found {
-		http.NotFound(w, r)
+		respondWithError(w, http.StatusNotFound, "short URL not found")
 		s.Metrics.IncHTTPError(service, method, endpoint, http.StatusNotFound)
 		return
 	}
 
+	// flagged destinations get a warning instead of a redirect
+	if response.Quarantined {
+		s.Logger.Info("Blocked redirect to quarantined link", zap.String("shortCode", shortCode))
+		s.renderPage(w, http.StatusForbidden, "quarantined", quarantinedPageData{ShortCode: shortCode})
+		s.Metrics.IncHTTPError(service, method, endpoint, http.StatusForbidden)
+		return
+	}
+
+	destination, err := passthrough.Apply(response.OriginalUrl, rest, r.URL.RawQuery, passthrough.Options{
+		Query: response.QueryPassthrough,
+		Path:  response.PathPassthrough,
+	})
+	if err != nil {
+		s.Logger.Warn("Rejected passthrough path", zap.String("shortCode", shortCode), zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "invalid path")
+		s.Metrics.IncHTTPError(service, method, endpoint, http.StatusBadRequest)
+		return
+	}
+
+	redirectType := int(response.RedirectType)
+	if redirectType == 0 {
+		// url-service predates per-link redirect types
+		redirectType = http.StatusFound
+	}
+	if r.Method != http.MethodGet && !keepsMethod(redirectType) {
+		w.Header().Set("Allow", http.MethodGet)
+		respondWithError(w, http.StatusMethodNotAllowed, "short URL only redirects GET requests")
+		s.Metrics.IncHTTPError(service, method, endpoint, http.StatusMethodNotAllowed)
+		return
+	}
+
 	// Publish URL accessed event
 	if s.Publisher != nil {
+		accessed := queue.URLAccessedEvent{
+			ShortCode:   shortCode,
+			OriginalURL: response.OriginalUrl,
+			UserAgent:   r.UserAgent(),
+			IPAddress:   s.getClientIP(r),
+			Country:     response.Country,
+			Referrer:    r.Header.Get("Referer"),
+			CampaignID:  response.CampaignId,
+			Variant:     response.Variant,
+		}
 		go func() {
 			s.Metrics.IncPublishEvent("gateway", string(events.URLAccessedEvent))
 			ctx := context.Background()
 
 			eventTimer := time.Now()
-			err := s.Publisher.PublishURLAccessed(
-				ctx,
-				shortCode,
-				response.OriginalUrl,
-				r.UserAgent(),
-				s.getClientIP(r),
-				r.Header.Get("Referer"),
-			)
+			err := s.Publisher.PublishURLAccessed(ctx, accessed)
 			s.Metrics.ObservePublishEventLatency(service, string(events.URLAccessedEvent), time.Since(eventTimer).Seconds())
 			if err != nil {
 				s.Metrics.IncPublishEventError("gateway", string(events.URLAccessedEvent))
@@ -176,8 +280,123 @@ func (s *GatewayServer) HandleGetOriginalURL(w http.ResponseWriter, r *http.Requ
 		}()
 	}
 
-	http.Redirect(w, r, response.OriginalUrl, http.StatusFound)
+	// the visitor only needs an ID once they've been put in a variant
+	if response.Variant != "" && newVisitor {
+		s.setVisitorCookie(w, visitor)
+	}
+
+	// the click is already counted, the page only asks before leaving. It
+	// can't carry a request body on, so other methods redirect directly.
+	if response.Interstitial && r.Method == http.MethodGet {
+		s.renderPage(w, http.StatusOK, "interstitial", interstitialPageData{
+			ShortCode:   shortCode,
+			Destination: template.URL(destination),
+		})
+		return
+	}
+
+	// a cached redirect of a click-limited link would be a click nobody counted
+	if response.ClickLimited {
+		w.Header().Set("Cache-Control", "no-store")
+	} else {
+		s.setRedirectCacheHeaders(w, redirectType, earliestUnix(response.ExpiresAt, response.ChangesAt), response.PerVisitor)
+	}
+	http.Redirect(w, r, destination, redirectType)
+
+}
+
+// keepsMethod reports whether clients repeat the request method and body
+// when following a redirect with this status
+func keepsMethod(redirectType int) bool {
+	return redirectType == http.StatusTemporaryRedirect || redirectType == http.StatusPermanentRedirect
+}
+
+// setRedirectCacheHeaders lets clients keep permanent redirects for up to
+// PermanentRedirectMaxAge, never past until, the link's expiry or next
+// scheduled change (0 for neither). Temporary redirects aren't stored so
+// every click reaches the gateway and is counted. A perVisitor redirect,
+// such as a geo-routed one, is only kept by the visitor's own browser.
+func (s *GatewayServer) setRedirectCacheHeaders(w http.ResponseWriter, redirectType int, until int64, perVisitor bool) {
+	maxAge := s.PermanentRedirectMaxAge
+	if until != 0 {
+		maxAge = min(maxAge, time.Until(time.Unix(until, 0)))
+	}
+
+	permanent := redirectType == http.StatusMovedPermanently || redirectType == http.StatusPermanentRedirect
+	if !permanent || maxAge < time.Second {
+		w.Header().Set("Cache-Control", "no-store")
+		return
+	}
+	scope := "public"
+	if perVisitor {
+		scope = "private"
+	}
+	w.Header().Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", scope, int64(maxAge/time.Second)))
+}
+
+// earliestUnix is the earlier of two unix times, 0 meaning unset
+func earliestUnix(a, b int64) int64 {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// HandlePreview shows where a short link goes without following it
+func (s *GatewayServer) HandlePreview(w http.ResponseWriter, r *http.Request) {
+	const endpoint = "/{shortCode}+"
+	defer s.trackRequest(r.Method, endpoint)()
+
+	shortCode := mux.Vars(r)["shortCode"]
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	s.Metrics.IncGRPCCall("gateway", "GetURLPreview")
+	grpcTimer := time.Now()
+	response, err := s.GrpcClient.GetURLPreview(ctx, &pb.GetURLPreviewRequest{ShortCode: shortCode})
+	s.Metrics.ObserveGRPCLatency("gateway", "GetURLPreview", time.Since(grpcTimer).Seconds())
+	if err != nil {
+		s.respondWithGRPCError(w, r, endpoint, "GetURLPreview", err)
+		return
+	}
+
+	statusCode := http.StatusOK
+	if response.Expired || response.Exhausted {
+		statusCode = http.StatusGone
+	}
+	s.renderPage(w, statusCode, "preview", previewPageData{
+		ShortCode:   response.ShortCode,
+		Destination: response.OriginalUrl,
+		CreatedAt:   time.Unix(response.CreatedAt, 0).UTC(),
+		ClickCount:  response.ClickCount,
+		ExpiresAt:   unixTimePtr(response.ExpiresAt),
+		Expired:     response.Expired,
+		Quarantined: response.Quarantined,
+		ActiveFrom:  unixTimePtr(response.ActiveFrom),
+		Inactive:    response.Inactive,
+		MaxClicks:   response.MaxClicks,
+		Exhausted:   response.Exhausted,
+	})
+}
 
+// publishURLCreated publishes a URL created event in the background
+func (s *GatewayServer) publishURLCreated(r *http.Request, shortCode, originalURL string, campaignID int64) {
+	if s.Publisher == nil {
+		return
+	}
+	createdBy := s.getClientInfo(r)
+	go func() {
+		s.Metrics.IncPublishEvent("gateway", string(events.URLCreatedEvent))
+		ctx := context.Background()
+		eventPublishTimer := time.Now()
+		err := s.Publisher.PublishURLCreated(ctx, shortCode, originalURL, createdBy, campaignID)
+		s.Metrics.ObservePublishEventLatency("gateway", string(events.URLCreatedEvent), time.Since(eventPublishTimer).Seconds())
+		if err != nil {
+			s.Metrics.IncPublishEventError("gateway", string(events.URLCreatedEvent))
+			s.Logger.Error("Failed to publish URL created event", zap.Error(err))
+		}
+	}()
 }
 
 func (s *GatewayServer) HandleHealthCheck(w http.ResponseWriter, r *http.Request) {
@@ -192,30 +411,18 @@ func (s *GatewayServer) HandleHealthCheck(w http.ResponseWriter, r *http.Request
 	json.NewEncoder(w).Encode(response)
 }
 
-func respondWithError(w http.ResponseWriter, status int, msg string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]string{"error": msg})
-}
-
-func (s *GatewayServer) getClientIP(r *http.Request) string {
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		ips := strings.Split(xff, ",")
-		return strings.TrimSpace(ips[0])
-	}
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
-		return xri
+// getUserID returns the user resolved by Authenticate, or "" on public routes
+func (s *GatewayServer) getUserID(r *http.Request) string {
+	if caller, ok := principalFromContext(r.Context()); ok {
+		return caller.UserID
 	}
-	ip := r.RemoteAddr
-	if colon := strings.LastIndex(ip, ":"); colon != -1 {
-		ip = ip[:colon]
-	}
-	return ip
+	return ""
 }
 
 // getClientInfo extracts client information from request (for analytics)
 func (g *GatewayServer) getClientInfo(r *http.Request) string {
-	// Extract user info if available (e.g., from JWT token, API key, etc.)
-	// For now, return IP address
+	if caller, ok := principalFromContext(r.Context()); ok {
+		return caller.UserID
+	}
 	return g.getClientIP(r)
 }
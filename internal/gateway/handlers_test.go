@@ -10,10 +10,15 @@ import (
 	"time"
 
 	"github.com/sammyqtran/url-shortener/internal/metrics"
+	"github.com/sammyqtran/url-shortener/internal/passthrough"
+	"github.com/sammyqtran/url-shortener/internal/queue"
 	pb "github.com/sammyqtran/url-shortener/proto"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // mock event publisher
@@ -26,25 +31,64 @@ type MockPublisher struct {
 	PublishedUser      string
 	UserAgent          string
 	ipAddress          string
+	Country            string
 	referrer           string
+	CampaignID         int64
+	Variant            string
 	Err                error
+
+	// published is signalled after each publish, see newMockPublisher
+	published chan struct{}
+}
+
+// newMockPublisher returns a publisher that tests can wait on, publishing
+// happens in a goroutine after the handler returns
+func newMockPublisher() *MockPublisher {
+	return &MockPublisher{published: make(chan struct{}, 1)}
 }
 
-func (m *MockPublisher) PublishURLCreated(ctx context.Context, shortCode, originalURL, createdBy string) error {
+// waitPublished blocks until an event is published, after which the
+// recorded fields are safe to read
+func (m *MockPublisher) waitPublished(t *testing.T) {
+	t.Helper()
+	select {
+	case <-m.published:
+	case <-time.After(time.Second):
+		t.Fatal("expected an event to be published")
+	}
+}
+
+func (m *MockPublisher) signal() {
+	if m.published == nil {
+		return
+	}
+	select {
+	case m.published <- struct{}{}:
+	default:
+	}
+}
+
+func (m *MockPublisher) PublishURLCreated(ctx context.Context, shortCode, originalURL, createdBy string, campaignID int64) error {
 	m.Called = true
+	m.CampaignID = campaignID
 	m.PublishedShortCode = shortCode
 	m.PublishedURL = originalURL
 	m.PublishedUser = createdBy
+	m.signal()
 	return m.Err
 }
 
-func (m *MockPublisher) PublishURLAccessed(ctx context.Context, shortCode, originalURL, userAgent, ipAddress, referrer string) error {
+func (m *MockPublisher) PublishURLAccessed(ctx context.Context, event queue.URLAccessedEvent) error {
 	m.Called = true
-	m.PublishedShortCode = shortCode
-	m.PublishedURL = originalURL
-	m.UserAgent = userAgent
-	m.ipAddress = ipAddress
-	m.referrer = referrer
+	m.Country = event.Country
+	m.Variant = event.Variant
+	m.CampaignID = event.CampaignID
+	m.PublishedShortCode = event.ShortCode
+	m.PublishedURL = event.OriginalURL
+	m.UserAgent = event.UserAgent
+	m.ipAddress = event.IPAddress
+	m.referrer = event.Referrer
+	m.signal()
 	return m.Err
 }
 
@@ -88,6 +132,61 @@ func (m *MockURLServiceClient) HealthCheck(ctx context.Context,
 	return resp.(*pb.HealthResponse), args.Error(1)
 }
 
+func (m *MockURLServiceClient) UpdateShortURL(ctx context.Context,
+	in *pb.UpdateURLRequest, opts ...grpc.CallOption) (*pb.UpdateURLResponse, error) {
+
+	args := m.Called(ctx, in, opts)
+	resp := args.Get(0)
+	if resp == nil {
+		return nil, args.Error(1)
+	}
+	return resp.(*pb.UpdateURLResponse), args.Error(1)
+}
+
+func (m *MockURLServiceClient) DeleteShortURL(ctx context.Context,
+	in *pb.DeleteURLRequest, opts ...grpc.CallOption) (*pb.DeleteURLResponse, error) {
+
+	args := m.Called(ctx, in, opts)
+	resp := args.Get(0)
+	if resp == nil {
+		return nil, args.Error(1)
+	}
+	return resp.(*pb.DeleteURLResponse), args.Error(1)
+}
+
+func (m *MockURLServiceClient) GetURLDetails(ctx context.Context,
+	in *pb.GetURLDetailsRequest, opts ...grpc.CallOption) (*pb.GetURLDetailsResponse, error) {
+
+	args := m.Called(ctx, in, opts)
+	resp := args.Get(0)
+	if resp == nil {
+		return nil, args.Error(1)
+	}
+	return resp.(*pb.GetURLDetailsResponse), args.Error(1)
+}
+
+func (m *MockURLServiceClient) ListURLs(ctx context.Context,
+	in *pb.ListURLsRequest, opts ...grpc.CallOption) (*pb.ListURLsResponse, error) {
+
+	args := m.Called(ctx, in, opts)
+	resp := args.Get(0)
+	if resp == nil {
+		return nil, args.Error(1)
+	}
+	return resp.(*pb.ListURLsResponse), args.Error(1)
+}
+
+func (m *MockURLServiceClient) GetURLPreview(ctx context.Context,
+	in *pb.GetURLPreviewRequest, opts ...grpc.CallOption) (*pb.GetURLPreviewResponse, error) {
+
+	args := m.Called(ctx, in, opts)
+	resp := args.Get(0)
+	if resp == nil {
+		return nil, args.Error(1)
+	}
+	return resp.(*pb.GetURLPreviewResponse), args.Error(1)
+}
+
 func TestHandlleHealthCheck(t *testing.T) {
 
 	mockClient := new(MockURLServiceClient)
@@ -157,6 +256,83 @@ func TestHandleGetOriginalURL(t *testing.T) {
 			},
 			expectedCode: http.StatusNotFound,
 		},
+		{
+			name:           "URL expired",
+			shortCode:      "abc123",
+			expectGrpcCall: true,
+			mockResponse: &pb.GetURLResponse{
+				Found:   false,
+				Expired: true,
+				Error:   "URL has expired",
+			},
+			expectError:   true,
+			expectedError: "short URL has expired",
+			expectedCode:  http.StatusGone,
+		},
+		{
+			name:           "URL not active yet",
+			shortCode:      "abc123",
+			expectGrpcCall: true,
+			mockResponse: &pb.GetURLResponse{
+				Found:    false,
+				Inactive: true,
+				Error:    "URL is not active yet",
+			},
+			expectError:   true,
+			expectedError: "short URL is not active yet",
+			expectedCode:  http.StatusNotFound,
+		},
+		{
+			name:           "URL used up",
+			shortCode:      "abc123",
+			expectGrpcCall: true,
+			mockResponse: &pb.GetURLResponse{
+				Found:     false,
+				Exhausted: true,
+				Error:     "URL has no clicks left",
+			},
+			expectError:   true,
+			expectedError: "short URL has no clicks left",
+			expectedCode:  http.StatusGone,
+		},
+		{
+			name:           "URL quarantined",
+			shortCode:      "abc123",
+			expectGrpcCall: true,
+			mockResponse: &pb.GetURLResponse{
+				Found:       true,
+				Quarantined: true,
+			},
+			expectError:   true,
+			expectedError: "This link has been disabled",
+			expectedCode:  http.StatusForbidden,
+		},
+		{
+			name:           "URL with interstitial",
+			shortCode:      "abc123",
+			expectGrpcCall: true,
+			mockResponse: &pb.GetURLResponse{
+				OriginalUrl:  "https://google.com/?q=a&b",
+				Found:        true,
+				Interstitial: true,
+			},
+			expectError:   true,
+			expectedError: `<a href="https://google.com/?q=a&amp;b"`,
+			expectedCode:  http.StatusOK,
+		},
+		{
+			name:           "interstitial to an Android app",
+			shortCode:      "abc123",
+			expectGrpcCall: true,
+			mockResponse: &pb.GetURLResponse{
+				OriginalUrl:  "intent://open/#Intent;scheme=shop;package=com.example.shop;end",
+				Found:        true,
+				Interstitial: true,
+			},
+			expectError:   true,
+			expectedError: `<a href="intent://open/#Intent;scheme=shop;package=com.example.shop;end"`,
+			expectedCode:  http.StatusOK,
+		},
 	}
 
 	for _, tc := range tests {
@@ -227,7 +403,7 @@ func TestHandleCreateShortURL(t *testing.T) {
 			name:           "json parsing issue",
 			inputBody:      `{"url:`,
 			mockResponse:   nil,
-			expectedBody:   `{"error":"bad request"}`,
+			expectedBody:   `{"error":"bad request","code":"invalid_argument"}`,
 			expectGrpcCall: false,
 			expectedCode:   http.StatusBadRequest,
 		},
@@ -237,7 +413,46 @@ func TestHandleCreateShortURL(t *testing.T) {
 			mockResponse:   nil,
 			mockError:      errors.New("grpc failure"),
 			expectedCode:   http.StatusInternalServerError,
-			expectedBody:   `{"error":"Failed to create short URL"}`,
+			expectedBody:   `{"error":"internal server error","code":"internal"}`,
+			expectGrpcCall: true,
+		},
+		{
+			name:      "custom alias",
+			inputBody: `{"url": "https://example.com", "custom_alias": "spring-sale"}`,
+			mockResponse: &pb.CreateURLResponse{
+				ShortCode: "spring-sale",
+				Success:   true,
+			},
+			expectedCode:   http.StatusOK,
+			expectedBody:   `{"shortcode":"spring-sale"}`,
+			expectGrpcCall: true,
+		},
+		{
+			name:      "ttl seconds",
+			inputBody: `{"url": "https://example.com", "ttl_seconds": 3600}`,
+			mockResponse: &pb.CreateURLResponse{
+				ShortCode: "abc123",
+				Success:   true,
+				ExpiresAt: 1767225600,
+			},
+			expectedCode:   http.StatusOK,
+			expectedBody:   `{"expires_at":"2026-01-01T00:00:00Z","shortcode":"abc123"}`,
+			expectGrpcCall: true,
+		},
+		{
+			name:           "custom alias taken",
+			inputBody:      `{"url": "https://example.com", "custom_alias": "spring-sale"}`,
+			mockError:      status.Error(codes.AlreadyExists, `alias "spring-sale" is already taken`),
+			expectedCode:   http.StatusConflict,
+			expectedBody:   `{"error":"alias \"spring-sale\" is already taken","code":"already_exists"}`,
+			expectGrpcCall: true,
+		},
+		{
+			name:           "custom alias invalid",
+			inputBody:      `{"url": "https://example.com", "custom_alias": "create"}`,
+			mockError:      status.Error(codes.InvalidArgument, `invalid alias: alias "create" is reserved`),
+			expectedCode:   http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid alias: alias \"create\" is reserved","code":"invalid_argument"}`,
 			expectGrpcCall: true,
 		},
 	}
@@ -280,7 +495,7 @@ func TestHandleCreateShortURL(t *testing.T) {
 func TestPublishCreate(t *testing.T) {
 
 	mockClient := new(MockURLServiceClient)
-	mockPublisher := new(MockPublisher)
+	mockPublisher := newMockPublisher()
 	mockMetrics := &metrics.NoopMetrics{}
 	service := &GatewayServer{
 		GrpcClient: mockClient,
@@ -300,11 +515,7 @@ func TestPublishCreate(t *testing.T) {
 
 	service.HandleCreateShortURL(w, req)
 
-	time.Sleep(50 * time.Millisecond) // wait for goroutine
-
-	if !mockPublisher.Called {
-		t.Fatal("expected PublishURLCreated to be called")
-	}
+	mockPublisher.waitPublished(t)
 
 	if mockPublisher.PublishedShortCode != "test123" {
 		t.Errorf("expected shortcode test123, got %s", mockPublisher.PublishedShortCode)
@@ -315,10 +526,45 @@ func TestPublishCreate(t *testing.T) {
 	}
 }
 
+func TestPublishCreate_SkipsExistingLink(t *testing.T) {
+	mockClient := new(MockURLServiceClient)
+	mockPublisher := new(MockPublisher)
+	service := &GatewayServer{
+		GrpcClient: mockClient,
+		Publisher:  mockPublisher,
+		Logger:     zap.NewNop(),
+		Metrics:    &metrics.NoopMetrics{},
+	}
+
+	mockClient.
+		On("CreateShortURL", mock.Anything, mock.MatchedBy(func(req *pb.CreateURLRequest) bool {
+			return req.Dedupe
+		}), mock.Anything).
+		Return(&pb.CreateURLResponse{ShortCode: "test123", Existing: true}, nil)
+
+	body := `{"url": "https://example.com", "dedupe": true}`
+	req := httptest.NewRequest(http.MethodPost, "/create", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	service.HandleCreateShortURL(w, req)
+
+	time.Sleep(50 * time.Millisecond) // wait for goroutine
+
+	expected := `{"created":false,"shortcode":"test123"}`
+	if got := strings.TrimSpace(w.Body.String()); got != expected {
+		t.Errorf("expected body %s, got %s", expected, got)
+	}
+
+	if mockPublisher.Called {
+		t.Fatal("expected PublishURLCreated not to be called for an existing link")
+	}
+}
+
 func TestPublishAccess(t *testing.T) {
 	mockMetrics := &metrics.NoopMetrics{}
 	mockClient := new(MockURLServiceClient)
-	mockPublisher := new(MockPublisher)
+	mockPublisher := newMockPublisher()
 	service := &GatewayServer{
 		GrpcClient: mockClient,
 		Publisher:  mockPublisher,
@@ -331,10 +577,397 @@ func TestPublishAccess(t *testing.T) {
 		Return(&pb.GetURLResponse{
 			OriginalUrl: "https://google.com",
 			Found:       true,
+			CampaignId:  7,
+			Country:     "GB",
+			Variant:     "b",
 		}, nil)
 
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/abc123", nil)
 	service.HandleGetOriginalURL(w, req)
 
+	mockPublisher.waitPublished(t)
+
+	if mockPublisher.CampaignID != 7 {
+		t.Errorf("expected campaign 7, got %d", mockPublisher.CampaignID)
+	}
+
+	if mockPublisher.Country != "GB" {
+		t.Errorf("expected country GB, got %s", mockPublisher.Country)
+	}
+
+	if mockPublisher.Variant != "b" {
+		t.Errorf("expected variant b, got %s", mockPublisher.Variant)
+	}
+}
+
+func TestVisitorCookie(t *testing.T) {
+	const existing = "0123456789abcdef0123456789abcdef"
+	secret := []byte("test-secret")
+
+	tests := []struct {
+		name            string
+		cookie          string
+		variant         string
+		expectVisitorID string
+		expectSetCookie bool
+	}{
+		{name: "new visitor put in a variant", variant: "a", expectSetCookie: true},
+		{name: "returning visitor", cookie: signVisitorID(secret, existing), variant: "a", expectVisitorID: existing},
+		{name: "link without variants", variant: ""},
+		{name: "cookie we didn't issue", cookie: "not-an-id", variant: "a", expectSetCookie: true},
+		{name: "unsigned visitor ID", cookie: existing, variant: "a", expectSetCookie: true},
+		{name: "signed with another secret", cookie: signVisitorID([]byte("other-secret"), existing), variant: "a", expectSetCookie: true},
+		{name: "tampered ID", cookie: "f" + signVisitorID(secret, existing)[1:], variant: "a", expectSetCookie: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(MockURLServiceClient)
+			service := &GatewayServer{
+				GrpcClient:    mockClient,
+				Logger:        zap.NewNop(),
+				Metrics:       &metrics.NoopMetrics{},
+				VisitorSecret: secret,
+			}
+
+			var sentVisitorID string
+			mockClient.
+				On("GetOriginalURL", mock.Anything, mock.MatchedBy(func(req *pb.GetURLRequest) bool {
+					sentVisitorID = req.VisitorId
+					return true
+				}), mock.Anything).
+				Return(&pb.GetURLResponse{OriginalUrl: "https://example.com/a", Found: true, Variant: tt.variant}, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: visitorCookie, Value: tt.cookie})
+			}
+			w := httptest.NewRecorder()
+			service.HandleGetOriginalURL(w, req)
+
+			if tt.expectVisitorID != "" && sentVisitorID != tt.expectVisitorID {
+				t.Errorf("expected visitor ID %s, got %s", tt.expectVisitorID, sentVisitorID)
+			}
+			if tt.expectVisitorID == "" && sentVisitorID == existing {
+				t.Errorf("expected the cookie's visitor ID to be ignored")
+			}
+			if !isVisitorID(sentVisitorID) {
+				t.Errorf("expected a generated visitor ID, got %q", sentVisitorID)
+			}
+
+			var setCookie *http.Cookie
+			for _, cookie := range w.Result().Cookies() {
+				if cookie.Name == visitorCookie {
+					setCookie = cookie
+				}
+			}
+			if !tt.expectSetCookie {
+				if setCookie != nil {
+					t.Errorf("expected no visitor cookie, got %s", setCookie.Value)
+				}
+				return
+			}
+			if setCookie == nil {
+				t.Fatal("expected a visitor cookie")
+			}
+			if setCookie.Value != signVisitorID(secret, sentVisitorID) {
+				t.Errorf("expected cookie %s to be the signed visitor ID sent, %s", setCookie.Value, sentVisitorID)
+			}
+			if !setCookie.HttpOnly || setCookie.MaxAge <= 0 {
+				t.Errorf("expected a persistent HttpOnly cookie, got %+v", setCookie)
+			}
+		})
+	}
+}
+
+func TestVisitorID_WithoutCookie(t *testing.T) {
+	newRequest := func(userAgent string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+		req.Header.Set("User-Agent", userAgent)
+		return req
+	}
+
+	service := &GatewayServer{VisitorSecret: []byte("test-secret")}
+
+	// clients that drop the cookie keep their ID, and so their variant
+	first, isNew := service.visitorID(newRequest("Mozilla/5.0 (X11; Linux x86_64)"), "198.51.100.7")
+	require.True(t, isNew)
+	require.True(t, isVisitorID(first))
+	again, _ := service.visitorID(newRequest("Mozilla/5.0 (X11; Linux x86_64)"), "198.51.100.7")
+	require.Equal(t, first, again)
+
+	other, _ := service.visitorID(newRequest("Mozilla/5.0 (X11; Linux x86_64)"), "198.51.100.8")
+	require.NotEqual(t, first, other)
+	other, _ = service.visitorID(newRequest("curl/8.5.0"), "198.51.100.7")
+	require.NotEqual(t, first, other)
+}
+
+func TestHandlePreview(t *testing.T) {
+	created := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC).Unix()
+
+	tests := []struct {
+		name         string
+		mockResponse *pb.GetURLPreviewResponse
+		mockError    error
+		expectedCode int
+		expectBody   []string
+		rejectBody   []string
+	}{
+		{
+			name: "live link",
+			mockResponse: &pb.GetURLPreviewResponse{
+				ShortCode:   "abc123",
+				OriginalUrl: "https://google.com/",
+				CreatedAt:   created,
+				ClickCount:  42,
+			},
+			expectedCode: http.StatusOK,
+			expectBody:   []string{"https://google.com/", "Created 14 Mar 2025", "42 clicks", "Continue to the destination"},
+		},
+		{
+			name: "quarantined link",
+			mockResponse: &pb.GetURLPreviewResponse{
+				ShortCode:   "abc123",
+				CreatedAt:   created,
+				Quarantined: true,
+			},
+			expectedCode: http.StatusOK,
+			expectBody:   []string{"has been disabled"},
+			rejectBody:   []string{"Continue"},
+		},
+		{
+			name: "link not active yet",
+			mockResponse: &pb.GetURLPreviewResponse{
+				ShortCode:  "abc123",
+				CreatedAt:  created,
+				ActiveFrom: created + 86400,
+				Inactive:   true,
+			},
+			expectedCode: http.StatusOK,
+			expectBody:   []string{"isn't active yet", "Goes live 15 Mar 2025"},
+			rejectBody:   []string{"Continue"},
+		},
+		{
+			name: "one-time link",
+			mockResponse: &pb.GetURLPreviewResponse{
+				ShortCode: "abc123",
+				CreatedAt: created,
+				MaxClicks: 1,
+			},
+			expectedCode: http.StatusOK,
+			expectBody:   []string{"only shown to whoever follows the link", "0 clicks of 1", `href="/abc123"`},
+			rejectBody:   []string{"Continue"},
+		},
+		{
+			name: "used up link",
+			mockResponse: &pb.GetURLPreviewResponse{
+				ShortCode:  "abc123",
+				CreatedAt:  created,
+				ClickCount: 1,
+				MaxClicks:  1,
+				Exhausted:  true,
+			},
+			expectedCode: http.StatusGone,
+			expectBody:   []string{"has been used up"},
+			rejectBody:   []string{"Follow the link"},
+		},
+		{
+			name: "expired link",
+			mockResponse: &pb.GetURLPreviewResponse{
+				ShortCode:   "abc123",
+				OriginalUrl: "https://google.com/",
+				CreatedAt:   created,
+				ExpiresAt:   created + 3600,
+				Expired:     true,
+			},
+			expectedCode: http.StatusGone,
+			expectBody:   []string{"Expired 14 Mar 2025 01:00 UTC"},
+			rejectBody:   []string{"Continue"},
+		},
+		{
+			name:         "unknown link",
+			mockError:    status.Error(codes.NotFound, "URL not found"),
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient := new(MockURLServiceClient)
+			server := &GatewayServer{
+				GrpcClient: mockClient,
+				Logger:     zap.NewNop(),
+				Metrics:    &metrics.NoopMetrics{},
+			}
+			mockClient.On("GetURLPreview", mock.Anything, &pb.GetURLPreviewRequest{ShortCode: "abc123"}, mock.Anything).
+				Return(tc.mockResponse, tc.mockError)
+
+			// through the router, the preview route has to win over the redirect
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/abc123+", nil)
+			server.NewRouter().ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("expected status %d, got %d", tc.expectedCode, w.Code)
+			}
+			for _, want := range tc.expectBody {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Errorf("expected body to contain %q, got %q", want, w.Body.String())
+				}
+			}
+			for _, unwanted := range tc.rejectBody {
+				if strings.Contains(w.Body.String(), unwanted) {
+					t.Errorf("expected body not to contain %q, got %q", unwanted, w.Body.String())
+				}
+			}
+			mockClient.AssertNotCalled(t, "GetOriginalURL", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestRedirectTypes(t *testing.T) {
+	soon := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name         string
+		method       string
+		redirectType int32
+		expiresAt    int64
+		changesAt    int64
+		perVisitor   bool
+		clickLimited bool
+		expectedCode int
+		// compared as a prefix, the expiry cap depends on the clock
+		expectedCacheControl string
+	}{
+		{name: "temporary by default", method: http.MethodGet, expectedCode: http.StatusFound, expectedCacheControl: "no-store"},
+		{name: "moved permanently", method: http.MethodGet, redirectType: 301, expectedCode: http.StatusMovedPermanently, expectedCacheControl: "public, max-age=86400"},
+		{name: "permanent capped at expiry", method: http.MethodGet, redirectType: 308, expiresAt: soon, expectedCode: http.StatusPermanentRedirect, expectedCacheControl: "public, max-age=35"},
+		{name: "permanent capped at schedule change", method: http.MethodGet, redirectType: 301, changesAt: soon, expectedCode: http.StatusMovedPermanently, expectedCacheControl: "public, max-age=35"},
+		{name: "schedule change before expiry", method: http.MethodGet, redirectType: 301, expiresAt: time.Now().Add(48 * time.Hour).Unix(), changesAt: soon, expectedCode: http.StatusMovedPermanently, expectedCacheControl: "public, max-age=35"},
+		{name: "geo-routed permanent kept by the browser only", method: http.MethodGet, redirectType: 301, perVisitor: true, expectedCode: http.StatusMovedPermanently, expectedCacheControl: "private, max-age=86400"},
+		{name: "click-limited permanent not cached", method: http.MethodGet, redirectType: 301, clickLimited: true, expectedCode: http.StatusMovedPermanently, expectedCacheControl: "no-store"},
+		{name: "temporary keeps POST", method: http.MethodPost, redirectType: 307, expectedCode: http.StatusTemporaryRedirect, expectedCacheControl: "no-store"},
+		{name: "302 refuses POST", method: http.MethodPost, redirectType: 302, expectedCode: http.StatusMethodNotAllowed},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient := new(MockURLServiceClient)
+			server := &GatewayServer{
+				GrpcClient:              mockClient,
+				Logger:                  zap.NewNop(),
+				Metrics:                 &metrics.NoopMetrics{},
+				PermanentRedirectMaxAge: 24 * time.Hour,
+			}
+			mockClient.On("GetOriginalURL", mock.Anything, mock.Anything, mock.Anything).Return(&pb.GetURLResponse{
+				OriginalUrl:  "https://google.com/",
+				Found:        true,
+				RedirectType: tc.redirectType,
+				ExpiresAt:    tc.expiresAt,
+				ChangesAt:    tc.changesAt,
+				PerVisitor:   tc.perVisitor,
+				ClickLimited: tc.clickLimited,
+			}, nil)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.method, "/abc123", strings.NewReader(`{"a":1}`))
+			server.NewRouter().ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("expected status %d, got %d", tc.expectedCode, w.Code)
+			}
+			if got := w.Header().Get("Cache-Control"); !strings.HasPrefix(got, tc.expectedCacheControl) {
+				t.Errorf("expected Cache-Control %q, got %q", tc.expectedCacheControl, got)
+			}
+		})
+	}
+}
+
+func TestPassthroughRedirects(t *testing.T) {
+	tests := []struct {
+		name             string
+		path             string
+		response         *pb.GetURLResponse
+		expectedWithPath bool
+		expectedCode     int
+		expectedLocation string
+	}{
+		{
+			name:             "query dropped by default",
+			path:             "/abc123?utm_source=x",
+			response:         &pb.GetURLResponse{OriginalUrl: "https://example.com/?a=1", Found: true},
+			expectedCode:     http.StatusFound,
+			expectedLocation: "https://example.com/?a=1",
+		},
+		{
+			name: "query forwarded",
+			path: "/abc123?a=2&utm_source=x",
+			response: &pb.GetURLResponse{
+				OriginalUrl:      "https://example.com/?a=1",
+				Found:            true,
+				QueryPassthrough: passthrough.QueryPreferDestination,
+			},
+			expectedCode:     http.StatusFound,
+			expectedLocation: "https://example.com/?a=1&utm_source=x",
+		},
+		{
+			name: "wildcard link",
+			path: "/abc123/guides/getting%20started?page=2",
+			response: &pb.GetURLResponse{
+				OriginalUrl:      "https://example.com/docs/",
+				Found:            true,
+				QueryPassthrough: passthrough.QueryPreferRequest,
+				PathPassthrough:  true,
+			},
+			expectedWithPath: true,
+			expectedCode:     http.StatusFound,
+			expectedLocation: "https://example.com/docs/guides/getting%20started?page=2",
+		},
+		{
+			name:             "path on a link without passthrough",
+			path:             "/abc123/guides",
+			response:         &pb.GetURLResponse{Found: false, Error: "URL not found"},
+			expectedWithPath: true,
+			expectedCode:     http.StatusNotFound,
+		},
+		{
+			// the router cleans the decoded path before any link is looked up
+			name:             "escaped dot segments",
+			path:             "/abc123/%2e%2e/admin",
+			expectedCode:     http.StatusMovedPermanently,
+			expectedLocation: "/admin",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient := new(MockURLServiceClient)
+			server := &GatewayServer{
+				GrpcClient: mockClient,
+				Logger:     zap.NewNop(),
+				Metrics:    &metrics.NoopMetrics{},
+			}
+			if tc.response != nil {
+				// visitor IDs are random, the rest of the request is checked
+				mockClient.On("GetOriginalURL", mock.Anything, mock.MatchedBy(func(req *pb.GetURLRequest) bool {
+					return req.ShortCode == "abc123" && req.WithPath == tc.expectedWithPath && req.ClientIp == "192.0.2.1"
+				}), mock.Anything).
+					Return(tc.response, nil)
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			server.NewRouter().ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("expected status %d, got %d", tc.expectedCode, w.Code)
+			}
+			if got := w.Header().Get("Location"); got != tc.expectedLocation {
+				t.Errorf("expected Location %q, got %q", tc.expectedLocation, got)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
 }
@@ -0,0 +1,463 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	pb "github.com/sammyqtran/url-shortener/proto"
+	"go.uber.org/zap"
+)
+
+// linkResponse is the JSON representation of a link in the /api/v1 API
+type linkResponse struct {
+	ShortCode        string            `json:"short_code"`
+	ShortURL         string            `json:"short_url"`
+	OriginalURL      string            `json:"original_url"`
+	ClickCount       int64             `json:"click_count"`
+	CreatedAt        *time.Time        `json:"created_at,omitempty"`
+	UpdatedAt        *time.Time        `json:"updated_at,omitempty"`
+	ExpiresAt        *time.Time        `json:"expires_at,omitempty"`
+	Quarantined      bool              `json:"quarantined,omitempty"`
+	Interstitial     bool              `json:"interstitial,omitempty"`
+	RedirectType     int32             `json:"redirect_type,omitempty"`
+	QueryPassthrough string            `json:"query_passthrough,omitempty"`
+	PathPassthrough  bool              `json:"path_passthrough,omitempty"`
+	CampaignID       int64             `json:"campaign_id,omitempty"`
+	GeoRoutes        map[string]string `json:"geo_routes,omitempty"`
+	DeviceRoutes     map[string]string `json:"device_routes,omitempty"`
+	Variants         []linkVariant     `json:"variants,omitempty"`
+	ActiveFrom       *time.Time        `json:"active_from,omitempty"`
+	Schedule         []scheduleEntry   `json:"schedule,omitempty"`
+	MaxClicks        int64             `json:"max_clicks,omitempty"`
+	// Created is only set on create responses
+	Created *bool `json:"created,omitempty"`
+}
+
+// linkVariant is one destination of an A/B split
+type linkVariant struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Weight int32  `json:"weight"`
+}
+
+// scheduleEntry is a destination for a time window, a missing side is open
+type scheduleEntry struct {
+	Start *time.Time `json:"start,omitempty"`
+	End   *time.Time `json:"end,omitempty"`
+	URL   string     `json:"url"`
+}
+
+type linkStatsResponse struct {
+	ShortCode  string     `json:"short_code"`
+	ClickCount int64      `json:"click_count"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+type createLinkRequest struct {
+	URL              string            `json:"url"`
+	CustomAlias      string            `json:"custom_alias"`
+	ExpiresAt        *time.Time        `json:"expires_at"`
+	TTLSeconds       int64             `json:"ttl_seconds"`
+	Dedupe           bool              `json:"dedupe"`
+	Interstitial     bool              `json:"interstitial"`
+	RedirectType     int32             `json:"redirect_type"`
+	QueryPassthrough string            `json:"query_passthrough"`
+	PathPassthrough  bool              `json:"path_passthrough"`
+	CampaignID       int64             `json:"campaign_id"`
+	GeoRoutes        map[string]string `json:"geo_routes"`
+	DeviceRoutes     map[string]string `json:"device_routes"`
+	Variants         []linkVariant     `json:"variants"`
+	ActiveFrom       *time.Time        `json:"active_from"`
+	Schedule         []scheduleEntry   `json:"schedule"`
+	MaxClicks        int64             `json:"max_clicks"`
+}
+
+type updateLinkRequest struct {
+	URL               string            `json:"url"`
+	ExpiresAt         *time.Time        `json:"expires_at"`
+	TTLSeconds        int64             `json:"ttl_seconds"`
+	ClearExpiry       bool              `json:"clear_expiry"`
+	Interstitial      *bool             `json:"interstitial"`
+	RedirectType      int32             `json:"redirect_type"`
+	QueryPassthrough  string            `json:"query_passthrough"`
+	PathPassthrough   *bool             `json:"path_passthrough"`
+	CampaignID        int64             `json:"campaign_id"`
+	ClearCampaign     bool              `json:"clear_campaign"`
+	GeoRoutes         map[string]string `json:"geo_routes"`
+	ClearGeoRoutes    bool              `json:"clear_geo_routes"`
+	DeviceRoutes      map[string]string `json:"device_routes"`
+	ClearDeviceRoutes bool              `json:"clear_device_routes"`
+	Variants          []linkVariant     `json:"variants"`
+	ClearVariants     bool              `json:"clear_variants"`
+	ActiveFrom        *time.Time        `json:"active_from"`
+	ClearActiveFrom   bool              `json:"clear_active_from"`
+	Schedule          []scheduleEntry   `json:"schedule"`
+	ClearSchedule     bool              `json:"clear_schedule"`
+	MaxClicks         int64             `json:"max_clicks"`
+	ClearMaxClicks    bool              `json:"clear_max_clicks"`
+}
+
+func (s *GatewayServer) HandleListLinks(w http.ResponseWriter, r *http.Request) {
+	const endpoint = "/api/v1/links"
+	defer s.trackRequest(r.Method, endpoint)()
+
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		s.respondWithHTTPError(w, r, endpoint, http.StatusBadRequest, "limit must be an integer")
+		return
+	}
+	offset, err := queryInt(r, "offset")
+	if err != nil {
+		s.respondWithHTTPError(w, r, endpoint, http.StatusBadRequest, "offset must be an integer")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	s.Metrics.IncGRPCCall("gateway", "ListURLs")
+	grpcTimer := time.Now()
+	response, err := s.GrpcClient.ListURLs(ctx, &pb.ListURLsRequest{
+		UserId: s.getUserID(r),
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	s.Metrics.ObserveGRPCLatency("gateway", "ListURLs", time.Since(grpcTimer).Seconds())
+	if err != nil {
+		s.respondWithGRPCError(w, r, endpoint, "ListURLs", err)
+		return
+	}
+
+	links := make([]linkResponse, 0, len(response.Urls))
+	for _, details := range response.Urls {
+		links = append(links, toLinkResponse(details))
+	}
+	respondWithJSON(w, http.StatusOK, map[string][]linkResponse{"links": links})
+}
+
+func (s *GatewayServer) HandleCreateLink(w http.ResponseWriter, r *http.Request) {
+	const endpoint = "/api/v1/links"
+	defer s.trackRequest(r.Method, endpoint)()
+	defer r.Body.Close()
+
+	var req createLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.Logger.Warn("Failed to decode JSON request", zap.Error(err))
+		s.respondWithHTTPError(w, r, endpoint, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if req.URL == "" {
+		s.respondWithHTTPError(w, r, endpoint, http.StatusBadRequest, "url is required")
+		return
+	}
+
+	request := toCreateURLRequest(req, s.getUserID(r))
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	s.Metrics.IncGRPCCall("gateway", "CreateShortURL")
+	grpcTimer := time.Now()
+	response, err := s.GrpcClient.CreateShortURL(ctx, request)
+	s.Metrics.ObserveGRPCLatency("gateway", "CreateShortURL", time.Since(grpcTimer).Seconds())
+	if err != nil {
+		s.respondWithGRPCError(w, r, endpoint, "CreateShortURL", err)
+		return
+	}
+
+	created := !response.Existing
+	link := linkResponse{
+		ShortCode:        response.ShortCode,
+		ShortURL:         response.ShortUrl,
+		OriginalURL:      response.OriginalUrl,
+		ExpiresAt:        unixTimePtr(response.ExpiresAt),
+		Interstitial:     response.Interstitial,
+		RedirectType:     response.RedirectType,
+		QueryPassthrough: response.QueryPassthrough,
+		PathPassthrough:  response.PathPassthrough,
+		CampaignID:       response.CampaignId,
+		GeoRoutes:        response.GeoRoutes,
+		DeviceRoutes:     response.DeviceRoutes,
+		Variants:         fromVariantProtos(response.Variants),
+		ActiveFrom:       unixTimePtr(response.ActiveFrom),
+		Schedule:         fromScheduleProtos(response.Schedule),
+		MaxClicks:        response.MaxClicks,
+		Created:          &created,
+	}
+	w.Header().Set("Location", "/api/v1/links/"+response.ShortCode)
+
+	// a dedupe hit points at the existing link instead of creating one
+	if !created {
+		respondWithJSON(w, http.StatusOK, link)
+		return
+	}
+
+	s.publishURLCreated(r, response.ShortCode, req.URL, response.CampaignId)
+	respondWithJSON(w, http.StatusCreated, link)
+}
+
+// toCreateURLRequest converts a create body, shared by /create and /api/v1/links
+func toCreateURLRequest(req createLinkRequest, userID string) *pb.CreateURLRequest {
+	request := &pb.CreateURLRequest{
+		OriginalUrl:      req.URL,
+		UserId:           userID,
+		CustomAlias:      req.CustomAlias,
+		TtlSeconds:       req.TTLSeconds,
+		Dedupe:           req.Dedupe,
+		Interstitial:     req.Interstitial,
+		RedirectType:     req.RedirectType,
+		QueryPassthrough: req.QueryPassthrough,
+		PathPassthrough:  req.PathPassthrough,
+		CampaignId:       req.CampaignID,
+		GeoRoutes:        req.GeoRoutes,
+		DeviceRoutes:     req.DeviceRoutes,
+		Variants:         toVariantProtos(req.Variants),
+		ActiveFrom:       timeUnix(req.ActiveFrom),
+		Schedule:         toScheduleProtos(req.Schedule),
+		MaxClicks:        req.MaxClicks,
+	}
+	if req.ExpiresAt != nil {
+		request.ExpiresAt = req.ExpiresAt.Unix()
+	}
+	return request
+}
+
+func (s *GatewayServer) HandleGetLink(w http.ResponseWriter, r *http.Request) {
+	const endpoint = "/api/v1/links/{code}"
+	defer s.trackRequest(r.Method, endpoint)()
+
+	details, ok := s.fetchLinkDetails(w, r, endpoint)
+	if !ok {
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toLinkResponse(details))
+}
+
+func (s *GatewayServer) HandleUpdateLink(w http.ResponseWriter, r *http.Request) {
+	const endpoint = "/api/v1/links/{code}"
+	defer s.trackRequest(r.Method, endpoint)()
+	defer r.Body.Close()
+
+	var req updateLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.Logger.Warn("Failed to decode JSON request", zap.Error(err))
+		s.respondWithHTTPError(w, r, endpoint, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	request := &pb.UpdateURLRequest{
+		ShortCode:         mux.Vars(r)["code"],
+		UserId:            s.getUserID(r),
+		OriginalUrl:       req.URL,
+		TtlSeconds:        req.TTLSeconds,
+		ClearExpiry:       req.ClearExpiry,
+		Interstitial:      req.Interstitial,
+		RedirectType:      req.RedirectType,
+		QueryPassthrough:  req.QueryPassthrough,
+		PathPassthrough:   req.PathPassthrough,
+		CampaignId:        req.CampaignID,
+		ClearCampaign:     req.ClearCampaign,
+		GeoRoutes:         req.GeoRoutes,
+		ClearGeoRoutes:    req.ClearGeoRoutes,
+		DeviceRoutes:      req.DeviceRoutes,
+		ClearDeviceRoutes: req.ClearDeviceRoutes,
+		Variants:          toVariantProtos(req.Variants),
+		ClearVariants:     req.ClearVariants,
+		ActiveFrom:        timeUnix(req.ActiveFrom),
+		ClearActiveFrom:   req.ClearActiveFrom,
+		Schedule:          toScheduleProtos(req.Schedule),
+		ClearSchedule:     req.ClearSchedule,
+		MaxClicks:         req.MaxClicks,
+		ClearMaxClicks:    req.ClearMaxClicks,
+	}
+	if req.ExpiresAt != nil {
+		request.ExpiresAt = req.ExpiresAt.Unix()
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	s.Metrics.IncGRPCCall("gateway", "UpdateShortURL")
+	grpcTimer := time.Now()
+	response, err := s.GrpcClient.UpdateShortURL(ctx, request)
+	s.Metrics.ObserveGRPCLatency("gateway", "UpdateShortURL", time.Since(grpcTimer).Seconds())
+	if err != nil {
+		s.respondWithGRPCError(w, r, endpoint, "UpdateShortURL", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toLinkResponse(response.Url))
+}
+
+func (s *GatewayServer) HandleDeleteLink(w http.ResponseWriter, r *http.Request) {
+	const endpoint = "/api/v1/links/{code}"
+	defer s.trackRequest(r.Method, endpoint)()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	s.Metrics.IncGRPCCall("gateway", "DeleteShortURL")
+	grpcTimer := time.Now()
+	_, err := s.GrpcClient.DeleteShortURL(ctx, &pb.DeleteURLRequest{
+		ShortCode: mux.Vars(r)["code"],
+		UserId:    s.getUserID(r),
+	})
+	s.Metrics.ObserveGRPCLatency("gateway", "DeleteShortURL", time.Since(grpcTimer).Seconds())
+	if err != nil {
+		s.respondWithGRPCError(w, r, endpoint, "DeleteShortURL", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *GatewayServer) HandleGetLinkStats(w http.ResponseWriter, r *http.Request) {
+	const endpoint = "/api/v1/links/{code}/stats"
+	defer s.trackRequest(r.Method, endpoint)()
+
+	details, ok := s.fetchLinkDetails(w, r, endpoint)
+	if !ok {
+		return
+	}
+	respondWithJSON(w, http.StatusOK, linkStatsResponse{
+		ShortCode:  details.ShortCode,
+		ClickCount: details.ClickCount,
+		CreatedAt:  time.Unix(details.CreatedAt, 0).UTC(),
+		UpdatedAt:  time.Unix(details.UpdatedAt, 0).UTC(),
+		ExpiresAt:  unixTimePtr(details.ExpiresAt),
+	})
+}
+
+// fetchLinkDetails loads the link named in the path, writing the error response itself on failure.
+func (s *GatewayServer) fetchLinkDetails(w http.ResponseWriter, r *http.Request, endpoint string) (*pb.URLDetails, bool) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	s.Metrics.IncGRPCCall("gateway", "GetURLDetails")
+	grpcTimer := time.Now()
+	response, err := s.GrpcClient.GetURLDetails(ctx, &pb.GetURLDetailsRequest{
+		ShortCode: mux.Vars(r)["code"],
+		UserId:    s.getUserID(r),
+	})
+	s.Metrics.ObserveGRPCLatency("gateway", "GetURLDetails", time.Since(grpcTimer).Seconds())
+	if err != nil {
+		s.respondWithGRPCError(w, r, endpoint, "GetURLDetails", err)
+		return nil, false
+	}
+	return response.Url, true
+}
+
+// trackRequest records the request count and returns a func to observe its duration, use with defer.
+func (s *GatewayServer) trackRequest(method, endpoint string) func() {
+	start := time.Now()
+	return func() {
+		s.Metrics.IncHTTPRequest("gateway", method, endpoint)
+		s.Metrics.ObserveHTTPRequestDuration("gateway", method, endpoint, time.Since(start).Seconds())
+	}
+}
+
+func respondWithJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(body)
+}
+
+func toLinkResponse(details *pb.URLDetails) linkResponse {
+	return linkResponse{
+		ShortCode:        details.ShortCode,
+		ShortURL:         details.ShortUrl,
+		OriginalURL:      details.OriginalUrl,
+		ClickCount:       details.ClickCount,
+		CreatedAt:        unixTimePtr(details.CreatedAt),
+		UpdatedAt:        unixTimePtr(details.UpdatedAt),
+		ExpiresAt:        unixTimePtr(details.ExpiresAt),
+		Quarantined:      details.Quarantined,
+		Interstitial:     details.Interstitial,
+		RedirectType:     details.RedirectType,
+		QueryPassthrough: details.QueryPassthrough,
+		PathPassthrough:  details.PathPassthrough,
+		CampaignID:       details.CampaignId,
+		GeoRoutes:        details.GeoRoutes,
+		DeviceRoutes:     details.DeviceRoutes,
+		Variants:         fromVariantProtos(details.Variants),
+		ActiveFrom:       unixTimePtr(details.ActiveFrom),
+		Schedule:         fromScheduleProtos(details.Schedule),
+		MaxClicks:        details.MaxClicks,
+	}
+}
+
+func toVariantProtos(variants []linkVariant) []*pb.Variant {
+	if len(variants) == 0 {
+		return nil
+	}
+	protos := make([]*pb.Variant, len(variants))
+	for i, variant := range variants {
+		protos[i] = &pb.Variant{Name: variant.Name, Url: variant.URL, Weight: variant.Weight}
+	}
+	return protos
+}
+
+func fromVariantProtos(protos []*pb.Variant) []linkVariant {
+	if len(protos) == 0 {
+		return nil
+	}
+	variants := make([]linkVariant, len(protos))
+	for i, variant := range protos {
+		variants[i] = linkVariant{Name: variant.Name, URL: variant.Url, Weight: variant.Weight}
+	}
+	return variants
+}
+
+func toScheduleProtos(schedule []scheduleEntry) []*pb.ScheduleEntry {
+	if len(schedule) == 0 {
+		return nil
+	}
+	protos := make([]*pb.ScheduleEntry, len(schedule))
+	for i, entry := range schedule {
+		protos[i] = &pb.ScheduleEntry{Start: timeUnix(entry.Start), End: timeUnix(entry.End), Url: entry.URL}
+	}
+	return protos
+}
+
+func fromScheduleProtos(protos []*pb.ScheduleEntry) []scheduleEntry {
+	if len(protos) == 0 {
+		return nil
+	}
+	schedule := make([]scheduleEntry, len(protos))
+	for i, entry := range protos {
+		schedule[i] = scheduleEntry{Start: unixTimePtr(entry.Start), End: unixTimePtr(entry.End), URL: entry.Url}
+	}
+	return schedule
+}
+
+// timeUnix converts an optional time to unix seconds, 0 when it's unset
+func timeUnix(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+// unixTimePtr converts unix seconds to a UTC time, treating 0 as unset.
+func unixTimePtr(seconds int64) *time.Time {
+	if seconds == 0 {
+		return nil
+	}
+	t := time.Unix(seconds, 0).UTC()
+	return &t
+}
+
+// queryInt parses an optional integer query parameter, returning 0 when it's absent.
+func queryInt(r *http.Request, key string) (int, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
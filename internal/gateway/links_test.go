@@ -0,0 +1,569 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sammyqtran/url-shortener/internal/metrics"
+	pb "github.com/sammyqtran/url-shortener/proto"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+const openAPISpecPath = "../../docs/openapi.yaml"
+
+// openAPISpec is the small subset of an OpenAPI 3 document the contract tests need
+type openAPISpec struct {
+	Paths      map[string]map[string]interface{} `yaml:"paths"`
+	Components map[string]map[string]interface{} `yaml:"components"`
+}
+
+func loadOpenAPISpec(t *testing.T) *openAPISpec {
+	t.Helper()
+	data, err := os.ReadFile(openAPISpecPath)
+	require.NoError(t, err)
+
+	var spec openAPISpec
+	require.NoError(t, yaml.Unmarshal(data, &spec))
+	return &spec
+}
+
+// resolve follows a local "#/components/<kind>/<name>" reference
+func (spec *openAPISpec) resolve(node map[string]interface{}) map[string]interface{} {
+	ref, ok := node["$ref"].(string)
+	if !ok {
+		return node
+	}
+	parts := strings.Split(strings.TrimPrefix(ref, "#/components/"), "/")
+	resolved, _ := spec.Components[parts[0]][parts[1]].(map[string]interface{})
+	return spec.resolve(resolved)
+}
+
+// validateResponse checks a recorded response is documented for the operation and matches its schema
+func (spec *openAPISpec) validateResponse(pathTemplate, method string, w *httptest.ResponseRecorder) error {
+	operation, ok := spec.Paths[pathTemplate][strings.ToLower(method)].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s %s is not documented", method, pathTemplate)
+	}
+	responses, _ := operation["responses"].(map[string]interface{})
+	response, ok := responses[fmt.Sprintf("%d", w.Code)].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("status %d is not documented for %s %s", w.Code, method, pathTemplate)
+	}
+	response = spec.resolve(response)
+
+	content, ok := response["content"].(map[string]interface{})
+	if !ok {
+		if w.Body.Len() != 0 {
+			return fmt.Errorf("expected empty body, got %q", w.Body.String())
+		}
+		return nil
+	}
+
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/json" {
+		return fmt.Errorf("expected application/json, got %q", contentType)
+	}
+	media, _ := content["application/json"].(map[string]interface{})
+	schema, _ := media["schema"].(map[string]interface{})
+
+	var body interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+	return spec.validateSchema("body", body, schema)
+}
+
+func (spec *openAPISpec) validateSchema(path string, value interface{}, schema map[string]interface{}) error {
+	schema = spec.resolve(schema)
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected object, got %T", path, value)
+		}
+		required, _ := schema["required"].([]interface{})
+		for _, name := range required {
+			if _, ok := object[name.(string)]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for name, propValue := range object {
+			propSchema, ok := properties[name].(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s: undocumented property %q", path, name)
+			}
+			if err := spec.validateSchema(path+"."+name, propValue, propSchema); err != nil {
+				return err
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array, got %T", path, value)
+		}
+		itemSchema, _ := schema["items"].(map[string]interface{})
+		for i, item := range items {
+			if err := spec.validateSchema(fmt.Sprintf("%s[%d]", path, i), item, itemSchema); err != nil {
+				return err
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected string, got %T", path, value)
+		}
+		if enum, ok := schema["enum"].([]interface{}); ok {
+			found := false
+			for _, allowed := range enum {
+				found = found || allowed == str
+			}
+			if !found {
+				return fmt.Errorf("%s: %q is not one of %v", path, str, enum)
+			}
+		}
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				return fmt.Errorf("%s: invalid date-time %q", path, str)
+			}
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != float64(int64(number)) {
+			return fmt.Errorf("%s: expected integer, got %v", path, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean, got %T", path, value)
+		}
+	}
+	return nil
+}
+
+func TestOpenAPIRoutesRegistered(t *testing.T) {
+	spec := loadOpenAPISpec(t)
+	server := &GatewayServer{Logger: zap.NewNop(), Metrics: &metrics.NoopMetrics{}}
+	router := server.NewRouter()
+
+	for pathTemplate, item := range spec.Paths {
+		for method := range item {
+			if method == "parameters" {
+				continue
+			}
+			path := strings.ReplaceAll(pathTemplate, "{code}", "abc123")
+			req := httptest.NewRequest(strings.ToUpper(method), path, nil)
+
+			var match mux.RouteMatch
+			require.True(t, router.Match(req, &match), "no route for %s %s", method, pathTemplate)
+			template, err := match.Route.GetPathTemplate()
+			require.NoError(t, err)
+			require.Equal(t, pathTemplate, template, "%s %s matched the wrong route", method, pathTemplate)
+		}
+	}
+}
+
+func TestLinksAPI(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	details := &pb.URLDetails{
+		Id:          1,
+		ShortCode:   "abc123",
+		ShortUrl:    "http://localhost:8080/abc123",
+		OriginalUrl: "https://example.com",
+		UserId:      "user123",
+		CreatedAt:   created,
+		UpdatedAt:   created,
+		ClickCount:  5,
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		pathTemplate string
+		body         string
+		anonymous    bool
+		mockSetup    func(m *MockURLServiceClient)
+		expectedCode int
+		checkBody    func(t *testing.T, w *httptest.ResponseRecorder)
+	}{
+		{
+			name:         "list links",
+			method:       http.MethodGet,
+			path:         "/api/v1/links?limit=10&offset=5",
+			pathTemplate: "/api/v1/links",
+			mockSetup: func(m *MockURLServiceClient) {
+				m.On("ListURLs", mock.Anything, mock.MatchedBy(func(req *pb.ListURLsRequest) bool {
+					return req.UserId == "user123" && req.Limit == 10 && req.Offset == 5
+				}), mock.Anything).Return(&pb.ListURLsResponse{Urls: []*pb.URLDetails{details}}, nil)
+			},
+			expectedCode: http.StatusOK,
+			checkBody: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.Contains(t, w.Body.String(), `"short_code":"abc123"`)
+			},
+		},
+		{
+			name:         "list links empty",
+			method:       http.MethodGet,
+			path:         "/api/v1/links",
+			pathTemplate: "/api/v1/links",
+			mockSetup: func(m *MockURLServiceClient) {
+				m.On("ListURLs", mock.Anything, mock.Anything, mock.Anything).Return(&pb.ListURLsResponse{}, nil)
+			},
+			expectedCode: http.StatusOK,
+			checkBody: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.JSONEq(t, `{"links":[]}`, w.Body.String())
+			},
+		},
+		{
+			name:         "list links bad limit",
+			method:       http.MethodGet,
+			path:         "/api/v1/links?limit=ten",
+			pathTemplate: "/api/v1/links",
+			mockSetup:    func(m *MockURLServiceClient) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "create link",
+			method:       http.MethodPost,
+			path:         "/api/v1/links",
+			pathTemplate: "/api/v1/links",
+			body:         `{"url": "HTTPS://Example.com:443", "custom_alias": "spring-sale"}`,
+			mockSetup: func(m *MockURLServiceClient) {
+				m.On("CreateShortURL", mock.Anything, mock.MatchedBy(func(req *pb.CreateURLRequest) bool {
+					return req.CustomAlias == "spring-sale"
+				}), mock.Anything).Return(&pb.CreateURLResponse{
+					ShortCode:   "spring-sale",
+					ShortUrl:    "http://localhost:8080/spring-sale",
+					OriginalUrl: "https://example.com/",
+					Success:     true,
+				}, nil)
+			},
+			expectedCode: http.StatusCreated,
+			checkBody: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.Equal(t, "/api/v1/links/spring-sale", w.Header().Get("Location"))
+				// the stored, canonical destination rather than the one sent
+				require.Contains(t, w.Body.String(), `"original_url":"https://example.com/"`)
+			},
+		},
+		{
+			name:         "create link with variants",
+			method:       http.MethodPost,
+			path:         "/api/v1/links",
+			pathTemplate: "/api/v1/links",
+			body:         `{"url": "https://example.com", "variants": [{"name": "a", "url": "https://example.com/a", "weight": 90}, {"name": "b", "url": "https://example.com/b", "weight": 10}]}`,
+			mockSetup: func(m *MockURLServiceClient) {
+				m.On("CreateShortURL", mock.Anything, mock.MatchedBy(func(req *pb.CreateURLRequest) bool {
+					return len(req.Variants) == 2 && req.Variants[0].Weight == 90 && req.Variants[1].Url == "https://example.com/b"
+				}), mock.Anything).Return(&pb.CreateURLResponse{
+					ShortCode: "abc123",
+					ShortUrl:  "http://localhost:8080/abc123",
+					Success:   true,
+					Variants: []*pb.Variant{
+						{Name: "a", Url: "https://example.com/a", Weight: 90},
+						{Name: "b", Url: "https://example.com/b", Weight: 10},
+					},
+				}, nil)
+			},
+			expectedCode: http.StatusCreated,
+			checkBody: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.Contains(t, w.Body.String(), `"variants":[{"name":"a","url":"https://example.com/a","weight":90}`)
+			},
+		},
+		{
+			name:         "create link with a schedule",
+			method:       http.MethodPost,
+			path:         "/api/v1/links",
+			pathTemplate: "/api/v1/links",
+			body:         `{"url": "https://example.com/recording", "active_from": "2026-05-01T00:00:00Z", "schedule": [{"start": "2026-06-01T09:00:00Z", "end": "2026-06-01T17:00:00Z", "url": "https://example.com/live"}]}`,
+			mockSetup: func(m *MockURLServiceClient) {
+				start := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC).Unix()
+				m.On("CreateShortURL", mock.Anything, mock.MatchedBy(func(req *pb.CreateURLRequest) bool {
+					return req.ActiveFrom == time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC).Unix() &&
+						len(req.Schedule) == 1 && req.Schedule[0].Start == start && req.Schedule[0].End == start+8*3600
+				}), mock.Anything).Return(&pb.CreateURLResponse{
+					ShortCode:  "abc123",
+					ShortUrl:   "http://localhost:8080/abc123",
+					Success:    true,
+					ActiveFrom: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC).Unix(),
+					Schedule:   []*pb.ScheduleEntry{{Start: start, End: start + 8*3600, Url: "https://example.com/live"}},
+				}, nil)
+			},
+			expectedCode: http.StatusCreated,
+			checkBody: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.Contains(t, w.Body.String(), `"active_from":"2026-05-01T00:00:00Z"`)
+				require.Contains(t, w.Body.String(), `"schedule":[{"start":"2026-06-01T09:00:00Z","end":"2026-06-01T17:00:00Z","url":"https://example.com/live"}]`)
+			},
+		},
+		{
+			name:         "create one-time link",
+			method:       http.MethodPost,
+			path:         "/api/v1/links",
+			pathTemplate: "/api/v1/links",
+			body:         `{"url": "https://example.com/onboarding?token=abc", "max_clicks": 1}`,
+			mockSetup: func(m *MockURLServiceClient) {
+				m.On("CreateShortURL", mock.Anything, mock.MatchedBy(func(req *pb.CreateURLRequest) bool {
+					return req.MaxClicks == 1
+				}), mock.Anything).Return(&pb.CreateURLResponse{
+					ShortCode: "abc123",
+					ShortUrl:  "http://localhost:8080/abc123",
+					Success:   true,
+					MaxClicks: 1,
+				}, nil)
+			},
+			expectedCode: http.StatusCreated,
+			checkBody: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.Contains(t, w.Body.String(), `"max_clicks":1`)
+			},
+		},
+		{
+			name:         "create link dedupe hit",
+			method:       http.MethodPost,
+			path:         "/api/v1/links",
+			pathTemplate: "/api/v1/links",
+			body:         `{"url": "https://example.com", "dedupe": true}`,
+			mockSetup: func(m *MockURLServiceClient) {
+				m.On("CreateShortURL", mock.Anything, mock.MatchedBy(func(req *pb.CreateURLRequest) bool {
+					return req.Dedupe
+				}), mock.Anything).Return(&pb.CreateURLResponse{
+					ShortCode:   "abc123",
+					ShortUrl:    "http://localhost:8080/abc123",
+					OriginalUrl: "https://example.com/",
+					Success:     true,
+					Existing:    true,
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+			checkBody: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.Equal(t, "/api/v1/links/abc123", w.Header().Get("Location"))
+				require.Contains(t, w.Body.String(), `"created":false`)
+				require.Contains(t, w.Body.String(), `"original_url":"https://example.com/"`)
+			},
+		},
+		{
+			name:         "create link to unsafe destination",
+			method:       http.MethodPost,
+			path:         "/api/v1/links",
+			pathTemplate: "/api/v1/links",
+			body:         `{"url": "https://evil.example/login"}`,
+			mockSetup: func(m *MockURLServiceClient) {
+				m.On("CreateShortURL", mock.Anything, mock.Anything, mock.Anything).Return(nil, threatMatchError(t))
+			},
+			expectedCode: http.StatusForbidden,
+			checkBody: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.Contains(t, w.Body.String(), `"reason":"threat_match"`)
+			},
+		},
+		{
+			name:         "create link without api key",
+			method:       http.MethodPost,
+			path:         "/api/v1/links",
+			pathTemplate: "/api/v1/links",
+			body:         `{"url": "https://example.com"}`,
+			anonymous:    true,
+			mockSetup:    func(m *MockURLServiceClient) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "get link without api key",
+			method:       http.MethodGet,
+			path:         "/api/v1/links/abc123",
+			pathTemplate: "/api/v1/links/{code}",
+			anonymous:    true,
+			mockSetup:    func(m *MockURLServiceClient) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "create link missing url",
+			method:       http.MethodPost,
+			path:         "/api/v1/links",
+			pathTemplate: "/api/v1/links",
+			body:         `{}`,
+			mockSetup:    func(m *MockURLServiceClient) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "create link alias taken",
+			method:       http.MethodPost,
+			path:         "/api/v1/links",
+			pathTemplate: "/api/v1/links",
+			body:         `{"url": "https://example.com", "custom_alias": "spring-sale"}`,
+			mockSetup: func(m *MockURLServiceClient) {
+				m.On("CreateShortURL", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.AlreadyExists, "alias taken"))
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "get link",
+			method:       http.MethodGet,
+			path:         "/api/v1/links/abc123",
+			pathTemplate: "/api/v1/links/{code}",
+			mockSetup: func(m *MockURLServiceClient) {
+				m.On("GetURLDetails", mock.Anything, mock.MatchedBy(func(req *pb.GetURLDetailsRequest) bool {
+					return req.ShortCode == "abc123"
+				}), mock.Anything).Return(&pb.GetURLDetailsResponse{Url: details}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "get quarantined link",
+			method:       http.MethodGet,
+			path:         "/api/v1/links/abc123",
+			pathTemplate: "/api/v1/links/{code}",
+			mockSetup: func(m *MockURLServiceClient) {
+				quarantined := proto.Clone(details).(*pb.URLDetails)
+				quarantined.Quarantined = true
+				m.On("GetURLDetails", mock.Anything, mock.Anything, mock.Anything).Return(&pb.GetURLDetailsResponse{Url: quarantined}, nil)
+			},
+			expectedCode: http.StatusOK,
+			checkBody: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.Contains(t, w.Body.String(), `"quarantined":true`)
+			},
+		},
+		{
+			name:         "get link not found",
+			method:       http.MethodGet,
+			path:         "/api/v1/links/missing",
+			pathTemplate: "/api/v1/links/{code}",
+			mockSetup: func(m *MockURLServiceClient) {
+				m.On("GetURLDetails", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.NotFound, "URL not found"))
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "get link of another user",
+			method:       http.MethodGet,
+			path:         "/api/v1/links/abc123",
+			pathTemplate: "/api/v1/links/{code}",
+			mockSetup: func(m *MockURLServiceClient) {
+				m.On("GetURLDetails", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.PermissionDenied, "URL belongs to another user"))
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "update link",
+			method:       http.MethodPatch,
+			path:         "/api/v1/links/abc123",
+			pathTemplate: "/api/v1/links/{code}",
+			body:         `{"url": "https://example.org", "ttl_seconds": 60}`,
+			mockSetup: func(m *MockURLServiceClient) {
+				m.On("UpdateShortURL", mock.Anything, mock.MatchedBy(func(req *pb.UpdateURLRequest) bool {
+					return req.ShortCode == "abc123" && req.OriginalUrl == "https://example.org" && req.TtlSeconds == 60
+				}), mock.Anything).Return(&pb.UpdateURLResponse{Url: &pb.URLDetails{
+					ShortCode:   "abc123",
+					ShortUrl:    "http://localhost:8080/abc123",
+					OriginalUrl: "https://example.org",
+					CreatedAt:   created,
+					UpdatedAt:   created,
+					ExpiresAt:   created + 60,
+				}}, nil)
+			},
+			expectedCode: http.StatusOK,
+			checkBody: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.Contains(t, w.Body.String(), `"expires_at":"2025-01-01T00:01:00Z"`)
+			},
+		},
+		{
+			name:         "update link invalid json",
+			method:       http.MethodPatch,
+			path:         "/api/v1/links/abc123",
+			pathTemplate: "/api/v1/links/{code}",
+			body:         `{"url":`,
+			mockSetup:    func(m *MockURLServiceClient) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "delete link",
+			method:       http.MethodDelete,
+			path:         "/api/v1/links/abc123",
+			pathTemplate: "/api/v1/links/{code}",
+			mockSetup: func(m *MockURLServiceClient) {
+				m.On("DeleteShortURL", mock.Anything, mock.Anything, mock.Anything).
+					Return(&pb.DeleteURLResponse{Success: true}, nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "delete link internal error",
+			method:       http.MethodDelete,
+			path:         "/api/v1/links/abc123",
+			pathTemplate: "/api/v1/links/{code}",
+			mockSetup: func(m *MockURLServiceClient) {
+				m.On("DeleteShortURL", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil, errors.New("grpc failure"))
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:         "link stats",
+			method:       http.MethodGet,
+			path:         "/api/v1/links/abc123/stats",
+			pathTemplate: "/api/v1/links/{code}/stats",
+			mockSetup: func(m *MockURLServiceClient) {
+				m.On("GetURLDetails", mock.Anything, mock.Anything, mock.Anything).
+					Return(&pb.GetURLDetailsResponse{Url: details}, nil)
+			},
+			expectedCode: http.StatusOK,
+			checkBody: func(t *testing.T, w *httptest.ResponseRecorder) {
+				require.JSONEq(t, `{"short_code":"abc123","click_count":5,"created_at":"2025-01-01T00:00:00Z","updated_at":"2025-01-01T00:00:00Z"}`, w.Body.String())
+			},
+		},
+	}
+
+	spec := loadOpenAPISpec(t)
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient := new(MockURLServiceClient)
+			tc.mockSetup(mockClient)
+			server := &GatewayServer{
+				GrpcClient: mockClient,
+				AuthClient: newAuthMock(),
+				Logger:     zap.NewNop(),
+				Metrics:    &metrics.NoopMetrics{},
+			}
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			if !tc.anonymous {
+				req.Header.Set("Authorization", "Bearer "+testAPIKey)
+			}
+			w := httptest.NewRecorder()
+
+			server.NewRouter().ServeHTTP(w, req)
+
+			require.Equal(t, tc.expectedCode, w.Code, w.Body.String())
+			require.NoError(t, spec.validateResponse(tc.pathTemplate, tc.method, w))
+			if tc.checkBody != nil {
+				tc.checkBody(t, w)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func threatMatchError(t *testing.T) error {
+	st, err := status.New(codes.PermissionDenied, "destination is on the threat list").WithDetails(&errdetails.ErrorInfo{
+		Reason: "THREAT_MATCH",
+		Domain: "url-shortener",
+	})
+	require.NoError(t, err)
+	return st.Err()
+}
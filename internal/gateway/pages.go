@@ -0,0 +1,70 @@
+package gateway
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// pages are the HTML pages served instead of a redirect, each one fills in
+// the "title" and "content" blocks of the shared layout
+var pages = map[string]*template.Template{
+	"preview":      parsePage("preview.html"),
+	"interstitial": parsePage("interstitial.html"),
+	// deliberately has no way through to the destination
+	"quarantined": parsePage("quarantined.html"),
+}
+
+func parsePage(name string) *template.Template {
+	return template.Must(template.ParseFS(templateFS, "templates/layout.html", "templates/"+name))
+}
+
+type previewPageData struct {
+	ShortCode string
+	// Destination is empty for quarantined, click-limited and not yet active links
+	Destination string
+	CreatedAt   time.Time
+	ClickCount  int64
+	ExpiresAt   *time.Time
+	Expired     bool
+	Quarantined bool
+	ActiveFrom  *time.Time
+	Inactive    bool
+	MaxClicks   int64
+	Exhausted   bool
+}
+
+type interstitialPageData struct {
+	ShortCode string
+	// Destination is trusted so Android intent: links survive html/template's
+	// URL filter, the url-service only stores http(s) and intent URLs
+	Destination template.URL
+}
+
+type quarantinedPageData struct {
+	ShortCode string
+}
+
+// renderPage writes the named page. Pages describe a single link at a point
+// in time, so they're never cached.
+func (s *GatewayServer) renderPage(w http.ResponseWriter, statusCode int, name string, data any) {
+	// render first so a template error doesn't leave a half-written page
+	var buf bytes.Buffer
+	if err := pages[name].Execute(&buf, data); err != nil {
+		s.Logger.Error("Failed to render page", zap.String("page", name), zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	w.Write(buf.Bytes())
+}
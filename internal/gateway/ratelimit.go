@@ -0,0 +1,69 @@
+package gateway
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/sammyqtran/url-shortener/internal/ratelimit"
+	"go.uber.org/zap"
+)
+
+// Rate limit policies, each counted separately per client
+const (
+	rateLimitPolicyCreate   = "create"
+	rateLimitPolicyRedirect = "redirect"
+	rateLimitPolicyAuth     = "auth"
+)
+
+// authenticate is Authenticate behind the per-IP auth limit, requests are
+// counted before their credentials cost a lookup
+func (s *GatewayServer) authenticate(next http.Handler) http.Handler {
+	return s.RateLimit(rateLimitPolicyAuth, s.AuthLimit, s.Authenticate(next))
+}
+
+// RateLimit wraps a handler so each client gets at most limit requests under
+// policy. Requests pass through when no limiter is configured or Redis fails.
+func (s *GatewayServer) RateLimit(policy string, limit ratelimit.Limit, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.RateLimiter == nil || limit.Requests <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		result, err := s.RateLimiter.Allow(r.Context(), policy, s.rateLimitKey(r), limit)
+		if err != nil {
+			s.Logger.Warn("Rate limiter unavailable, allowing request", zap.String("policy", policy), zap.Error(err))
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(result.Reset.Unix(), 10))
+
+		if !result.Allowed {
+			s.Metrics.IncRateLimitRejected("gateway", policy)
+			s.Logger.Warn("Rate limit exceeded",
+				zap.String("policy", policy),
+				zap.String("client", s.rateLimitKey(r)),
+			)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+			s.respondWithHTTPError(w, r, routeTemplate(r), http.StatusTooManyRequests, "rate limit exceeded")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitKey identifies the client by API key or JWT subject, falling back to its IP
+func (s *GatewayServer) rateLimitKey(r *http.Request) string {
+	if caller, ok := principalFromContext(r.Context()); ok {
+		if caller.KeyID != 0 {
+			return "key:" + strconv.FormatInt(caller.KeyID, 10)
+		}
+		return "user:" + caller.UserID
+	}
+	return "ip:" + s.getClientIP(r)
+}
@@ -0,0 +1,199 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sammyqtran/url-shortener/internal/metrics"
+	"github.com/sammyqtran/url-shortener/internal/ratelimit"
+	pb "github.com/sammyqtran/url-shortener/proto"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type MockRateLimiter struct {
+	mock.Mock
+}
+
+func (m *MockRateLimiter) Allow(ctx context.Context, policy, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	args := m.Called(ctx, policy, key, limit)
+	return args.Get(0).(ratelimit.Result), args.Error(1)
+}
+
+type rateLimitMetrics struct {
+	metrics.NoopMetrics
+	rejected []string
+}
+
+func (m *rateLimitMetrics) IncRateLimitRejected(service, policy string) {
+	m.rejected = append(m.rejected, policy)
+}
+
+func TestRateLimit(t *testing.T) {
+	reset := time.Unix(1_700_000_060, 0)
+	createLimit := ratelimit.Limit{Requests: 10, Window: time.Minute}
+	redirectLimit := ratelimit.Limit{Requests: 100, Window: time.Minute}
+	authLimit := ratelimit.Limit{Requests: 300, Window: time.Minute}
+	allowAuth := func(m *MockRateLimiter) {
+		m.On("Allow", mock.Anything, "auth", "ip:192.0.2.1", authLimit).
+			Return(ratelimit.Result{Allowed: true, Limit: 300, Remaining: 299, Reset: reset}, nil)
+	}
+
+	tests := []struct {
+		name            string
+		method          string
+		path            string
+		apiKey          string
+		remoteAddr      string
+		limiterSetup    func(m *MockRateLimiter)
+		clientSetup     func(m *MockURLServiceClient)
+		expectedCode    int
+		expectedHeaders map[string]string
+		expectRejected  []string
+	}{
+		{
+			name:   "create keyed by api key",
+			method: http.MethodPost,
+			path:   "/create",
+			apiKey: testAPIKey,
+			limiterSetup: func(m *MockRateLimiter) {
+				allowAuth(m)
+				m.On("Allow", mock.Anything, "create", "key:1", createLimit).
+					Return(ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9, Reset: reset}, nil)
+			},
+			clientSetup: func(m *MockURLServiceClient) {
+				m.On("CreateShortURL", mock.Anything, mock.Anything, mock.Anything).
+					Return(&pb.CreateURLResponse{ShortCode: "abc123"}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"X-RateLimit-Limit":     "10",
+				"X-RateLimit-Remaining": "9",
+				"X-RateLimit-Reset":     "1700000060",
+			},
+		},
+		{
+			name:   "create over limit",
+			method: http.MethodPost,
+			path:   "/create",
+			apiKey: testAPIKey,
+			limiterSetup: func(m *MockRateLimiter) {
+				allowAuth(m)
+				m.On("Allow", mock.Anything, "create", "key:1", createLimit).
+					Return(ratelimit.Result{Allowed: false, Limit: 10, Remaining: 0, Reset: reset, RetryAfter: 1500 * time.Millisecond}, nil)
+			},
+			clientSetup:  func(m *MockURLServiceClient) {},
+			expectedCode: http.StatusTooManyRequests,
+			expectedHeaders: map[string]string{
+				"X-RateLimit-Remaining": "0",
+				"Retry-After":           "2",
+			},
+			expectRejected: []string{"create"},
+		},
+		{
+			name:       "redirect keyed by client ip",
+			method:     http.MethodGet,
+			path:       "/abc123",
+			remoteAddr: "203.0.113.7:5555",
+			limiterSetup: func(m *MockRateLimiter) {
+				m.On("Allow", mock.Anything, "redirect", "ip:203.0.113.7", redirectLimit).
+					Return(ratelimit.Result{Allowed: false, Limit: 100, Reset: reset, RetryAfter: 30 * time.Second}, nil)
+			},
+			clientSetup:     func(m *MockURLServiceClient) {},
+			expectedCode:    http.StatusTooManyRequests,
+			expectedHeaders: map[string]string{"Retry-After": "30"},
+			expectRejected:  []string{"redirect"},
+		},
+		{
+			name:       "limiter failure fails open",
+			method:     http.MethodGet,
+			path:       "/abc123",
+			remoteAddr: "203.0.113.7:5555",
+			limiterSetup: func(m *MockRateLimiter) {
+				m.On("Allow", mock.Anything, "redirect", mock.Anything, redirectLimit).
+					Return(ratelimit.Result{}, errors.New("redis down"))
+			},
+			clientSetup: func(m *MockURLServiceClient) {
+				m.On("GetOriginalURL", mock.Anything, mock.Anything, mock.Anything).
+					Return(&pb.GetURLResponse{OriginalUrl: "https://example.com", Found: true}, nil)
+			},
+			expectedCode: http.StatusFound,
+		},
+		{
+			name:         "unauthenticated create is rejected before the create limit",
+			method:       http.MethodPost,
+			path:         "/create",
+			limiterSetup: allowAuth,
+			clientSetup:  func(m *MockURLServiceClient) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:   "made-up api keys limited per ip before the key lookup",
+			method: http.MethodPost,
+			path:   "/api/v1/links",
+			apiKey: "usk_madeup",
+			limiterSetup: func(m *MockRateLimiter) {
+				m.On("Allow", mock.Anything, "auth", "ip:192.0.2.1", authLimit).
+					Return(ratelimit.Result{Allowed: false, Limit: 300, Reset: reset, RetryAfter: 10 * time.Second}, nil)
+			},
+			clientSetup:     func(m *MockURLServiceClient) {},
+			expectedCode:    http.StatusTooManyRequests,
+			expectedHeaders: map[string]string{"Retry-After": "10"},
+			expectRejected:  []string{"auth"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			limiter := new(MockRateLimiter)
+			tc.limiterSetup(limiter)
+			mockClient := new(MockURLServiceClient)
+			tc.clientSetup(mockClient)
+			recorder := &rateLimitMetrics{}
+			authClient := newAuthMock()
+
+			server := &GatewayServer{
+				GrpcClient:    mockClient,
+				AuthClient:    authClient,
+				Logger:        zap.NewNop(),
+				Metrics:       recorder,
+				RateLimiter:   limiter,
+				CreateLimit:   createLimit,
+				RedirectLimit: redirectLimit,
+				AuthLimit:     authLimit,
+			}
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(`{"url": "https://example.com"}`))
+			if tc.apiKey != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.apiKey)
+			}
+			if tc.remoteAddr != "" {
+				req.RemoteAddr = tc.remoteAddr
+			}
+			w := httptest.NewRecorder()
+
+			server.NewRouter().ServeHTTP(w, req)
+
+			require.Equal(t, tc.expectedCode, w.Code, w.Body.String())
+			for header, value := range tc.expectedHeaders {
+				require.Equal(t, value, w.Header().Get(header), header)
+			}
+			if tc.expectedCode == http.StatusTooManyRequests {
+				require.JSONEq(t, `{"error":"rate limit exceeded","code":"resource_exhausted"}`, w.Body.String())
+			}
+			require.Equal(t, tc.expectRejected, recorder.rejected)
+			limiter.AssertExpectations(t)
+			mockClient.AssertExpectations(t)
+			if slices.Contains(tc.expectRejected, "auth") {
+				authClient.AssertNotCalled(t, "ValidateAPIKey", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
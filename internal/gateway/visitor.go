@@ -0,0 +1,75 @@
+package gateway
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// visitorCookie keeps visitors on the same variant of A/B split links
+const visitorCookie = "visitor_id"
+
+// visitorCookieMaxAge is how long a visitor keeps their variants
+const visitorCookieMaxAge = 365 * 24 * time.Hour
+
+// visitorIDLength is the hex length of generated visitor IDs
+const visitorIDLength = 32
+
+// visitorID returns the visitor's ID from their cookie, or a new one with
+// isNew set. New IDs are derived from the visitor's address and User-Agent,
+// so clients that never keep the cookie still get the same ID, and variant,
+// on every click. Cookies that aren't signed with VisitorSecret are replaced,
+// so visitors can't pick their own variant.
+func (s *GatewayServer) visitorID(r *http.Request, clientIP string) (id string, isNew bool) {
+	if cookie, err := r.Cookie(visitorCookie); err == nil && len(s.VisitorSecret) > 0 {
+		if id, ok := verifyVisitorCookie(s.VisitorSecret, cookie.Value); ok {
+			return id, false
+		}
+	}
+
+	sum := sha256.Sum256([]byte(clientIP + "\x00" + r.UserAgent()))
+	return hex.EncodeToString(sum[:visitorIDLength/2]), true
+}
+
+func isVisitorID(value string) bool {
+	if len(value) != visitorIDLength {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
+
+// signVisitorID makes the cookie value for id, the ID and its HMAC
+func signVisitorID(secret []byte, id string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(id))
+	return id + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyVisitorCookie returns the ID in a cookie value made by signVisitorID
+func verifyVisitorCookie(secret []byte, value string) (string, bool) {
+	id, _, ok := strings.Cut(value, ".")
+	if !ok || !isVisitorID(id) {
+		return "", false
+	}
+	return id, hmac.Equal([]byte(value), []byte(signVisitorID(secret, id)))
+}
+
+// setVisitorCookie stores id so the visitor's next clicks get the same
+// variant. Without a VisitorSecret no cookie is set.
+func (s *GatewayServer) setVisitorCookie(w http.ResponseWriter, id string) {
+	if len(s.VisitorSecret) == 0 {
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     visitorCookie,
+		Value:    signVisitorID(s.VisitorSecret, id),
+		Path:     "/",
+		MaxAge:   int(visitorCookieMaxAge / time.Second),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,203 @@
+// Package geoip maps client addresses to countries with an offline database
+// of CIDR networks, loaded from a CSV file and reloaded when it changes.
+package geoip
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var ErrInvalidCountry = errors.New("invalid country code")
+
+// NormalizeCountry upper-cases an ISO 3166-1 alpha-2 country code and checks
+// it is two letters
+func NormalizeCountry(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+		return "", fmt.Errorf("%w: %q, must be two letters such as US", ErrInvalidCountry, code)
+	}
+	return code, nil
+}
+
+// Table maps networks to countries, the most specific network containing an
+// address wins
+type Table struct {
+	networks map[netip.Prefix]string
+	// lengths are the prefix lengths in networks, longest first
+	lengths []int
+}
+
+// Len returns the number of networks in the table
+func (t *Table) Len() int {
+	return len(t.networks)
+}
+
+// Country returns the country of addr, or "" if no network contains it
+func (t *Table) Country(addr netip.Addr) string {
+	addr = addr.Unmap()
+	for _, length := range t.lengths {
+		if length > addr.BitLen() {
+			continue
+		}
+		network, err := addr.Prefix(length)
+		if err != nil {
+			continue
+		}
+		if country, ok := t.networks[network]; ok {
+			return country
+		}
+	}
+	return ""
+}
+
+// ParseCSV reads "network,country" records such as "81.2.69.0/24,GB". Blank
+// lines, lines starting with # and a header row are skipped, and columns
+// after the country are ignored.
+func ParseCSV(r io.Reader) (*Table, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	t := &Table{networks: make(map[netip.Prefix]string)}
+	seen := make(map[int]bool)
+	for record := 1; ; record++ {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading GeoIP database: %w", err)
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("record %d: want network and country", record)
+		}
+
+		network, err := netip.ParsePrefix(strings.TrimSpace(fields[0]))
+		if err != nil {
+			if record == 1 {
+				// header
+				continue
+			}
+			return nil, fmt.Errorf("record %d: %w", record, err)
+		}
+		country, err := NormalizeCountry(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", record, err)
+		}
+
+		// lookups unmap addresses, so ::ffff:1.2.3.0/120 is stored as 1.2.3.0/24
+		if addr := network.Addr(); addr.Is4In6() && network.Bits() >= 96 {
+			network = netip.PrefixFrom(addr.Unmap(), network.Bits()-96)
+		}
+		network = network.Masked()
+		t.networks[network] = country
+		if !seen[network.Bits()] {
+			seen[network.Bits()] = true
+			t.lengths = append(t.lengths, network.Bits())
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(t.lengths)))
+	return t, nil
+}
+
+// Database is a Table loaded from a file that is safe for concurrent use
+type Database struct {
+	file   string
+	logger *zap.Logger
+
+	mu      sync.RWMutex
+	table   *Table
+	modTime time.Time
+}
+
+// Open loads the CSV database in file
+func Open(file string, logger *zap.Logger) (*Database, error) {
+	d := &Database{
+		file:   file,
+		logger: logger,
+		table:  &Table{},
+	}
+	if _, err := d.Reload(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Country returns the country of the address in ip, or "" if it is unknown
+// or ip isn't an address
+func (d *Database) Country(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+
+	d.mu.RLock()
+	table := d.table
+	d.mu.RUnlock()
+	return table.Country(addr)
+}
+
+// Reload reads the database file again if it changed since the last load and
+// reports whether it did. On error the current table is kept.
+func (d *Database) Reload() (bool, error) {
+	info, err := os.Stat(d.file)
+	if err != nil {
+		return false, fmt.Errorf("error reading GeoIP database: %w", err)
+	}
+
+	d.mu.RLock()
+	unchanged := info.ModTime().Equal(d.modTime)
+	d.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	file, err := os.Open(d.file)
+	if err != nil {
+		return false, fmt.Errorf("error reading GeoIP database: %w", err)
+	}
+	defer file.Close()
+
+	table, err := ParseCSV(file)
+	if err != nil {
+		return false, fmt.Errorf("error parsing GeoIP database %s: %w", d.file, err)
+	}
+
+	d.mu.Lock()
+	d.table, d.modTime = table, info.ModTime()
+	d.mu.Unlock()
+
+	d.logger.Info("Loaded GeoIP database", zap.String("file", d.file), zap.Int("networks", table.Len()))
+	return true, nil
+}
+
+// Watch reloads the database file every interval until ctx is cancelled
+func (d *Database) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := d.Reload(); err != nil {
+			d.logger.Warn("Failed to reload GeoIP database, keeping the current one", zap.Error(err))
+		}
+	}
+}
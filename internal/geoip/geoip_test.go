@@ -0,0 +1,115 @@
+package geoip
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testDatabase = `network,country_iso_code
+# documentation ranges
+81.2.69.0/24,GB
+81.2.69.128/25,fr
+203.0.113.7/32,JP,extra column
+2001:db8::/32,DE
+::ffff:198.51.100.0/120,US
+`
+
+func TestNormalizeCountry(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+		wantErr  bool
+	}{
+		{code: "US", expected: "US"},
+		{code: " gb ", expected: "GB"},
+		{code: "USA", wantErr: true},
+		{code: "U1", wantErr: true},
+		{code: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			got, err := NormalizeCountry(tt.code)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidCountry)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestTableCountry(t *testing.T) {
+	table, err := ParseCSV(strings.NewReader(testDatabase))
+	require.NoError(t, err)
+	require.Equal(t, 5, table.Len())
+
+	tests := []struct {
+		addr     string
+		expected string
+	}{
+		{addr: "81.2.69.1", expected: "GB"},
+		{addr: "81.2.69.200", expected: "FR"}, // the more specific network wins
+		{addr: "::ffff:81.2.69.1", expected: "GB"},
+		{addr: "203.0.113.7", expected: "JP"},
+		{addr: "203.0.113.8", expected: ""},
+		{addr: "2001:db8:1::1", expected: "DE"},
+		{addr: "198.51.100.20", expected: "US"},
+		{addr: "10.0.0.1", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			require.Equal(t, tt.expected, table.Country(netip.MustParseAddr(tt.addr)))
+		})
+	}
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	for _, input := range []string{
+		"81.2.69.0/24\n",
+		"81.2.69.0/24,GB\nnot-a-network,GB\n",
+		"81.2.69.0/24,GBR\n",
+	} {
+		_, err := ParseCSV(strings.NewReader(input))
+		require.Error(t, err, input)
+	}
+}
+
+func TestDatabaseReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "geoip.csv")
+	require.NoError(t, os.WriteFile(file, []byte("81.2.69.0/24,GB\n"), 0o644))
+
+	db, err := Open(file, zap.NewNop())
+	require.NoError(t, err)
+	require.Equal(t, "GB", db.Country("81.2.69.1"))
+	require.Equal(t, "", db.Country("not an address"))
+
+	changed, err := db.Reload()
+	require.NoError(t, err)
+	require.False(t, changed, "an unchanged file isn't parsed again")
+
+	require.NoError(t, os.WriteFile(file, []byte("81.2.69.0/24,IE\n"), 0o644))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(file, later, later))
+	changed, err = db.Reload()
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t, "IE", db.Country("81.2.69.1"))
+
+	// a broken file keeps the last good table
+	require.NoError(t, os.WriteFile(file, []byte("garbage,GB\ngarbage,GB\n"), 0o644))
+	later = later.Add(time.Minute)
+	require.NoError(t, os.Chtimes(file, later, later))
+	_, err = db.Reload()
+	require.Error(t, err)
+	require.Equal(t, "IE", db.Country("81.2.69.1"))
+}
@@ -20,6 +20,8 @@ type Metrics interface {
 	IncConsumeEvent(service, eventName string)
 	IncConsumeEventError(service, eventName string)
 	ObserveConsumeEventLatency(service, eventName string, seconds float64)
+	IncCampaignClick(service string)
+	IncVariantClick(service, variant string)
 
 	// Cache operations (URL service)
 	IncCacheHit(service, cacheName string)
@@ -30,4 +32,17 @@ type Metrics interface {
 	IncDBOperation(service, operation string)
 	IncDBError(service, operation string)
 	ObserveDBOperationDuration(service, operation string, seconds float64)
+
+	// Short code generation (URL service)
+	IncShortCodeCollision(service, reason string)
+	SetCodePoolDepth(service string, depth int64)
+	IncCodePoolMiss(service string)
+
+	// Destination policy (URL service)
+	IncDestinationRejected(service, reason string)
+	SetThreatListSize(service string, size int64)
+	IncLinksQuarantined(service string, count int)
+
+	// Rate limiting (gateway)
+	IncRateLimitRejected(service, policy string)
 }
@@ -37,3 +37,21 @@ func (m *NoopMetrics) IncDBOperation(service, operation string) {}
 func (m *NoopMetrics) IncDBError(service, operation string) {}
 
 func (m *NoopMetrics) ObserveDBOperationDuration(service, operation string, seconds float64) {}
+
+func (m *NoopMetrics) IncShortCodeCollision(service, reason string) {}
+
+func (m *NoopMetrics) IncDestinationRejected(service, reason string) {}
+
+func (m *NoopMetrics) SetThreatListSize(service string, size int64) {}
+
+func (m *NoopMetrics) IncLinksQuarantined(service string, count int) {}
+
+func (m *NoopMetrics) IncCampaignClick(service string) {}
+
+func (m *NoopMetrics) IncVariantClick(service, variant string) {}
+
+func (m *NoopMetrics) SetCodePoolDepth(service string, depth int64) {}
+
+func (m *NoopMetrics) IncCodePoolMiss(service string) {}
+
+func (m *NoopMetrics) IncRateLimitRejected(service, policy string) {}
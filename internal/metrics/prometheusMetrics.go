@@ -21,12 +21,21 @@ type PrometheusMetrics struct {
 	consumeEvents       *prometheus.CounterVec
 	consumeEventErrors  *prometheus.CounterVec
 	consumeEventLatency *prometheus.HistogramVec
+	campaignClicks      *prometheus.CounterVec
+	variantClicks       *prometheus.CounterVec
 	cacheHits           *prometheus.CounterVec
 	cacheMisses         *prometheus.CounterVec
 	cacheErrors         *prometheus.CounterVec
 	dbOperations        *prometheus.CounterVec
 	dbErrors            *prometheus.CounterVec
 	dbOperationDuration *prometheus.HistogramVec
+	shortCodeCollisions *prometheus.CounterVec
+	codePoolDepth       *prometheus.GaugeVec
+	codePoolMisses      *prometheus.CounterVec
+	destinationRejects  *prometheus.CounterVec
+	threatListSize      *prometheus.GaugeVec
+	linksQuarantined    *prometheus.CounterVec
+	rateLimitRejections *prometheus.CounterVec
 }
 
 func NewPrometheusMetrics() *PrometheusMetrics {
@@ -83,6 +92,14 @@ func NewPrometheusMetrics() *PrometheusMetrics {
 			Help:    "Event consuming duration in seconds",
 			Buckets: prometheus.DefBuckets,
 		}, []string{"service", "event"}),
+		campaignClicks: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "campaign_clicks_total",
+			Help: "Total redirects of links in a campaign",
+		}, []string{"service"}),
+		variantClicks: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "variant_clicks_total",
+			Help: "Total redirects of A/B split links by variant",
+		}, []string{"service", "variant"}),
 		cacheHits: promauto.NewCounterVec(prometheus.CounterOpts{
 			Name: "cache_hits_total",
 			Help: "Total cache hits",
@@ -108,6 +125,34 @@ func NewPrometheusMetrics() *PrometheusMetrics {
 			Help:    "Duration of database operations in seconds",
 			Buckets: prometheus.DefBuckets, // or customize: prometheus.ExponentialBuckets(0.001, 2, 12)
 		}, []string{"service", "operation"}),
+		shortCodeCollisions: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "short_code_collisions_total",
+			Help: "Total generated short codes that were taken or reserved",
+		}, []string{"service", "reason"}),
+		codePoolDepth: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "code_pool_depth",
+			Help: "Pre-generated short codes waiting in the pool",
+		}, []string{"service"}),
+		codePoolMisses: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "code_pool_misses_total",
+			Help: "Total creates that found the code pool empty and generated a code directly",
+		}, []string{"service"}),
+		destinationRejects: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "destination_rejections_total",
+			Help: "Total destination URLs rejected by the destination policy",
+		}, []string{"service", "reason"}),
+		threatListSize: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "threat_list_prefixes",
+			Help: "Hash prefixes in the local threat list",
+		}, []string{"service"}),
+		linksQuarantined: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "links_quarantined_total",
+			Help: "Total existing links quarantined because their destination matched the threat list",
+		}, []string{"service"}),
+		rateLimitRejections: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "rate_limit_rejections_total",
+			Help: "Total requests rejected by the rate limiter",
+		}, []string{"service", "policy"}),
 	}
 }
 
@@ -161,6 +206,14 @@ func (m *PrometheusMetrics) ObserveConsumeEventLatency(service, eventName string
 	m.consumeEventLatency.WithLabelValues(service, eventName).Observe(seconds)
 }
 
+func (m *PrometheusMetrics) IncCampaignClick(service string) {
+	m.campaignClicks.WithLabelValues(service).Inc()
+}
+
+func (m *PrometheusMetrics) IncVariantClick(service, variant string) {
+	m.variantClicks.WithLabelValues(service, variant).Inc()
+}
+
 func (m *PrometheusMetrics) IncCacheHit(service, cacheName string) {
 	m.cacheHits.WithLabelValues(service, cacheName).Inc()
 }
@@ -184,3 +237,31 @@ func (m *PrometheusMetrics) IncDBError(service, operation string) {
 func (m *PrometheusMetrics) ObserveDBOperationDuration(service, operation string, seconds float64) {
 	m.dbOperationDuration.WithLabelValues(service, operation).Observe(seconds)
 }
+
+func (m *PrometheusMetrics) IncShortCodeCollision(service, reason string) {
+	m.shortCodeCollisions.WithLabelValues(service, reason).Inc()
+}
+
+func (m *PrometheusMetrics) SetCodePoolDepth(service string, depth int64) {
+	m.codePoolDepth.WithLabelValues(service).Set(float64(depth))
+}
+
+func (m *PrometheusMetrics) IncCodePoolMiss(service string) {
+	m.codePoolMisses.WithLabelValues(service).Inc()
+}
+
+func (m *PrometheusMetrics) IncDestinationRejected(service, reason string) {
+	m.destinationRejects.WithLabelValues(service, reason).Inc()
+}
+
+func (m *PrometheusMetrics) SetThreatListSize(service string, size int64) {
+	m.threatListSize.WithLabelValues(service).Set(float64(size))
+}
+
+func (m *PrometheusMetrics) IncLinksQuarantined(service string, count int) {
+	m.linksQuarantined.WithLabelValues(service).Add(float64(count))
+}
+
+func (m *PrometheusMetrics) IncRateLimitRejected(service, policy string) {
+	m.rateLimitRejections.WithLabelValues(service, policy).Inc()
+}
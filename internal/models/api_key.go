@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+type APIKey struct {
+	ID        int64      `db:"id" json:"id"`
+	UserID    string     `db:"user_id" json:"user_id"`
+	Name      string     `db:"name" json:"name"`
+	KeyHash   string     `db:"key_hash" json:"-"`
+	Prefix    string     `db:"prefix" json:"prefix"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	RevokedAt *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+}
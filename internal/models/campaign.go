@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// Campaign holds the UTM parameters added to the destination of its links
+type Campaign struct {
+	ID          int64     `db:"id" json:"id"`
+	UserID      string    `db:"user_id" json:"user_id"`
+	Name        string    `db:"name" json:"name"`
+	UTMSource   string    `db:"utm_source" json:"utm_source,omitempty"`
+	UTMMedium   string    `db:"utm_medium" json:"utm_medium,omitempty"`
+	UTMCampaign string    `db:"utm_campaign" json:"utm_campaign,omitempty"`
+	UTMTerm     string    `db:"utm_term" json:"utm_term,omitempty"`
+	UTMContent  string    `db:"utm_content" json:"utm_content,omitempty"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -13,4 +16,195 @@ type URL struct {
 	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
 	ClickCount  int64      `db:"click_count" json:"click_count"`
 	ExpiresAt   *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	// QuarantinedAt is set while the destination matches the threat list
+	QuarantinedAt *time.Time `db:"quarantined_at" json:"quarantined_at,omitempty"`
+	// Interstitial shows a "you are leaving" page instead of redirecting straight away
+	Interstitial bool `db:"interstitial" json:"interstitial,omitempty"`
+	// RedirectType is the HTTP status redirects answer with, 301, 302, 307 or 308
+	RedirectType int `db:"redirect_type" json:"redirect_type,omitempty"`
+	// QueryPassthrough is a passthrough query mode, PathPassthrough makes a wildcard link
+	QueryPassthrough string `db:"query_passthrough" json:"query_passthrough,omitempty"`
+	PathPassthrough  bool   `db:"path_passthrough" json:"path_passthrough,omitempty"`
+	// CampaignID names the campaign whose UTM parameters are added on redirect
+	CampaignID *int64 `db:"campaign_id" json:"campaign_id,omitempty"`
+	// GeoRoutes sends visitors from a country, keyed by ISO 3166-1 alpha-2
+	// code, to another destination than OriginalURL
+	GeoRoutes Routes `db:"geo_routes" json:"geo_routes,omitempty"`
+	// DeviceRoutes does the same by platform (see device.Platform), they win over GeoRoutes
+	DeviceRoutes Routes `db:"device_routes" json:"device_routes,omitempty"`
+	// Variants split visitors between destinations instead of OriginalURL,
+	// routes still win over them
+	Variants Variants `db:"variants" json:"variants,omitempty"`
+	// ActiveFrom is when a launch link starts resolving, nil for straight away
+	ActiveFrom *time.Time `db:"active_from" json:"active_from,omitempty"`
+	// Schedule sends the link elsewhere for time windows, the first window
+	// containing the current time wins over every other destination
+	Schedule Schedule `db:"schedule" json:"schedule,omitempty"`
+	// MaxClicks is how many redirects the link answers, nil for no limit
+	MaxClicks *int64 `db:"max_clicks" json:"max_clicks,omitempty"`
+	// URLHash is the SHA-256 of the normalized destination used by dedupe, reads leave it empty
+	URLHash string `db:"url_hash" json:"-"`
+}
+
+// Destinations are every URL the link can redirect to
+func (u *URL) Destinations() []string {
+	destinations := []string{u.OriginalURL}
+	for _, routes := range []Routes{u.GeoRoutes, u.DeviceRoutes} {
+		for _, destination := range routes {
+			destinations = append(destinations, destination)
+		}
+	}
+	for _, variant := range u.Variants {
+		destinations = append(destinations, variant.URL)
+	}
+	for _, entry := range u.Schedule {
+		destinations = append(destinations, entry.URL)
+	}
+	return destinations
+}
+
+// IsActive reports whether the link resolves at now, launch links don't before ActiveFrom
+func (u *URL) IsActive(now time.Time) bool {
+	return u.ActiveFrom == nil || !now.Before(*u.ActiveFrom)
+}
+
+// NextChange is the first time after now the link resolves differently
+// because of ActiveFrom or a schedule boundary, false if it never will
+func (u *URL) NextChange(now time.Time) (time.Time, bool) {
+	var next time.Time
+	consider := func(t *time.Time) {
+		if t != nil && t.After(now) && (next.IsZero() || t.Before(next)) {
+			next = *t
+		}
+	}
+
+	consider(u.ActiveFrom)
+	for _, entry := range u.Schedule {
+		consider(entry.Start)
+		consider(entry.End)
+	}
+	return next, !next.IsZero()
+}
+
+// Routes maps a visitor attribute, such as their country, to a destination, stored as JSONB
+type Routes map[string]string
+
+func (r Routes) Value() (driver.Value, error) {
+	if len(r) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(r)
+}
+
+func (r *Routes) Scan(src interface{}) error {
+	switch data := src.(type) {
+	case nil:
+		*r = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(data, r)
+	case string:
+		return json.Unmarshal([]byte(data), r)
+	default:
+		return fmt.Errorf("cannot scan %T into Routes", src)
+	}
+}
+
+// Variant is one destination of an A/B split
+type Variant struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Weight is the variant's share of visitors relative to the others
+	Weight int `json:"weight"`
+}
+
+// Variants are the destinations of an A/B split, stored as JSONB
+type Variants []Variant
+
+// Weights are the variants' weights in order, see split.Pick
+func (v Variants) Weights() []int {
+	weights := make([]int, len(v))
+	for i, variant := range v {
+		weights[i] = variant.Weight
+	}
+	return weights
+}
+
+func (v Variants) Value() (driver.Value, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+func (v *Variants) Scan(src interface{}) error {
+	switch data := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(data, v)
+	case string:
+		return json.Unmarshal([]byte(data), v)
+	default:
+		return fmt.Errorf("cannot scan %T into Variants", src)
+	}
+}
+
+// ScheduleEntry is a destination for the window [Start, End), a nil side is open
+type ScheduleEntry struct {
+	Start *time.Time `json:"start,omitempty"`
+	End   *time.Time `json:"end,omitempty"`
+	URL   string     `json:"url"`
+}
+
+// Contains reports whether now is inside the entry's window
+func (e ScheduleEntry) Contains(now time.Time) bool {
+	return (e.Start == nil || !now.Before(*e.Start)) && (e.End == nil || now.Before(*e.End))
+}
+
+// Equal reports whether both entries have the same window and destination
+func (e ScheduleEntry) Equal(other ScheduleEntry) bool {
+	return e.URL == other.URL && equalTime(e.Start, other.Start) && equalTime(e.End, other.End)
+}
+
+func equalTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// Schedule is an ordered list of time windows with their own destination, stored as JSONB
+type Schedule []ScheduleEntry
+
+// Destination is the destination of the first entry containing now, false if none does
+func (s Schedule) Destination(now time.Time) (string, bool) {
+	for _, entry := range s {
+		if entry.Contains(now) {
+			return entry.URL, true
+		}
+	}
+	return "", false
+}
+
+func (s Schedule) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+func (s *Schedule) Scan(src interface{}) error {
+	switch data := src.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(data, s)
+	case string:
+		return json.Unmarshal([]byte(data), s)
+	default:
+		return fmt.Errorf("cannot scan %T into Schedule", src)
+	}
 }
@@ -0,0 +1,149 @@
+// Package passthrough builds the final redirect target of links that forward
+// the incoming query string or the path after their short code.
+package passthrough
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Query modes say whether and how incoming query parameters are merged into
+// the destination's
+const (
+	// QueryOff drops the incoming query string, the default
+	QueryOff = "off"
+	// QueryPreferDestination adds incoming parameters the destination doesn't set
+	QueryPreferDestination = "prefer_destination"
+	// QueryPreferRequest lets incoming parameters replace the destination's
+	QueryPreferRequest = "prefer_request"
+)
+
+var (
+	ErrInvalidQueryMode = errors.New("invalid query passthrough mode")
+	ErrInvalidPath      = errors.New("invalid passthrough path")
+)
+
+// Options are a link's passthrough settings
+type Options struct {
+	// Query is one of the query modes, "" means QueryOff
+	Query string
+	// Path appends the path after the short code to the destination's path
+	Path bool
+}
+
+// ValidateQueryMode checks mode is one of the query modes
+func ValidateQueryMode(mode string) error {
+	switch mode {
+	case QueryOff, QueryPreferDestination, QueryPreferRequest:
+		return nil
+	}
+	return fmt.Errorf("%w: %q, must be %s, %s or %s", ErrInvalidQueryMode, mode, QueryOff, QueryPreferDestination, QueryPreferRequest)
+}
+
+// Apply returns destination with rest, the still-escaped path after the
+// short code, and the incoming raw query added as opts allows. Only the path
+// and query change, so the scheme and host that passed the destination
+// checks are kept. Dot segments in rest are rejected rather than resolved,
+// so a request can't climb out of the destination's path.
+func Apply(destination, rest, rawQuery string, opts Options) (string, error) {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return "", err
+	}
+
+	if rest = strings.TrimLeft(rest, "/"); rest != "" && opts.Path {
+		if err := joinPath(u, rest); err != nil {
+			return "", err
+		}
+	}
+
+	switch opts.Query {
+	case "", QueryOff:
+	case QueryPreferDestination:
+		u.RawQuery = MergeQuery(u.RawQuery, rawQuery, false)
+	case QueryPreferRequest:
+		u.RawQuery = MergeQuery(u.RawQuery, rawQuery, true)
+	default:
+		return "", ValidateQueryMode(opts.Query)
+	}
+
+	return u.String(), nil
+}
+
+// joinPath appends the escaped path rest to u's path with exactly one slash
+// between them
+func joinPath(u *url.URL, rest string) error {
+	for _, segment := range strings.Split(rest, "/") {
+		decoded, err := url.PathUnescape(segment)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPath, err)
+		}
+		if decoded == "." || decoded == ".." || strings.ContainsAny(decoded, "/\\") {
+			return fmt.Errorf("%w: %q", ErrInvalidPath, segment)
+		}
+	}
+
+	escaped := strings.TrimSuffix(u.EscapedPath(), "/") + "/" + rest
+	path, err := url.PathUnescape(escaped)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+	u.Path = path
+	u.RawPath = escaped
+	return nil
+}
+
+// MergeQuery adds the parameters of the raw query incoming to base, keeping
+// the order of both. On a name they share, base keeps its values unless
+// preferIncoming.
+func MergeQuery(base, incoming string, preferIncoming bool) string {
+	incomingNames := queryNames(incoming)
+	if len(incomingNames) == 0 {
+		return base
+	}
+	baseNames := queryNames(base)
+
+	var pairs []string
+	for _, pair := range splitQuery(base) {
+		if preferIncoming && incomingNames[queryName(pair)] {
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+	for _, pair := range splitQuery(incoming) {
+		if !preferIncoming && baseNames[queryName(pair)] {
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+	return strings.Join(pairs, "&")
+}
+
+func splitQuery(rawQuery string) []string {
+	var pairs []string
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair != "" {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+func queryNames(rawQuery string) map[string]bool {
+	names := make(map[string]bool)
+	for _, pair := range splitQuery(rawQuery) {
+		names[queryName(pair)] = true
+	}
+	return names
+}
+
+// queryName is the unescaped name of a "name=value" pair, so "a%62" and "ab" match
+func queryName(pair string) string {
+	name, _, _ := strings.Cut(pair, "=")
+	if unescaped, err := url.QueryUnescape(name); err == nil {
+		return unescaped
+	}
+	return name
+}
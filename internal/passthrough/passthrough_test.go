@@ -0,0 +1,57 @@
+package passthrough
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination string
+		rest        string
+		rawQuery    string
+		opts        Options
+		expected    string
+		expectError error
+	}{
+		{name: "nothing forwarded by default", destination: "https://example.com/docs?x=1", rest: "a/b", rawQuery: "y=2", expected: "https://example.com/docs?x=1"},
+		{name: "path appended", destination: "https://example.com/docs", rest: "a/b.html", opts: Options{Path: true}, expected: "https://example.com/docs/a/b.html"},
+		{name: "single slash between paths", destination: "https://example.com/docs/", rest: "/a", opts: Options{Path: true}, expected: "https://example.com/docs/a"},
+		{name: "path keeps query and fragment", destination: "https://example.com/docs?x=1#top", rest: "a", opts: Options{Path: true}, expected: "https://example.com/docs/a?x=1#top"},
+		{name: "escapes kept", destination: "https://example.com/", rest: "a%20b/c%3Fd", opts: Options{Path: true}, expected: "https://example.com/a%20b/c%3Fd"},
+		{name: "empty rest", destination: "https://example.com/docs", opts: Options{Path: true}, expected: "https://example.com/docs"},
+		{name: "dot segments rejected", destination: "https://example.com/docs/", rest: "a/../../admin", opts: Options{Path: true}, expectError: ErrInvalidPath},
+		{name: "escaped dot segments rejected", destination: "https://example.com/docs/", rest: "%2e%2E/admin", opts: Options{Path: true}, expectError: ErrInvalidPath},
+		{name: "escaped slash rejected", destination: "https://example.com/docs/", rest: "a%2F..", opts: Options{Path: true}, expectError: ErrInvalidPath},
+		{name: "host can't change", destination: "https://example.com", rest: "//evil.example/x", opts: Options{Path: true}, expected: "https://example.com/evil.example/x"},
+		{name: "query added", destination: "https://example.com/", rawQuery: "utm_source=x&b=2", opts: Options{Query: QueryPreferDestination}, expected: "https://example.com/?utm_source=x&b=2"},
+		{name: "destination wins", destination: "https://example.com/?a=1&b=1", rawQuery: "b=2&c=3", opts: Options{Query: QueryPreferDestination}, expected: "https://example.com/?a=1&b=1&c=3"},
+		{name: "request wins", destination: "https://example.com/?a=1&b=1&b=0", rawQuery: "b=2&c=3", opts: Options{Query: QueryPreferRequest}, expected: "https://example.com/?a=1&b=2&c=3"},
+		{name: "names compared unescaped", destination: "https://example.com/?a%62=1", rawQuery: "ab=2", opts: Options{Query: QueryPreferRequest}, expected: "https://example.com/?ab=2"},
+		{name: "empty incoming query", destination: "https://example.com/?a=1", opts: Options{Query: QueryPreferRequest}, expected: "https://example.com/?a=1"},
+		{name: "path and query", destination: "https://example.com/docs?a=1", rest: "x", rawQuery: "b=2", opts: Options{Path: true, Query: QueryPreferDestination}, expected: "https://example.com/docs/x?a=1&b=2"},
+		{name: "unknown mode", destination: "https://example.com/", opts: Options{Query: "both"}, expectError: ErrInvalidQueryMode},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Apply(tc.destination, tc.rest, tc.rawQuery, tc.opts)
+			if tc.expectError != nil {
+				require.ErrorIs(t, err, tc.expectError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestValidateQueryMode(t *testing.T) {
+	for _, mode := range []string{QueryOff, QueryPreferDestination, QueryPreferRequest} {
+		require.NoError(t, ValidateQueryMode(mode))
+	}
+	require.ErrorIs(t, ValidateQueryMode(""), ErrInvalidQueryMode)
+	require.ErrorIs(t, ValidateQueryMode("merge"), ErrInvalidQueryMode)
+}
@@ -42,6 +42,21 @@ func DefaultStreamConfig() *StreamConfig {
 // EventPublisher defines the methods needed to publish events.
 // Implemented by Publisher and by mocks in tests.
 type EventPublisher interface {
-	PublishURLCreated(ctx context.Context, shortCode, originalURL, createdBy string) error
-	PublishURLAccessed(ctx context.Context, shortCode, originalURL, userAgent, ipAddress, referrer string) error
+	PublishURLCreated(ctx context.Context, shortCode, originalURL, createdBy string, campaignID int64) error
+	PublishURLAccessed(ctx context.Context, event URLAccessedEvent) error
+}
+
+// URLAccessedEvent describes one redirect for PublishURLAccessed
+type URLAccessedEvent struct {
+	ShortCode   string
+	OriginalURL string
+	UserAgent   string
+	IPAddress   string
+	// Country is "" when it wasn't resolved
+	Country  string
+	Referrer string
+	// CampaignID is 0 for links outside a campaign
+	CampaignID int64
+	// Variant is "" for links without an A/B split
+	Variant string
 }
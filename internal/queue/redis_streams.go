@@ -201,8 +201,8 @@ func NewPublisher(queue MessageQueue, stream string) *Publisher {
 	}
 }
 
-// PublishURLCreated publishes a URL created event
-func (p *Publisher) PublishURLCreated(ctx context.Context, shortCode, originalURL, createdBy string) error {
+// PublishURLCreated publishes a URL created event, campaignID is 0 for links outside a campaign
+func (p *Publisher) PublishURLCreated(ctx context.Context, shortCode, originalURL, createdBy string, campaignID int64) error {
 	event := events.URLCreatedEventData{
 		BaseEvent: events.BaseEvent{
 			ID:        generateEventID(),
@@ -213,13 +213,14 @@ func (p *Publisher) PublishURLCreated(ctx context.Context, shortCode, originalUR
 		ShortCode:   shortCode,
 		OriginalURL: originalURL,
 		CreatedBy:   createdBy,
+		CampaignID:  campaignID,
 	}
 
 	return p.queue.Publish(ctx, p.stream, event)
 }
 
 // PublishURLAccessed publishes a URL accessed event
-func (p *Publisher) PublishURLAccessed(ctx context.Context, shortCode, originalURL, userAgent, ipAddress, referrer string) error {
+func (p *Publisher) PublishURLAccessed(ctx context.Context, accessed URLAccessedEvent) error {
 	event := events.URLAccessedEventData{
 		BaseEvent: events.BaseEvent{
 			ID:        generateEventID(),
@@ -227,11 +228,14 @@ func (p *Publisher) PublishURLAccessed(ctx context.Context, shortCode, originalU
 			Timestamp: time.Now(),
 			Source:    "gateway-service",
 		},
-		ShortCode:   shortCode,
-		OriginalURL: originalURL,
-		UserAgent:   userAgent,
-		IPAddress:   ipAddress,
-		Referrer:    referrer,
+		ShortCode:   accessed.ShortCode,
+		OriginalURL: accessed.OriginalURL,
+		UserAgent:   accessed.UserAgent,
+		IPAddress:   accessed.IPAddress,
+		Country:     accessed.Country,
+		Referrer:    accessed.Referrer,
+		CampaignID:  accessed.CampaignID,
+		Variant:     accessed.Variant,
 	}
 
 	return p.queue.Publish(ctx, p.stream, event)
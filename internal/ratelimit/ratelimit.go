@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Limit allows Requests per Window for each client
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// Validate reports whether the limit can be enforced, windows are counted in
+// whole milliseconds
+func (l Limit) Validate() error {
+	if l.Window < time.Millisecond {
+		return fmt.Errorf("rate limit window %s is shorter than 1ms", l.Window)
+	}
+	return nil
+}
+
+// Result describes the outcome of a single Allow call
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is when the current window ends
+	Reset time.Time
+	// RetryAfter is how long a rejected client should wait, zero when allowed
+	RetryAfter time.Duration
+}
+
+// RateLimiter decides whether a client may make another request under a policy
+type RateLimiter interface {
+	Allow(ctx context.Context, policy, key string, limit Limit) (Result, error)
+}
+
+// slidingWindow estimates the request count over the last window from the
+// current and previous fixed windows, weighting the previous one by how much
+// of it still overlaps. Only allowed requests are counted.
+var slidingWindow = redis.NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+local limit = tonumber(ARGV[1])
+local window_ms = tonumber(ARGV[2])
+local elapsed_ms = tonumber(ARGV[3])
+
+local count = math.floor(previous * (window_ms - elapsed_ms) / window_ms) + current
+if count >= limit then
+	return {0, 0}
+end
+
+redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], window_ms * 2)
+return {1, limit - count - 1}
+`)
+
+// Limiter is a sliding-window rate limiter whose counters live in Redis so
+// every gateway replica shares them.
+type Limiter struct {
+	client redis.Scripter
+	prefix string
+	now    func() time.Time
+}
+
+func NewLimiter(client redis.Scripter) *Limiter {
+	return &Limiter{
+		client: client,
+		prefix: "ratelimit",
+		now:    time.Now,
+	}
+}
+
+// Allow records a request for key under policy and reports whether it fits in limit
+func (l *Limiter) Allow(ctx context.Context, policy, key string, limit Limit) (Result, error) {
+	if err := limit.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	now := l.now()
+	windowMs := limit.Window.Milliseconds()
+	nowMs := now.UnixMilli()
+	window := nowMs / windowMs
+	elapsedMs := nowMs - window*windowMs
+	reset := time.UnixMilli((window + 1) * windowMs)
+
+	keys := []string{
+		l.windowKey(policy, key, window),
+		l.windowKey(policy, key, window-1),
+	}
+	values, err := slidingWindow.Run(ctx, l.client, keys, limit.Requests, windowMs, elapsedMs).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("rate limit check failed: %w", err)
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("rate limit check returned %d values", len(values))
+	}
+
+	result := Result{
+		Allowed:   values[0] == 1,
+		Limit:     limit.Requests,
+		Remaining: int(values[1]),
+		Reset:     reset,
+	}
+	if !result.Allowed {
+		result.RetryAfter = reset.Sub(now)
+	}
+	return result, nil
+}
+
+func (l *Limiter) windowKey(policy, key string, window int64) string {
+	return l.prefix + ":" + policy + ":" + key + ":" + strconv.FormatInt(window, 10)
+}
@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	// 15s into the window [60s, 120s)
+	now := time.UnixMilli(75_000)
+	limit := Limit{Requests: 10, Window: time.Minute}
+	keys := []string{"ratelimit:create:ip:1.2.3.4:1", "ratelimit:create:ip:1.2.3.4:0"}
+
+	tests := []struct {
+		name      string
+		mockSetup func(m redismock.ClientMock)
+		expected  Result
+		expectErr bool
+	}{
+		{
+			name: "allowed",
+			mockSetup: func(m redismock.ClientMock) {
+				m.ExpectEvalSha(slidingWindow.Hash(), keys, 10, int64(60_000), int64(15_000)).
+					SetVal([]interface{}{int64(1), int64(7)})
+			},
+			expected: Result{
+				Allowed:   true,
+				Limit:     10,
+				Remaining: 7,
+				Reset:     time.UnixMilli(120_000),
+			},
+		},
+		{
+			name: "rejected",
+			mockSetup: func(m redismock.ClientMock) {
+				m.ExpectEvalSha(slidingWindow.Hash(), keys, 10, int64(60_000), int64(15_000)).
+					SetVal([]interface{}{int64(0), int64(0)})
+			},
+			expected: Result{
+				Allowed:    false,
+				Limit:      10,
+				Remaining:  0,
+				Reset:      time.UnixMilli(120_000),
+				RetryAfter: 45 * time.Second,
+			},
+		},
+		{
+			name: "redis error",
+			mockSetup: func(m redismock.ClientMock) {
+				m.ExpectEvalSha(slidingWindow.Hash(), keys, 10, int64(60_000), int64(15_000)).
+					SetErr(errors.New("connection refused"))
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock := redismock.NewClientMock()
+			tc.mockSetup(mock)
+
+			limiter := NewLimiter(db)
+			limiter.now = func() time.Time { return now }
+
+			result, err := limiter.Allow(context.Background(), "create", "ip:1.2.3.4", limit)
+			if tc.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, result)
+			}
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestLimitValidate(t *testing.T) {
+	require.NoError(t, Limit{Requests: 10, Window: time.Minute}.Validate())
+	require.NoError(t, Limit{Requests: 10, Window: time.Millisecond}.Validate())
+	require.Error(t, Limit{Requests: 10, Window: time.Microsecond}.Validate())
+	require.Error(t, Limit{Requests: 10}.Validate())
+
+	// a sub-millisecond window would divide by zero, Allow refuses it
+	db, mock := redismock.NewClientMock()
+	_, err := NewLimiter(db).Allow(context.Background(), "create", "ip:1.2.3.4", Limit{Requests: 10, Window: time.Microsecond})
+	require.Error(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sammyqtran/url-shortener/internal/models"
+)
+
+type APIKeyRepository interface {
+	// Create stores a new API key
+	Create(ctx context.Context, key *models.APIKey) error
+
+	// GetActiveByHash retrieves a non-revoked API key by the hash of its secret
+	GetActiveByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+
+	// Revoke marks a user's API key as revoked
+	Revoke(ctx context.Context, id int64, userID string) error
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sammyqtran/url-shortener/internal/models"
+)
+
+type CampaignRepository interface {
+	// Create stores a new campaign
+	Create(ctx context.Context, campaign *models.Campaign) error
+
+	// GetByID retrieves a campaign of any user
+	GetByID(ctx context.Context, id int64) (*models.Campaign, error)
+
+	// ListByUser retrieves a user's campaigns, oldest first
+	ListByUser(ctx context.Context, userID string) ([]*models.Campaign, error)
+
+	// Update overwrites the name and UTM parameters of a campaign
+	Update(ctx context.Context, campaign *models.Campaign) error
+
+	// Delete removes a campaign, its links are taken out of it
+	Delete(ctx context.Context, id int64) error
+}
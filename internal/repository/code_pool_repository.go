@@ -0,0 +1,15 @@
+package repository
+
+import "context"
+
+// CodePoolRepository stores pre-generated short codes waiting to be handed out
+type CodePoolRepository interface {
+	// Add stores codes that aren't already pooled or in use and returns how many were added
+	Add(ctx context.Context, codes []string) (int64, error)
+
+	// Take removes and returns one pooled code, ErrCodePoolEmpty if there are none
+	Take(ctx context.Context) (string, error)
+
+	// Count returns the number of pooled codes
+	Count(ctx context.Context) (int64, error)
+}
@@ -3,8 +3,12 @@ package repository
 import "errors"
 
 var (
-	ErrURLNotFound     = errors.New("URL not found")
-	ErrShortCodeExists = errors.New("short code already exists")
-	ErrInvalidURL      = errors.New("invalid URL format")
-	ErrExpiredURL      = errors.New("URL has expired")
+	ErrURLNotFound      = errors.New("URL not found")
+	ErrShortCodeExists  = errors.New("short code already exists")
+	ErrInvalidURL       = errors.New("invalid URL format")
+	ErrExpiredURL       = errors.New("URL has expired")
+	ErrAPIKeyNotFound   = errors.New("API key not found")
+	ErrCodePoolEmpty    = errors.New("short code pool is empty")
+	ErrCampaignNotFound = errors.New("campaign not found")
+	ErrClicksExhausted  = errors.New("URL has no clicks left")
 )
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"github.com/sammyqtran/url-shortener/internal/models"
+	"github.com/sammyqtran/url-shortener/internal/repository"
+)
+
+type postgresAPIKeyRepository struct {
+	db     *sqlx.DB
+	logger *zap.Logger
+}
+
+// NewPostgresAPIKeyRepository creates a new PostgreSQL API key repository
+func NewPostgresAPIKeyRepository(db *sqlx.DB, logger *zap.Logger) repository.APIKeyRepository {
+	return &postgresAPIKeyRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *postgresAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	query := `
+        INSERT INTO api_keys (user_id, name, key_hash, prefix) 
+        VALUES ($1, $2, $3, $4) 
+        RETURNING id, created_at
+    `
+
+	err := r.db.QueryRowxContext(ctx, query, key.UserID, key.Name, key.KeyHash, key.Prefix).
+		Scan(&key.ID, &key.CreatedAt)
+
+	if err != nil {
+		r.logger.Error("Failed to create API key", zap.String("userID", key.UserID), zap.Error(err))
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresAPIKeyRepository) GetActiveByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	query := `
+        SELECT id, user_id, name, key_hash, prefix, created_at, revoked_at
+        FROM api_keys 
+        WHERE key_hash = $1 AND revoked_at IS NULL
+    `
+
+	err := r.db.GetContext(ctx, &key, query, keyHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, repository.ErrAPIKeyNotFound
+		}
+		r.logger.Error("Error retrieving API key", zap.Error(err))
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return &key, nil
+}
+
+func (r *postgresAPIKeyRepository) Revoke(ctx context.Context, id int64, userID string) error {
+	query := `
+        UPDATE api_keys 
+        SET revoked_at = CURRENT_TIMESTAMP
+        WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+    `
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		r.logger.Error("Error revoking API key", zap.Int64("id", id), zap.Error(err))
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error("Error getting affected rows", zap.Error(err))
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
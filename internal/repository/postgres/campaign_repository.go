@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"github.com/sammyqtran/url-shortener/internal/models"
+	"github.com/sammyqtran/url-shortener/internal/repository"
+)
+
+type postgresCampaignRepository struct {
+	db     *sqlx.DB
+	logger *zap.Logger
+}
+
+// NewPostgresCampaignRepository creates a new PostgreSQL campaign repository
+func NewPostgresCampaignRepository(db *sqlx.DB, logger *zap.Logger) repository.CampaignRepository {
+	return &postgresCampaignRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *postgresCampaignRepository) Create(ctx context.Context, campaign *models.Campaign) error {
+	query := `
+        INSERT INTO campaigns (user_id, name, utm_source, utm_medium, utm_campaign, utm_term, utm_content)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id, created_at, updated_at
+    `
+
+	err := r.db.QueryRowxContext(ctx, query, campaign.UserID, campaign.Name, campaign.UTMSource, campaign.UTMMedium,
+		campaign.UTMCampaign, campaign.UTMTerm, campaign.UTMContent).
+		Scan(&campaign.ID, &campaign.CreatedAt, &campaign.UpdatedAt)
+
+	if err != nil {
+		r.logger.Error("Failed to create campaign", zap.String("userID", campaign.UserID), zap.Error(err))
+		return fmt.Errorf("failed to create campaign: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresCampaignRepository) GetByID(ctx context.Context, id int64) (*models.Campaign, error) {
+	var campaign models.Campaign
+	query := `
+        SELECT id, user_id, name, utm_source, utm_medium, utm_campaign, utm_term, utm_content, created_at, updated_at
+        FROM campaigns
+        WHERE id = $1
+    `
+
+	err := r.db.GetContext(ctx, &campaign, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, repository.ErrCampaignNotFound
+		}
+		r.logger.Error("Error retrieving campaign", zap.Int64("id", id), zap.Error(err))
+		return nil, fmt.Errorf("failed to get campaign: %w", err)
+	}
+
+	return &campaign, nil
+}
+
+func (r *postgresCampaignRepository) ListByUser(ctx context.Context, userID string) ([]*models.Campaign, error) {
+	campaigns := []*models.Campaign{}
+	query := `
+        SELECT id, user_id, name, utm_source, utm_medium, utm_campaign, utm_term, utm_content, created_at, updated_at
+        FROM campaigns
+        WHERE user_id = $1
+        ORDER BY id
+    `
+
+	err := r.db.SelectContext(ctx, &campaigns, query, userID)
+	if err != nil {
+		r.logger.Error("Error listing campaigns", zap.String("userID", userID), zap.Error(err))
+		return nil, fmt.Errorf("failed to list campaigns: %w", err)
+	}
+
+	return campaigns, nil
+}
+
+func (r *postgresCampaignRepository) Update(ctx context.Context, campaign *models.Campaign) error {
+	query := `
+        UPDATE campaigns
+        SET name = $1, utm_source = $2, utm_medium = $3, utm_campaign = $4, utm_term = $5, utm_content = $6,
+            updated_at = CURRENT_TIMESTAMP
+        WHERE id = $7
+        RETURNING updated_at
+    `
+
+	err := r.db.QueryRowxContext(ctx, query, campaign.Name, campaign.UTMSource, campaign.UTMMedium,
+		campaign.UTMCampaign, campaign.UTMTerm, campaign.UTMContent, campaign.ID).
+		Scan(&campaign.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return repository.ErrCampaignNotFound
+		}
+		r.logger.Error("Error updating campaign", zap.Int64("id", campaign.ID), zap.Error(err))
+		return fmt.Errorf("failed to update campaign: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresCampaignRepository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM campaigns WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		r.logger.Error("Error deleting campaign", zap.Int64("id", id), zap.Error(err))
+		return fmt.Errorf("failed to delete campaign: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error("Error getting affected rows", zap.Error(err))
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrCampaignNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/sammyqtran/url-shortener/internal/repository"
+)
+
+type postgresCodePoolRepository struct {
+	db     *sqlx.DB
+	logger *zap.Logger
+}
+
+// NewPostgresCodePoolRepository creates a new PostgreSQL short code pool repository
+func NewPostgresCodePoolRepository(db *sqlx.DB, logger *zap.Logger) repository.CodePoolRepository {
+	return &postgresCodePoolRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *postgresCodePoolRepository) Add(ctx context.Context, codes []string) (int64, error) {
+	// codes already used by a link are dropped here so the pool only holds free ones
+	query := `
+        INSERT INTO code_pool (code)
+        SELECT c FROM unnest($1::text[]) AS c
+        WHERE NOT EXISTS (SELECT 1 FROM urls WHERE short_code = c)
+        ON CONFLICT (code) DO NOTHING
+    `
+
+	result, err := r.db.ExecContext(ctx, query, pq.Array(codes))
+	if err != nil {
+		r.logger.Error("Failed to add codes to pool", zap.Int("codes", len(codes)), zap.Error(err))
+		return 0, fmt.Errorf("failed to add codes to pool: %w", err)
+	}
+
+	added, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return added, nil
+}
+
+func (r *postgresCodePoolRepository) Take(ctx context.Context) (string, error) {
+	// SKIP LOCKED lets concurrent creates each claim a different row without waiting
+	query := `
+        DELETE FROM code_pool
+        WHERE code = (
+            SELECT code FROM code_pool
+            LIMIT 1
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING code
+    `
+
+	var code string
+	err := r.db.QueryRowxContext(ctx, query).Scan(&code)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", repository.ErrCodePoolEmpty
+		}
+		r.logger.Error("Failed to take code from pool", zap.Error(err))
+		return "", fmt.Errorf("failed to take code from pool: %w", err)
+	}
+
+	return code, nil
+}
+
+func (r *postgresCodePoolRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	query := `SELECT COUNT(*) FROM code_pool`
+
+	if err := r.db.GetContext(ctx, &count, query); err != nil {
+		r.logger.Error("Failed to count code pool", zap.Error(err))
+		return 0, fmt.Errorf("failed to count code pool: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/sammyqtran/url-shortener/internal/repository"
+)
+
+type postgresThreatListRepository struct {
+	db     *sqlx.DB
+	logger *zap.Logger
+}
+
+// NewPostgresThreatListRepository creates a new PostgreSQL threat list repository
+func NewPostgresThreatListRepository(db *sqlx.DB, logger *zap.Logger) repository.ThreatListRepository {
+	return &postgresThreatListRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const addThreatPrefixesQuery = `
+        INSERT INTO threat_hash_prefixes (prefix)
+        SELECT p FROM unnest($1::bytea[]) AS p
+        ON CONFLICT (prefix) DO NOTHING
+    `
+
+func (r *postgresThreatListRepository) Add(ctx context.Context, prefixes [][]byte) (int64, error) {
+	result, err := r.db.ExecContext(ctx, addThreatPrefixesQuery, pq.ByteaArray(prefixes))
+	if err != nil {
+		r.logger.Error("Failed to add threat list prefixes", zap.Int("prefixes", len(prefixes)), zap.Error(err))
+		return 0, fmt.Errorf("failed to add threat list prefixes: %w", err)
+	}
+
+	added, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return added, nil
+}
+
+func (r *postgresThreatListRepository) Remove(ctx context.Context, prefixes [][]byte) (int64, error) {
+	query := `DELETE FROM threat_hash_prefixes WHERE prefix = ANY($1::bytea[])`
+
+	result, err := r.db.ExecContext(ctx, query, pq.ByteaArray(prefixes))
+	if err != nil {
+		r.logger.Error("Failed to remove threat list prefixes", zap.Int("prefixes", len(prefixes)), zap.Error(err))
+		return 0, fmt.Errorf("failed to remove threat list prefixes: %w", err)
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return removed, nil
+}
+
+func (r *postgresThreatListRepository) Replace(ctx context.Context, prefixes [][]byte) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM threat_hash_prefixes`); err != nil {
+		r.logger.Error("Failed to clear threat list", zap.Error(err))
+		return 0, fmt.Errorf("failed to clear threat list: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, addThreatPrefixesQuery, pq.ByteaArray(prefixes))
+	if err != nil {
+		r.logger.Error("Failed to replace threat list", zap.Int("prefixes", len(prefixes)), zap.Error(err))
+		return 0, fmt.Errorf("failed to replace threat list: %w", err)
+	}
+
+	stored, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit threat list: %w", err)
+	}
+
+	return stored, nil
+}
+
+func (r *postgresThreatListRepository) List(ctx context.Context) ([][]byte, error) {
+	var prefixes [][]byte
+	query := `SELECT prefix FROM threat_hash_prefixes`
+
+	if err := r.db.SelectContext(ctx, &prefixes, query); err != nil {
+		r.logger.Error("Failed to list threat list prefixes", zap.Error(err))
+		return nil, fmt.Errorf("failed to list threat list prefixes: %w", err)
+	}
+
+	return prefixes, nil
+}
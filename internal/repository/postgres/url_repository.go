@@ -3,16 +3,23 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 
 	"github.com/sammyqtran/url-shortener/internal/models"
 	"github.com/sammyqtran/url-shortener/internal/repository"
 )
 
+// uniqueViolation is the Postgres error code for a unique index conflict
+const uniqueViolation = "23505"
+
+// shortCodeConstraint is the name Postgres gives the UNIQUE on urls.short_code
+const shortCodeConstraint = "urls_short_code_key"
+
 type postgresURLRepository struct {
 	db     *sqlx.DB
 	logger *zap.Logger
@@ -28,15 +35,18 @@ func NewPostgresURLRepository(db *sqlx.DB, logger *zap.Logger) repository.URLRep
 
 func (r *postgresURLRepository) Create(ctx context.Context, url *models.URL) error {
 	query := `
-        INSERT INTO urls (user_id, short_code, original_url, expires_at) 
-        VALUES ($1, $2, $3, $4) 
+        INSERT INTO urls (user_id, short_code, original_url, expires_at, url_hash, interstitial, redirect_type, query_passthrough, path_passthrough, campaign_id, geo_routes, device_routes, variants, active_from, schedule, max_clicks) 
+        VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) 
         RETURNING id, created_at, updated_at, click_count
     `
 
-	err := r.db.QueryRowxContext(ctx, query, url.UserID, url.ShortCode, url.OriginalURL, url.ExpiresAt).
+	err := r.db.QueryRowxContext(ctx, query, url.UserID, url.ShortCode, url.OriginalURL, url.ExpiresAt, url.URLHash, url.Interstitial, url.RedirectType, url.QueryPassthrough, url.PathPassthrough, url.CampaignID, url.GeoRoutes, url.DeviceRoutes, url.Variants, url.ActiveFrom, url.Schedule, url.MaxClicks).
 		Scan(&url.ID, &url.CreatedAt, &url.UpdatedAt, &url.ClickCount)
 
 	if err != nil {
+		if isShortCodeConflict(err) {
+			return repository.ErrShortCodeExists
+		}
 		r.logger.Error("Failed to create row in database", zap.Error(err))
 		return fmt.Errorf("failed to create URL: %w", err)
 	}
@@ -44,12 +54,18 @@ func (r *postgresURLRepository) Create(ctx context.Context, url *models.URL) err
 	return nil
 }
 
+// isShortCodeConflict reports whether err is a unique violation on short_code
+func isShortCodeConflict(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == shortCodeConstraint
+}
+
 func (r *postgresURLRepository) GetByShortCode(ctx context.Context, shortCode string) (*models.URL, error) {
 	var url models.URL
 	query := `
-        SELECT id, short_code, original_url, created_at, updated_at, click_count, expires_at
+        SELECT id, user_id, short_code, original_url, created_at, updated_at, click_count, expires_at, quarantined_at, interstitial, redirect_type, query_passthrough, path_passthrough, campaign_id, geo_routes, device_routes, variants, active_from, schedule, max_clicks
         FROM urls 
-        WHERE short_code = $1 AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+        WHERE short_code = $1
     `
 
 	err := r.db.GetContext(ctx, &url, query, shortCode)
@@ -64,10 +80,33 @@ func (r *postgresURLRepository) GetByShortCode(ctx context.Context, shortCode st
 	return &url, nil
 }
 
+func (r *postgresURLRepository) GetLiveByURLHash(ctx context.Context, userID, urlHash string) (*models.URL, error) {
+	var url models.URL
+	query := `
+        SELECT id, user_id, short_code, original_url, created_at, updated_at, click_count, expires_at, quarantined_at, interstitial, redirect_type, query_passthrough, path_passthrough, campaign_id, geo_routes, device_routes, variants, active_from, schedule, max_clicks
+        FROM urls 
+        WHERE user_id = $1 AND url_hash = $2
+          AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+        ORDER BY created_at DESC
+        LIMIT 1
+    `
+
+	err := r.db.GetContext(ctx, &url, query, userID, urlHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, repository.ErrURLNotFound
+		}
+		r.logger.Error("Error retrieving URL by hash", zap.String("userID", userID), zap.Error(err))
+		return nil, fmt.Errorf("failed to get URL by hash: %w", err)
+	}
+
+	return &url, nil
+}
+
 func (r *postgresURLRepository) GetByID(ctx context.Context, id int64) (*models.URL, error) {
 	var url models.URL
 	query := `
-        SELECT id, short_code, original_url, created_at, updated_at, click_count, expires_at
+        SELECT id, user_id, short_code, original_url, created_at, updated_at, click_count, expires_at, quarantined_at, interstitial, redirect_type, query_passthrough, path_passthrough, campaign_id, geo_routes, device_routes, variants, active_from, schedule, max_clicks
         FROM urls 
         WHERE id = $1
     `
@@ -84,14 +123,18 @@ func (r *postgresURLRepository) GetByID(ctx context.Context, id int64) (*models.
 	return &url, nil
 }
 
+// Update doesn't write quarantined_at, only SetQuarantined does, so a scan
+// that quarantines the link between the read and this write isn't undone
 func (r *postgresURLRepository) Update(ctx context.Context, url *models.URL) error {
 	query := `
         UPDATE urls 
-        SET original_url = $1, expires_at = $2, updated_at = CURRENT_TIMESTAMP
-        WHERE short_code = $3
+        SET original_url = $1, expires_at = $2, url_hash = NULLIF($3, ''), interstitial = $4, redirect_type = $5,
+            query_passthrough = $6, path_passthrough = $7, campaign_id = $8, geo_routes = $9, device_routes = $10, variants = $11,
+            active_from = $12, schedule = $13, max_clicks = $14, updated_at = CURRENT_TIMESTAMP
+        WHERE short_code = $15
     `
 
-	result, err := r.db.ExecContext(ctx, query, url.OriginalURL, url.ExpiresAt, url.ShortCode)
+	result, err := r.db.ExecContext(ctx, query, url.OriginalURL, url.ExpiresAt, url.URLHash, url.Interstitial, url.RedirectType, url.QueryPassthrough, url.PathPassthrough, url.CampaignID, url.GeoRoutes, url.DeviceRoutes, url.Variants, url.ActiveFrom, url.Schedule, url.MaxClicks, url.ShortCode)
 	if err != nil {
 		r.logger.Error("Error updating URL", zap.Error(err))
 		return fmt.Errorf("failed to update URL: %w", err)
@@ -158,6 +201,29 @@ func (r *postgresURLRepository) IncrementClickCount(ctx context.Context, shortCo
 	return nil
 }
 
+func (r *postgresURLRepository) ClaimClick(ctx context.Context, shortCode string) error {
+	// the row lock taken by UPDATE makes concurrent claims wait and re-check the limit
+	query := `
+        UPDATE urls 
+        SET click_count = click_count + 1, updated_at = CURRENT_TIMESTAMP
+        WHERE short_code = $1 AND (max_clicks IS NULL OR click_count < max_clicks)
+        RETURNING click_count
+    `
+
+	var clickCount int64
+	err := r.db.GetContext(ctx, &clickCount, query, shortCode)
+	if err != nil {
+		// callers have just loaded the link, so no row means no clicks left
+		if err == sql.ErrNoRows {
+			return repository.ErrClicksExhausted
+		}
+		r.logger.Error("Error claiming click", zap.String("shortCode", shortCode), zap.Error(err))
+		return fmt.Errorf("failed to claim click: %w", err)
+	}
+
+	return nil
+}
+
 func (r *postgresURLRepository) GetStats(ctx context.Context, shortCode string) (*models.URL, error) {
 	return r.GetByShortCode(ctx, shortCode)
 }
@@ -165,7 +231,7 @@ func (r *postgresURLRepository) GetStats(ctx context.Context, shortCode string)
 func (r *postgresURLRepository) ListURLs(ctx context.Context, limit, offset int) ([]*models.URL, error) {
 	var urls []*models.URL
 	query := `
-        SELECT id, short_code, original_url, created_at, updated_at, click_count, expires_at
+        SELECT id, user_id, short_code, original_url, created_at, updated_at, click_count, expires_at, quarantined_at, interstitial, redirect_type, query_passthrough, path_passthrough, campaign_id, geo_routes, device_routes, variants, active_from, schedule, max_clicks
         FROM urls 
         ORDER BY created_at DESC
         LIMIT $1 OFFSET $2
@@ -180,6 +246,59 @@ func (r *postgresURLRepository) ListURLs(ctx context.Context, limit, offset int)
 	return urls, nil
 }
 
+func (r *postgresURLRepository) ListURLsByUser(ctx context.Context, userID string, limit, offset int) ([]*models.URL, error) {
+	var urls []*models.URL
+	query := `
+        SELECT id, user_id, short_code, original_url, created_at, updated_at, click_count, expires_at, quarantined_at, interstitial, redirect_type, query_passthrough, path_passthrough, campaign_id, geo_routes, device_routes, variants, active_from, schedule, max_clicks
+        FROM urls 
+        WHERE user_id = $1
+        ORDER BY created_at DESC
+        LIMIT $2 OFFSET $3
+    `
+
+	err := r.db.SelectContext(ctx, &urls, query, userID, limit, offset)
+	if err != nil {
+		r.logger.Error("Error getting rows for user", zap.String("userID", userID), zap.Error(err))
+		return nil, fmt.Errorf("failed to list URLs for user: %w", err)
+	}
+
+	return urls, nil
+}
+
+func (r *postgresURLRepository) ListAfterID(ctx context.Context, afterID int64, limit int) ([]*models.URL, error) {
+	var urls []*models.URL
+	query := `
+        SELECT id, user_id, short_code, original_url, created_at, updated_at, click_count, expires_at, quarantined_at, interstitial, redirect_type, query_passthrough, path_passthrough, campaign_id, geo_routes, device_routes, variants, active_from, schedule, max_clicks
+        FROM urls 
+        WHERE id > $1
+        ORDER BY id
+        LIMIT $2
+    `
+
+	err := r.db.SelectContext(ctx, &urls, query, afterID, limit)
+	if err != nil {
+		r.logger.Error("Error getting rows after ID", zap.Int64("afterID", afterID), zap.Error(err))
+		return nil, fmt.Errorf("failed to list URLs after ID: %w", err)
+	}
+
+	return urls, nil
+}
+
+func (r *postgresURLRepository) SetQuarantined(ctx context.Context, ids []int64, quarantined bool) error {
+	// quarantined links keep their original timestamp
+	query := `UPDATE urls SET quarantined_at = COALESCE(quarantined_at, CURRENT_TIMESTAMP) WHERE id = ANY($1)`
+	if !quarantined {
+		query = `UPDATE urls SET quarantined_at = NULL WHERE id = ANY($1)`
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, pq.Array(ids)); err != nil {
+		r.logger.Error("Error updating quarantine", zap.Int("urls", len(ids)), zap.Bool("quarantined", quarantined), zap.Error(err))
+		return fmt.Errorf("failed to update quarantine: %w", err)
+	}
+
+	return nil
+}
+
 func (r *postgresURLRepository) IsShortCodeExists(ctx context.Context, shortCode string) (bool, error) {
 	var exists bool
 	query := `SELECT EXISTS(SELECT 1 FROM urls WHERE short_code = $1)`
@@ -192,3 +311,16 @@ func (r *postgresURLRepository) IsShortCodeExists(ctx context.Context, shortCode
 
 	return exists, nil
 }
+
+func (r *postgresURLRepository) NextShortCodeID(ctx context.Context) (int64, error) {
+	var id int64
+	query := `SELECT nextval('short_code_seq')`
+
+	err := r.db.GetContext(ctx, &id, query)
+	if err != nil {
+		r.logger.Error("Error getting next short code ID", zap.Error(err))
+		return 0, fmt.Errorf("failed to get next short code ID: %w", err)
+	}
+
+	return id, nil
+}
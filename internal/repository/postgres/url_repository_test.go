@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/sammyqtran/url-shortener/internal/models"
+)
+
+func TestUpdate_KeepsQuarantine(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostgresURLRepository(sqlx.NewDb(db, "postgres"), zap.NewNop())
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE urls SET quarantined_at = COALESCE(quarantined_at, CURRENT_TIMESTAMP) WHERE id = ANY($1)`)).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.SetQuarantined(context.Background(), []int64{1}, true))
+
+	// the link was read before the scan quarantined it, so it still looks clean
+	mock.ExpectExec(`UPDATE urls\s+SET original_url = \$1, expires_at = \$2, url_hash = NULLIF\(\$3, ''\), interstitial = \$4,`).
+		WithArgs("https://example.com/", nil, "hash", true, 0, "", false, nil, nil, nil, nil, nil, nil, nil, "abc123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	err = repo.Update(context.Background(), &models.URL{
+		ID:           1,
+		ShortCode:    "abc123",
+		OriginalURL:  "https://example.com/",
+		URLHash:      "hash",
+		Interstitial: true,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,18 @@
+package repository
+
+import "context"
+
+// ThreatListRepository stores SHA-256 hash prefixes of unsafe URL expressions
+type ThreatListRepository interface {
+	// Add stores prefixes that aren't listed yet and returns how many were added
+	Add(ctx context.Context, prefixes [][]byte) (int64, error)
+
+	// Remove deletes prefixes and returns how many were listed
+	Remove(ctx context.Context, prefixes [][]byte) (int64, error)
+
+	// Replace swaps the whole list for prefixes and returns how many were stored
+	Replace(ctx context.Context, prefixes [][]byte) (int64, error)
+
+	// List returns every stored prefix
+	List(ctx context.Context) ([][]byte, error)
+}
@@ -13,10 +13,13 @@ type URLRepository interface {
 	// GetByShortCode retrieves URL by short code
 	GetByShortCode(ctx context.Context, shortCode string) (*models.URL, error)
 
+	// GetLiveByURLHash retrieves a user's newest non-expired URL with the given destination hash
+	GetLiveByURLHash(ctx context.Context, userID, urlHash string) (*models.URL, error)
+
 	// GetByID retrieves URL by ID
 	GetByID(ctx context.Context, id int64) (*models.URL, error)
 
-	// Update modifies an existing URL
+	// Update modifies an existing URL, apart from its quarantine
 	Update(ctx context.Context, url *models.URL) error
 
 	// Delete removes a URL by short code
@@ -25,12 +28,29 @@ type URLRepository interface {
 	// IncrementClickCount increments the click counter
 	IncrementClickCount(ctx context.Context, shortCode string) error
 
+	// ClaimClick counts a click on a click-limited URL if it has one left,
+	// ErrClicksExhausted if it doesn't. Concurrent claims can't both take
+	// the last click.
+	ClaimClick(ctx context.Context, shortCode string) error
+
 	// GetStats returns URL statistics
 	GetStats(ctx context.Context, shortCode string) (*models.URL, error)
 
 	// ListURLs returns paginated list of URLs
 	ListURLs(ctx context.Context, limit, offset int) ([]*models.URL, error)
 
+	// ListURLsByUser returns paginated list of URLs owned by a user
+	ListURLsByUser(ctx context.Context, userID string, limit, offset int) ([]*models.URL, error)
+
+	// ListAfterID returns up to limit URLs with an ID above afterID, in ID order
+	ListAfterID(ctx context.Context, afterID int64, limit int) ([]*models.URL, error)
+
+	// SetQuarantined sets or clears quarantined_at on the URLs with the given IDs
+	SetQuarantined(ctx context.Context, ids []int64, quarantined bool) error
+
 	// IsShortCodeExists checks if short code already exists
 	IsShortCodeExists(ctx context.Context, shortCode string) (bool, error)
+
+	// NextShortCodeID returns the next value of the short code counter
+	NextShortCodeID(ctx context.Context) (int64, error)
 }
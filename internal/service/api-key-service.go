@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sammyqtran/url-shortener/internal/metrics"
+	"github.com/sammyqtran/url-shortener/internal/models"
+	"github.com/sammyqtran/url-shortener/internal/repository"
+	pb "github.com/sammyqtran/url-shortener/proto"
+)
+
+// API keys look like "usk_" followed by apiKeySecretLength charset characters
+const (
+	apiKeyPrefix       = "usk_"
+	apiKeySecretLength = 40
+	apiKeyDisplayChars = 12
+)
+
+type APIKeyService struct {
+	pb.UnimplementedAPIKeyServiceServer
+	repo    repository.APIKeyRepository
+	Logger  *zap.Logger
+	Metrics metrics.Metrics
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepository, logger *zap.Logger, metrics *metrics.PrometheusMetrics) *APIKeyService {
+	return &APIKeyService{
+		repo:    repo,
+		Logger:  logger,
+		Metrics: metrics,
+	}
+}
+
+func (s *APIKeyService) IssueAPIKey(ctx context.Context, req *pb.IssueAPIKeyRequest) (*pb.IssueAPIKeyResponse, error) {
+	service := "url-service"
+
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id cannot be empty")
+	}
+
+	apiKey, err := generateAPIKey()
+	if err != nil {
+		s.Logger.Error("Failed to generate API key", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to generate API key: %v", err)
+	}
+
+	key := &models.APIKey{
+		UserID:  req.UserId,
+		Name:    req.Name,
+		KeyHash: hashAPIKey(apiKey),
+		Prefix:  apiKey[:apiKeyDisplayChars],
+	}
+
+	s.Metrics.IncDBOperation(service, "CreateAPIKey")
+	dbTimer := time.Now()
+	err = s.repo.Create(ctx, key)
+	s.Metrics.ObserveDBOperationDuration(service, "CreateAPIKey", time.Since(dbTimer).Seconds())
+	if err != nil {
+		s.Metrics.IncDBError(service, "CreateAPIKey")
+		s.Logger.Error("Failed to store API key", zap.String("userID", req.UserId), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to store API key: %v", err)
+	}
+
+	s.Logger.Info("Issued API key", zap.String("userID", req.UserId), zap.Int64("keyID", key.ID), zap.String("prefix", key.Prefix))
+
+	return &pb.IssueAPIKeyResponse{
+		KeyId:  key.ID,
+		ApiKey: apiKey,
+		Prefix: key.Prefix,
+	}, nil
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, req *pb.RevokeAPIKeyRequest) (*pb.RevokeAPIKeyResponse, error) {
+	service := "url-service"
+
+	if req.KeyId == 0 || req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "key_id and user_id are required")
+	}
+
+	s.Metrics.IncDBOperation(service, "RevokeAPIKey")
+	dbTimer := time.Now()
+	err := s.repo.Revoke(ctx, req.KeyId, req.UserId)
+	s.Metrics.ObserveDBOperationDuration(service, "RevokeAPIKey", time.Since(dbTimer).Seconds())
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, status.Error(codes.NotFound, "API key not found")
+		}
+		s.Metrics.IncDBError(service, "RevokeAPIKey")
+		s.Logger.Error("Failed to revoke API key", zap.Int64("keyID", req.KeyId), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to revoke API key: %v", err)
+	}
+
+	s.Logger.Info("Revoked API key", zap.String("userID", req.UserId), zap.Int64("keyID", req.KeyId))
+
+	return &pb.RevokeAPIKeyResponse{Success: true}, nil
+}
+
+func (s *APIKeyService) ValidateAPIKey(ctx context.Context, req *pb.ValidateAPIKeyRequest) (*pb.ValidateAPIKeyResponse, error) {
+	service := "url-service"
+
+	// malformed keys can't exist in the table, skip the lookup
+	if !strings.HasPrefix(req.ApiKey, apiKeyPrefix) || len(req.ApiKey) != len(apiKeyPrefix)+apiKeySecretLength {
+		return &pb.ValidateAPIKeyResponse{Valid: false}, nil
+	}
+
+	s.Metrics.IncDBOperation(service, "GetActiveAPIKey")
+	dbTimer := time.Now()
+	key, err := s.repo.GetActiveByHash(ctx, hashAPIKey(req.ApiKey))
+	s.Metrics.ObserveDBOperationDuration(service, "GetActiveAPIKey", time.Since(dbTimer).Seconds())
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return &pb.ValidateAPIKeyResponse{Valid: false}, nil
+		}
+		s.Metrics.IncDBError(service, "GetActiveAPIKey")
+		s.Logger.Error("Failed to validate API key", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to validate API key: %v", err)
+	}
+
+	return &pb.ValidateAPIKeyResponse{
+		Valid:  true,
+		UserId: key.UserID,
+		KeyId:  key.ID,
+	}, nil
+}
+
+// generateAPIKey returns a new random API key using crypto/rand
+func generateAPIKey() (string, error) {
+	secret, err := randomString(charset, apiKeySecretLength)
+	if err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + secret, nil
+}
+
+// hashAPIKey returns the hex SHA-256 of a key, the only form that is stored
+func hashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sammyqtran/url-shortener/internal/metrics"
+	"github.com/sammyqtran/url-shortener/internal/models"
+	"github.com/sammyqtran/url-shortener/internal/repository"
+	pb "github.com/sammyqtran/url-shortener/proto"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type MockAPIKeyRepo struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepo) Create(ctx context.Context, key *models.APIKey) error {
+	args := m.Called(ctx, key)
+	key.ID = 1
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepo) GetActiveByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+
+	if key, ok := args.Get(0).(*models.APIKey); ok {
+		return key, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAPIKeyRepo) Revoke(ctx context.Context, id int64, userID string) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+func newTestAPIKeyService(repo repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{
+		repo:    repo,
+		Logger:  zap.NewNop(),
+		Metrics: &metrics.NoopMetrics{},
+	}
+}
+
+func TestIssueAPIKey(t *testing.T) {
+	repo := new(MockAPIKeyRepo)
+	service := newTestAPIKeyService(repo)
+
+	var stored *models.APIKey
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*models.APIKey")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*models.APIKey) }).
+		Return(nil)
+
+	resp, err := service.IssueAPIKey(context.Background(), &pb.IssueAPIKeyRequest{UserId: "user123", Name: "ci-bot"})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(resp.ApiKey, apiKeyPrefix))
+	require.Len(t, resp.ApiKey, len(apiKeyPrefix)+apiKeySecretLength)
+	require.Equal(t, int64(1), resp.KeyId)
+
+	// only the hash is persisted
+	require.Equal(t, hashAPIKey(resp.ApiKey), stored.KeyHash)
+	require.NotContains(t, stored.KeyHash, resp.ApiKey)
+	require.Equal(t, resp.Prefix, stored.Prefix)
+	require.True(t, strings.HasPrefix(resp.ApiKey, stored.Prefix))
+	require.Equal(t, "user123", stored.UserID)
+
+	_, err = service.IssueAPIKey(context.Background(), &pb.IssueAPIKeyRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGenerateAPIKeyUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		key, err := generateAPIKey()
+		require.NoError(t, err)
+		require.False(t, seen[key], "duplicate key generated")
+		seen[key] = true
+	}
+}
+
+func TestValidateAPIKey(t *testing.T) {
+	validKey, err := generateAPIKey()
+	require.NoError(t, err)
+	unknownKey, err := generateAPIKey()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		apiKey     string
+		mockSetup  func(m *MockAPIKeyRepo)
+		expectErr  codes.Code
+		expectResp *pb.ValidateAPIKeyResponse
+	}{
+		{
+			name:   "valid key",
+			apiKey: validKey,
+			mockSetup: func(m *MockAPIKeyRepo) {
+				m.On("GetActiveByHash", mock.Anything, hashAPIKey(validKey)).
+					Return(&models.APIKey{ID: 3, UserID: "user123"}, nil)
+			},
+			expectResp: &pb.ValidateAPIKeyResponse{Valid: true, UserId: "user123", KeyId: 3},
+		},
+		{
+			name:   "unknown or revoked key",
+			apiKey: unknownKey,
+			mockSetup: func(m *MockAPIKeyRepo) {
+				m.On("GetActiveByHash", mock.Anything, hashAPIKey(unknownKey)).
+					Return(nil, repository.ErrAPIKeyNotFound)
+			},
+			expectResp: &pb.ValidateAPIKeyResponse{Valid: false},
+		},
+		{
+			name:       "malformed key skips lookup",
+			apiKey:     "not-a-key",
+			mockSetup:  func(m *MockAPIKeyRepo) {},
+			expectResp: &pb.ValidateAPIKeyResponse{Valid: false},
+		},
+		{
+			name:   "repository failure",
+			apiKey: validKey,
+			mockSetup: func(m *MockAPIKeyRepo) {
+				m.On("GetActiveByHash", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("connection reset"))
+			},
+			expectErr: codes.Internal,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := new(MockAPIKeyRepo)
+			tc.mockSetup(repo)
+			service := newTestAPIKeyService(repo)
+
+			resp, err := service.ValidateAPIKey(context.Background(), &pb.ValidateAPIKeyRequest{ApiKey: tc.apiKey})
+			if tc.expectErr != codes.OK {
+				require.Equal(t, tc.expectErr, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectResp.Valid, resp.Valid)
+			require.Equal(t, tc.expectResp.UserId, resp.UserId)
+			require.Equal(t, tc.expectResp.KeyId, resp.KeyId)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	repo := new(MockAPIKeyRepo)
+	service := newTestAPIKeyService(repo)
+
+	repo.On("Revoke", mock.Anything, int64(3), "user123").Return(nil)
+	repo.On("Revoke", mock.Anything, int64(3), "intruder").Return(repository.ErrAPIKeyNotFound)
+
+	resp, err := service.RevokeAPIKey(context.Background(), &pb.RevokeAPIKeyRequest{KeyId: 3, UserId: "user123"})
+	require.NoError(t, err)
+	require.True(t, resp.Success)
+
+	_, err = service.RevokeAPIKey(context.Background(), &pb.RevokeAPIKeyRequest{KeyId: 3, UserId: "intruder"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = service.RevokeAPIKey(context.Background(), &pb.RevokeAPIKeyRequest{UserId: "user123"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sammyqtran/url-shortener/internal/metrics"
+	"github.com/sammyqtran/url-shortener/internal/models"
+	"github.com/sammyqtran/url-shortener/internal/passthrough"
+	"github.com/sammyqtran/url-shortener/internal/repository"
+	pb "github.com/sammyqtran/url-shortener/proto"
+)
+
+// maxCampaignFieldLength bounds names and UTM values, it must fit the campaigns columns
+const maxCampaignFieldLength = 255
+
+// CampaignService manages UTM campaigns. URLService shares it to check the
+// campaign of new links and to look campaigns up on redirect.
+type CampaignService struct {
+	pb.UnimplementedCampaignServiceServer
+	repo    repository.CampaignRepository
+	cache   *redis.Client
+	Logger  *zap.Logger
+	Metrics metrics.Metrics
+}
+
+func NewCampaignService(repo repository.CampaignRepository, cache *redis.Client, logger *zap.Logger, metrics metrics.Metrics) *CampaignService {
+	return &CampaignService{
+		repo:    repo,
+		cache:   cache,
+		Logger:  logger,
+		Metrics: metrics,
+	}
+}
+
+func (s *CampaignService) CreateCampaign(ctx context.Context, req *pb.CreateCampaignRequest) (*pb.CreateCampaignResponse, error) {
+	service := "url-service"
+
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id cannot be empty")
+	}
+
+	campaign := &models.Campaign{
+		UserID:      req.UserId,
+		Name:        req.Name,
+		UTMSource:   req.UtmSource,
+		UTMMedium:   req.UtmMedium,
+		UTMCampaign: req.UtmCampaign,
+		UTMTerm:     req.UtmTerm,
+		UTMContent:  req.UtmContent,
+	}
+	if err := validateCampaign(campaign); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid campaign: %v", err)
+	}
+
+	s.Metrics.IncDBOperation(service, "CreateCampaign")
+	dbTimer := time.Now()
+	err := s.repo.Create(ctx, campaign)
+	s.Metrics.ObserveDBOperationDuration(service, "CreateCampaign", time.Since(dbTimer).Seconds())
+	if err != nil {
+		s.Metrics.IncDBError(service, "CreateCampaign")
+		s.Logger.Error("Failed to create campaign", zap.String("userID", req.UserId), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to create campaign: %v", err)
+	}
+
+	s.Logger.Info("Created campaign", zap.String("userID", req.UserId), zap.Int64("campaignID", campaign.ID))
+
+	return &pb.CreateCampaignResponse{Campaign: toCampaignProto(campaign)}, nil
+}
+
+func (s *CampaignService) GetCampaign(ctx context.Context, req *pb.GetCampaignRequest) (*pb.GetCampaignResponse, error) {
+	campaign, err := s.getOwnedCampaign(ctx, req.Id, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetCampaignResponse{Campaign: toCampaignProto(campaign)}, nil
+}
+
+func (s *CampaignService) ListCampaigns(ctx context.Context, req *pb.ListCampaignsRequest) (*pb.ListCampaignsResponse, error) {
+	service := "url-service"
+
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id cannot be empty")
+	}
+
+	s.Metrics.IncDBOperation(service, "ListCampaigns")
+	dbTimer := time.Now()
+	campaigns, err := s.repo.ListByUser(ctx, req.UserId)
+	s.Metrics.ObserveDBOperationDuration(service, "ListCampaigns", time.Since(dbTimer).Seconds())
+	if err != nil {
+		s.Metrics.IncDBError(service, "ListCampaigns")
+		s.Logger.Error("Failed to list campaigns", zap.String("userID", req.UserId), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to list campaigns: %v", err)
+	}
+
+	resp := &pb.ListCampaignsResponse{Campaigns: make([]*pb.Campaign, 0, len(campaigns))}
+	for _, campaign := range campaigns {
+		resp.Campaigns = append(resp.Campaigns, toCampaignProto(campaign))
+	}
+	return resp, nil
+}
+
+func (s *CampaignService) UpdateCampaign(ctx context.Context, req *pb.UpdateCampaignRequest) (*pb.UpdateCampaignResponse, error) {
+	service := "url-service"
+
+	campaign, err := s.getOwnedCampaign(ctx, req.Id, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range []struct {
+		value  *string
+		target *string
+	}{
+		{req.Name, &campaign.Name},
+		{req.UtmSource, &campaign.UTMSource},
+		{req.UtmMedium, &campaign.UTMMedium},
+		{req.UtmCampaign, &campaign.UTMCampaign},
+		{req.UtmTerm, &campaign.UTMTerm},
+		{req.UtmContent, &campaign.UTMContent},
+	} {
+		if field.value != nil {
+			*field.target = *field.value
+		}
+	}
+	if err := validateCampaign(campaign); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid campaign: %v", err)
+	}
+
+	s.Metrics.IncDBOperation(service, "UpdateCampaign")
+	dbTimer := time.Now()
+	err = s.repo.Update(ctx, campaign)
+	s.Metrics.ObserveDBOperationDuration(service, "UpdateCampaign", time.Since(dbTimer).Seconds())
+	if err != nil {
+		s.Metrics.IncDBError(service, "UpdateCampaign")
+		if errors.Is(err, repository.ErrCampaignNotFound) {
+			return nil, status.Error(codes.NotFound, "campaign not found")
+		}
+		s.Logger.Error("Failed to update campaign", zap.Int64("campaignID", req.Id), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to update campaign: %v", err)
+	}
+
+	// redirects pick up the new parameters on their next lookup
+	s.removeFromCache(ctx, campaign.ID)
+
+	return &pb.UpdateCampaignResponse{Campaign: toCampaignProto(campaign)}, nil
+}
+
+func (s *CampaignService) DeleteCampaign(ctx context.Context, req *pb.DeleteCampaignRequest) (*pb.DeleteCampaignResponse, error) {
+	service := "url-service"
+
+	if _, err := s.getOwnedCampaign(ctx, req.Id, req.UserId); err != nil {
+		return nil, err
+	}
+
+	s.Metrics.IncDBOperation(service, "DeleteCampaign")
+	dbTimer := time.Now()
+	err := s.repo.Delete(ctx, req.Id)
+	s.Metrics.ObserveDBOperationDuration(service, "DeleteCampaign", time.Since(dbTimer).Seconds())
+	if err != nil {
+		s.Metrics.IncDBError(service, "DeleteCampaign")
+		if errors.Is(err, repository.ErrCampaignNotFound) {
+			return nil, status.Error(codes.NotFound, "campaign not found")
+		}
+		s.Logger.Error("Failed to delete campaign", zap.Int64("campaignID", req.Id), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to delete campaign: %v", err)
+	}
+
+	s.removeFromCache(ctx, req.Id)
+	s.Logger.Info("Deleted campaign", zap.String("userID", req.UserId), zap.Int64("campaignID", req.Id))
+
+	return &pb.DeleteCampaignResponse{Success: true}, nil
+}
+
+// getOwnedCampaign loads a campaign and checks it belongs to userID.
+// Errors are already gRPC statuses.
+func (s *CampaignService) getOwnedCampaign(ctx context.Context, id int64, userID string) (*models.Campaign, error) {
+	service := "url-service"
+
+	if id == 0 || userID == "" {
+		return nil, status.Error(codes.InvalidArgument, "campaign id and user_id are required")
+	}
+
+	s.Metrics.IncDBOperation(service, "GetCampaign")
+	dbTimer := time.Now()
+	campaign, err := s.repo.GetByID(ctx, id)
+	s.Metrics.ObserveDBOperationDuration(service, "GetCampaign", time.Since(dbTimer).Seconds())
+	if err != nil {
+		if errors.Is(err, repository.ErrCampaignNotFound) {
+			return nil, status.Error(codes.NotFound, "campaign not found")
+		}
+		s.Metrics.IncDBError(service, "GetCampaign")
+		s.Logger.Error("Failed to load campaign", zap.Int64("campaignID", id), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to load campaign: %v", err)
+	}
+
+	if campaign.UserID != userID {
+		return nil, status.Error(codes.PermissionDenied, "campaign belongs to another user")
+	}
+	return campaign, nil
+}
+
+// lookup returns a campaign for a redirect, from the cache when possible
+func (s *CampaignService) lookup(ctx context.Context, id int64) (*models.Campaign, error) {
+	service := "url-service"
+	cacheKey := campaignCacheKey(id)
+
+	if data, err := s.cache.Get(ctx, cacheKey).Bytes(); err == nil {
+		var campaign models.Campaign
+		if err := json.Unmarshal(data, &campaign); err == nil {
+			s.Metrics.IncCacheHit(service, "campaign")
+			return &campaign, nil
+		}
+	} else if err == redis.Nil {
+		s.Metrics.IncCacheMiss(service, "campaign")
+	} else {
+		s.Metrics.IncCacheError(service, "campaign", "get")
+	}
+
+	s.Metrics.IncDBOperation(service, "GetCampaign")
+	dbTimer := time.Now()
+	campaign, err := s.repo.GetByID(ctx, id)
+	s.Metrics.ObserveDBOperationDuration(service, "GetCampaign", time.Since(dbTimer).Seconds())
+	if err != nil {
+		if !errors.Is(err, repository.ErrCampaignNotFound) {
+			s.Metrics.IncDBError(service, "GetCampaign")
+		}
+		return nil, err
+	}
+
+	if data, err := json.Marshal(campaign); err == nil {
+		if err := s.cache.Set(ctx, cacheKey, data, cacheTTL).Err(); err != nil {
+			s.Metrics.IncCacheError(service, "campaign", "set")
+		}
+	}
+	return campaign, nil
+}
+
+func (s *CampaignService) removeFromCache(ctx context.Context, id int64) {
+	if err := s.cache.Del(ctx, campaignCacheKey(id)).Err(); err != nil {
+		s.Metrics.IncCacheError("url-service", "campaign", "delete")
+		s.Logger.Error("Failed to remove campaign from cache", zap.Int64("campaignID", id), zap.Error(err))
+	}
+}
+
+func campaignCacheKey(id int64) string {
+	return fmt.Sprintf("campaign:%d", id)
+}
+
+// validateCampaign checks a campaign has a name and every field fits its column.
+func validateCampaign(campaign *models.Campaign) error {
+	if campaign.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	for name, value := range map[string]string{
+		"name":         campaign.Name,
+		"utm_source":   campaign.UTMSource,
+		"utm_medium":   campaign.UTMMedium,
+		"utm_campaign": campaign.UTMCampaign,
+		"utm_term":     campaign.UTMTerm,
+		"utm_content":  campaign.UTMContent,
+	} {
+		if len(value) > maxCampaignFieldLength {
+			return fmt.Errorf("%s must be at most %d characters", name, maxCampaignFieldLength)
+		}
+	}
+	return nil
+}
+
+// appendUTM adds the campaign's non-empty UTM parameters to destination.
+// Parameters the destination already sets are kept, so a single link can
+// still override its campaign.
+func appendUTM(destination string, campaign *models.Campaign) string {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return destination
+	}
+
+	utm := url.Values{}
+	for name, value := range map[string]string{
+		"utm_source":   campaign.UTMSource,
+		"utm_medium":   campaign.UTMMedium,
+		"utm_campaign": campaign.UTMCampaign,
+		"utm_term":     campaign.UTMTerm,
+		"utm_content":  campaign.UTMContent,
+	} {
+		if value != "" {
+			utm.Set(name, value)
+		}
+	}
+
+	u.RawQuery = passthrough.MergeQuery(u.RawQuery, utm.Encode(), false)
+	return u.String()
+}
+
+// toCampaignProto converts a campaign model to its protobuf representation.
+func toCampaignProto(campaign *models.Campaign) *pb.Campaign {
+	return &pb.Campaign{
+		Id:          campaign.ID,
+		UserId:      campaign.UserID,
+		Name:        campaign.Name,
+		UtmSource:   campaign.UTMSource,
+		UtmMedium:   campaign.UTMMedium,
+		UtmCampaign: campaign.UTMCampaign,
+		UtmTerm:     campaign.UTMTerm,
+		UtmContent:  campaign.UTMContent,
+		CreatedAt:   campaign.CreatedAt.Unix(),
+		UpdatedAt:   campaign.UpdatedAt.Unix(),
+	}
+}
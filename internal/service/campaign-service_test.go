@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/sammyqtran/url-shortener/internal/metrics"
+	"github.com/sammyqtran/url-shortener/internal/models"
+	"github.com/sammyqtran/url-shortener/internal/repository"
+	pb "github.com/sammyqtran/url-shortener/proto"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type MockCampaignRepo struct {
+	mock.Mock
+}
+
+func (m *MockCampaignRepo) Create(ctx context.Context, campaign *models.Campaign) error {
+	args := m.Called(ctx, campaign)
+	campaign.ID = 1
+	return args.Error(0)
+}
+
+func (m *MockCampaignRepo) GetByID(ctx context.Context, id int64) (*models.Campaign, error) {
+	args := m.Called(ctx, id)
+
+	if campaign, ok := args.Get(0).(*models.Campaign); ok {
+		return campaign, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockCampaignRepo) ListByUser(ctx context.Context, userID string) ([]*models.Campaign, error) {
+	args := m.Called(ctx, userID)
+
+	if campaigns, ok := args.Get(0).([]*models.Campaign); ok {
+		return campaigns, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockCampaignRepo) Update(ctx context.Context, campaign *models.Campaign) error {
+	args := m.Called(ctx, campaign)
+	return args.Error(0)
+}
+
+func (m *MockCampaignRepo) Delete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestCreateCampaign(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       *pb.CreateCampaignRequest
+		expectErr codes.Code
+	}{
+		{
+			name: "valid campaign",
+			req:  &pb.CreateCampaignRequest{UserId: "user123", Name: "spring", UtmSource: "newsletter", UtmCampaign: "spring_sale"},
+		},
+		{
+			name:      "missing user",
+			req:       &pb.CreateCampaignRequest{Name: "spring"},
+			expectErr: codes.InvalidArgument,
+		},
+		{
+			name:      "missing name",
+			req:       &pb.CreateCampaignRequest{UserId: "user123", UtmSource: "newsletter"},
+			expectErr: codes.InvalidArgument,
+		},
+		{
+			name:      "utm value too long",
+			req:       &pb.CreateCampaignRequest{UserId: "user123", Name: "spring", UtmTerm: strings.Repeat("a", maxCampaignFieldLength+1)},
+			expectErr: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockCampaignRepo)
+			service := &CampaignService{repo: repo, Logger: zap.NewNop(), Metrics: &metrics.NoopMetrics{}}
+			if tt.expectErr == codes.OK {
+				repo.On("Create", mock.Anything, mock.AnythingOfType("*models.Campaign")).Return(nil)
+			}
+
+			resp, err := service.CreateCampaign(context.Background(), tt.req)
+			if tt.expectErr != codes.OK {
+				require.Equal(t, tt.expectErr, status.Code(err))
+				repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, int64(1), resp.Campaign.Id)
+			require.Equal(t, tt.req.UtmSource, resp.Campaign.UtmSource)
+			require.Equal(t, tt.req.UtmCampaign, resp.Campaign.UtmCampaign)
+		})
+	}
+}
+
+func TestCampaignOwnership(t *testing.T) {
+	repo := new(MockCampaignRepo)
+	service := &CampaignService{repo: repo, Logger: zap.NewNop(), Metrics: &metrics.NoopMetrics{}}
+	repo.On("GetByID", mock.Anything, int64(7)).Return(&models.Campaign{ID: 7, UserID: "owner", Name: "spring"}, nil)
+	repo.On("GetByID", mock.Anything, int64(8)).Return(nil, repository.ErrCampaignNotFound)
+
+	resp, err := service.GetCampaign(context.Background(), &pb.GetCampaignRequest{Id: 7, UserId: "owner"})
+	require.NoError(t, err)
+	require.Equal(t, "spring", resp.Campaign.Name)
+
+	_, err = service.GetCampaign(context.Background(), &pb.GetCampaignRequest{Id: 7, UserId: "someone-else"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = service.UpdateCampaign(context.Background(), &pb.UpdateCampaignRequest{Id: 7, UserId: "someone-else", Name: proto.String("mine")})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = service.DeleteCampaign(context.Background(), &pb.DeleteCampaignRequest{Id: 7, UserId: "someone-else"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = service.GetCampaign(context.Background(), &pb.GetCampaignRequest{Id: 8, UserId: "owner"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestUpdateCampaign(t *testing.T) {
+	cache, mockRedis := redismock.NewClientMock()
+	repo := new(MockCampaignRepo)
+	service := &CampaignService{repo: repo, cache: cache, Logger: zap.NewNop(), Metrics: &metrics.NoopMetrics{}}
+
+	repo.On("GetByID", mock.Anything, int64(7)).
+		Return(&models.Campaign{ID: 7, UserID: "owner", Name: "spring", UTMSource: "newsletter", UTMMedium: "email"}, nil)
+	var stored *models.Campaign
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*models.Campaign")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*models.Campaign) }).
+		Return(nil)
+	// redirects must not keep tagging with the old parameters
+	mockRedis.ExpectDel("campaign:7").SetVal(1)
+
+	resp, err := service.UpdateCampaign(context.Background(), &pb.UpdateCampaignRequest{
+		Id:        7,
+		UserId:    "owner",
+		UtmSource: proto.String("twitter"),
+		UtmMedium: proto.String(""),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "twitter", resp.Campaign.UtmSource)
+	require.Equal(t, "", stored.UTMMedium, "a set empty value clears the parameter")
+	require.Equal(t, "spring", stored.Name, "unset fields are kept")
+	require.NoError(t, mockRedis.ExpectationsWereMet())
+}
+
+func TestAppendUTM(t *testing.T) {
+	campaign := &models.Campaign{UTMSource: "newsletter", UTMMedium: "email", UTMCampaign: "spring sale"}
+
+	tests := []struct {
+		name        string
+		destination string
+		expected    string
+	}{
+		{
+			name:        "no query",
+			destination: "https://example.com/shop",
+			expected:    "https://example.com/shop?utm_campaign=spring+sale&utm_medium=email&utm_source=newsletter",
+		},
+		{
+			name:        "existing parameters come first",
+			destination: "https://example.com/shop?id=4",
+			expected:    "https://example.com/shop?id=4&utm_campaign=spring+sale&utm_medium=email&utm_source=newsletter",
+		},
+		{
+			name:        "destination overrides the campaign",
+			destination: "https://example.com/shop?utm_source=partner",
+			expected:    "https://example.com/shop?utm_source=partner&utm_campaign=spring+sale&utm_medium=email",
+		},
+		{
+			name:        "fragment is kept",
+			destination: "https://example.com/shop#top",
+			expected:    "https://example.com/shop?utm_campaign=spring+sale&utm_medium=email&utm_source=newsletter#top",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, appendUTM(tt.destination, campaign))
+		})
+	}
+
+	require.Equal(t, "https://example.com/", appendUTM("https://example.com/", &models.Campaign{Name: "empty"}),
+		"a campaign without parameters leaves the destination alone")
+}
+
+func TestGetOriginalURL_Campaign(t *testing.T) {
+	campaignID := int64(7)
+	urlData, err := json.Marshal(&models.URL{ShortCode: "abc123", OriginalURL: "https://example.com/shop?utm_source=partner", CampaignID: &campaignID})
+	require.NoError(t, err)
+	campaignData, err := json.Marshal(&models.Campaign{ID: 7, UserID: "owner", Name: "spring", UTMSource: "newsletter", UTMCampaign: "spring"})
+	require.NoError(t, err)
+
+	cache, mockRedis := redismock.NewClientMock()
+	repo := new(MockRepo)
+	repo.On("IncrementClickCount", mock.Anything, "abc123").Return(nil).Maybe()
+	service := &URLService{
+		repo:      repo,
+		cache:     cache,
+		Logger:    zap.NewNop(),
+		Metrics:   &metrics.NoopMetrics{},
+		Campaigns: &CampaignService{repo: new(MockCampaignRepo), cache: cache, Logger: zap.NewNop(), Metrics: &metrics.NoopMetrics{}},
+	}
+	mockRedis.ExpectGet("url:abc123").SetVal(string(urlData))
+	mockRedis.ExpectGet("campaign:7").SetVal(string(campaignData))
+
+	resp, err := service.GetOriginalURL(context.Background(), &pb.GetURLRequest{ShortCode: "abc123"})
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/shop?utm_source=partner&utm_campaign=spring", resp.OriginalUrl)
+	require.Equal(t, campaignID, resp.CampaignId)
+}
+
+func TestGetOriginalURL_CampaignUnavailable(t *testing.T) {
+	campaignID := int64(7)
+	urlData, err := json.Marshal(&models.URL{ShortCode: "abc123", OriginalURL: "https://example.com/shop", CampaignID: &campaignID})
+	require.NoError(t, err)
+
+	cache, mockRedis := redismock.NewClientMock()
+	repo := new(MockRepo)
+	repo.On("IncrementClickCount", mock.Anything, "abc123").Return(nil).Maybe()
+	campaignRepo := new(MockCampaignRepo)
+	campaignRepo.On("GetByID", mock.Anything, campaignID).Return(nil, repository.ErrCampaignNotFound)
+	service := &URLService{
+		repo:      repo,
+		cache:     cache,
+		Logger:    zap.NewNop(),
+		Metrics:   &metrics.NoopMetrics{},
+		Campaigns: &CampaignService{repo: campaignRepo, cache: cache, Logger: zap.NewNop(), Metrics: &metrics.NoopMetrics{}},
+	}
+	mockRedis.ExpectGet("url:abc123").SetVal(string(urlData))
+	mockRedis.ExpectGet("campaign:7").RedisNil()
+
+	resp, err := service.GetOriginalURL(context.Background(), &pb.GetURLRequest{ShortCode: "abc123"})
+	require.NoError(t, err)
+	require.True(t, resp.Found, "the redirect still happens")
+	require.Equal(t, "https://example.com/shop", resp.OriginalUrl)
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Short code generation strategies
+const (
+	StrategyRandom    = "random"
+	StrategySequence  = "sequence"
+	StrategySnowflake = "snowflake"
+	StrategyHashids   = "hashids"
+)
+
+// minAlphabetLength keeps the longest snowflake ID inside the short_code column
+const minAlphabetLength = 16
+
+// CodeGenerator produces candidate short codes. Candidates can still be taken,
+// custom aliases can occupy any of them, so callers retry on insert conflicts.
+type CodeGenerator interface {
+	Generate(ctx context.Context) (string, error)
+}
+
+// CollisionObserver is implemented by generators that adapt to how often
+// their candidates turn out to be taken.
+type CollisionObserver interface {
+	ObserveCollision(collided bool)
+}
+
+// SequenceSource hands out unique, increasing counter values
+type SequenceSource interface {
+	NextShortCodeID(ctx context.Context) (int64, error)
+}
+
+type CodeGeneratorConfig struct {
+	Strategy string
+	// Alphabet is the set of characters codes are built from
+	Alphabet string
+	// Length is the starting length for random codes and the minimum for the others
+	Length int
+	// WorkerID distinguishes replicas for the snowflake strategy, 0-1023
+	WorkerID int64
+	// Salt shuffles the alphabet and counter for the hashids strategy
+	Salt string
+}
+
+func DefaultCodeGeneratorConfig() CodeGeneratorConfig {
+	return CodeGeneratorConfig{
+		Strategy: StrategyRandom,
+		Alphabet: charset,
+		Length:   6,
+	}
+}
+
+// NewCodeGenerator builds the generator for cfg.Strategy. seq backs the
+// sequence and hashids strategies and may be nil for the others.
+func NewCodeGenerator(cfg CodeGeneratorConfig, seq SequenceSource) (CodeGenerator, error) {
+	if err := validateAlphabet(cfg.Alphabet); err != nil {
+		return nil, err
+	}
+	if cfg.Length < 1 || cfg.Length > maxAliasLength {
+		return nil, fmt.Errorf("length must be between 1 and %d", maxAliasLength)
+	}
+
+	switch cfg.Strategy {
+	case StrategyRandom:
+		return NewRandomCodeGenerator(cfg.Alphabet, cfg.Length), nil
+	case StrategySequence, StrategyHashids:
+		if seq == nil {
+			return nil, fmt.Errorf("%s strategy needs a sequence source", cfg.Strategy)
+		}
+		if cfg.Strategy == StrategySequence {
+			return NewSequenceCodeGenerator(seq, cfg.Alphabet, cfg.Length), nil
+		}
+		return NewHashidsCodeGenerator(seq, cfg.Alphabet, cfg.Length, cfg.Salt), nil
+	case StrategySnowflake:
+		if cfg.WorkerID < 0 || cfg.WorkerID > snowflakeMaxWorker {
+			return nil, fmt.Errorf("worker ID must be between 0 and %d", snowflakeMaxWorker)
+		}
+		return NewSnowflakeCodeGenerator(cfg.WorkerID, cfg.Alphabet, cfg.Length), nil
+	default:
+		return nil, fmt.Errorf("unknown short code strategy %q", cfg.Strategy)
+	}
+}
+
+func validateAlphabet(alphabet string) error {
+	if len(alphabet) < minAlphabetLength {
+		return fmt.Errorf("alphabet must have at least %d characters", minAlphabetLength)
+	}
+	seen := make(map[rune]bool, len(alphabet))
+	for _, c := range alphabet {
+		if !strings.ContainsRune(charset, c) && c != '-' && c != '_' {
+			return fmt.Errorf("alphabet character %q is not URL safe", c)
+		}
+		if seen[c] {
+			return fmt.Errorf("alphabet character %q is repeated", c)
+		}
+		seen[c] = true
+	}
+	return nil
+}
+
+// RandomCodeGenerator draws codes from crypto/rand. Its length grows by one
+// whenever more than growThreshold of the last growWindow candidates collided.
+type RandomCodeGenerator struct {
+	alphabet string
+
+	mu            sync.Mutex
+	length        int
+	attempts      int
+	collisions    int
+	growWindow    int
+	growThreshold float64
+}
+
+func NewRandomCodeGenerator(alphabet string, length int) *RandomCodeGenerator {
+	return &RandomCodeGenerator{
+		alphabet:      alphabet,
+		length:        length,
+		growWindow:    100,
+		growThreshold: 0.05,
+	}
+}
+
+func (g *RandomCodeGenerator) Generate(ctx context.Context) (string, error) {
+	return randomString(g.alphabet, g.Length())
+}
+
+// Length returns the current code length
+func (g *RandomCodeGenerator) Length() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.length
+}
+
+func (g *RandomCodeGenerator) ObserveCollision(collided bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.attempts++
+	if collided {
+		g.collisions++
+	}
+	if g.attempts < g.growWindow {
+		return
+	}
+
+	if float64(g.collisions)/float64(g.attempts) > g.growThreshold && g.length < maxAliasLength {
+		g.length++
+	}
+	g.attempts, g.collisions = 0, 0
+}
+
+// SequenceCodeGenerator encodes the next counter value in the alphabet,
+// left-padded to the minimum length.
+type SequenceCodeGenerator struct {
+	seq       SequenceSource
+	alphabet  string
+	minLength int
+}
+
+func NewSequenceCodeGenerator(seq SequenceSource, alphabet string, minLength int) *SequenceCodeGenerator {
+	return &SequenceCodeGenerator{
+		seq:       seq,
+		alphabet:  alphabet,
+		minLength: minLength,
+	}
+}
+
+func (g *SequenceCodeGenerator) Generate(ctx context.Context) (string, error) {
+	id, err := g.seq.NextShortCodeID(ctx)
+	if err != nil {
+		return "", err
+	}
+	return encodeBase(uint64(id), g.alphabet, g.minLength), nil
+}
+
+// snowflake IDs are 41 bits of milliseconds since snowflakeEpoch, 10 bits of
+// worker ID and 12 bits of per-millisecond sequence
+const (
+	snowflakeWorkerBits   = 10
+	snowflakeSequenceBits = 12
+	snowflakeMaxWorker    = 1<<snowflakeWorkerBits - 1
+	snowflakeMaxSequence  = 1<<snowflakeSequenceBits - 1
+)
+
+var snowflakeEpoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// SnowflakeCodeGenerator builds time ordered IDs that are unique across
+// replicas as long as each one has its own worker ID.
+type SnowflakeCodeGenerator struct {
+	workerID  int64
+	alphabet  string
+	minLength int
+	now       func() time.Time
+
+	mu       sync.Mutex
+	lastMs   int64
+	sequence int64
+}
+
+func NewSnowflakeCodeGenerator(workerID int64, alphabet string, minLength int) *SnowflakeCodeGenerator {
+	return &SnowflakeCodeGenerator{
+		workerID:  workerID,
+		alphabet:  alphabet,
+		minLength: minLength,
+		now:       time.Now,
+	}
+}
+
+func (g *SnowflakeCodeGenerator) Generate(ctx context.Context) (string, error) {
+	return encodeBase(uint64(g.nextID()), g.alphabet, g.minLength), nil
+}
+
+func (g *SnowflakeCodeGenerator) nextID() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := g.now().Sub(snowflakeEpoch).Milliseconds()
+	if ms < g.lastMs {
+		// clock went backwards, stay on the last timestamp rather than reuse IDs
+		ms = g.lastMs
+	}
+
+	if ms == g.lastMs {
+		g.sequence = (g.sequence + 1) & snowflakeMaxSequence
+		if g.sequence == 0 {
+			// sequence exhausted for this millisecond, borrow the next one
+			ms = g.lastMs + 1
+		}
+	} else {
+		g.sequence = 0
+	}
+	g.lastMs = ms
+
+	return ms<<(snowflakeWorkerBits+snowflakeSequenceBits) | g.workerID<<snowflakeSequenceBits | g.sequence
+}
+
+// HashidsCodeGenerator turns counter values into non-sequential looking codes.
+// Counters are permuted within the space of codes of the current length with
+// a salt-derived multiplier, so codes stay unique and short but don't reveal
+// how many links exist. The length grows once a length's space is used up.
+type HashidsCodeGenerator struct {
+	seq       SequenceSource
+	alphabet  string
+	minLength int
+	salt      uint64
+}
+
+func NewHashidsCodeGenerator(seq SequenceSource, alphabet string, minLength int, salt string) *HashidsCodeGenerator {
+	sum := sha256.Sum256([]byte(salt))
+	return &HashidsCodeGenerator{
+		seq:       seq,
+		alphabet:  shuffleAlphabet(alphabet, sum[:]),
+		minLength: minLength,
+		salt:      binary.BigEndian.Uint64(sum[8:16]),
+	}
+}
+
+func (g *HashidsCodeGenerator) Generate(ctx context.Context) (string, error) {
+	id, err := g.seq.NextShortCodeID(ctx)
+	if err != nil {
+		return "", err
+	}
+	return g.encode(uint64(id)), nil
+}
+
+func (g *HashidsCodeGenerator) encode(n uint64) string {
+	base := uint64(len(g.alphabet))
+
+	// find the code length whose space holds n, counting from the codes already used by shorter lengths
+	length := g.minLength
+	space := pow(base, length)
+	for space != 0 && n >= space {
+		n -= space
+		length++
+		space = pow(base, length)
+	}
+	if space == 0 {
+		// beyond 64 bits there's nothing left to permute against
+		return encodeBase(n, g.alphabet, length)
+	}
+
+	multiplier := coprimeMultiplier(g.salt, space)
+	hi, lo := bits.Mul64(n, multiplier)
+	_, permuted := bits.Div64(hi, lo, space)
+	return encodeBase(permuted, g.alphabet, length)
+}
+
+// coprimeMultiplier derives a multiplier from salt that is invertible modulo space
+func coprimeMultiplier(salt, space uint64) uint64 {
+	m := salt%space | 1
+	for new(big.Int).GCD(nil, nil, new(big.Int).SetUint64(m), new(big.Int).SetUint64(space)).Uint64() != 1 {
+		m = (m + 2) % space
+	}
+	return m
+}
+
+// shuffleAlphabet deterministically permutes alphabet with a Fisher-Yates shuffle seeded by key
+func shuffleAlphabet(alphabet string, key []byte) string {
+	b := []byte(alphabet)
+	for i := len(b) - 1; i > 0; i-- {
+		j := int(key[i%len(key)]) % (i + 1)
+		b[i], b[j] = b[j], b[i]
+	}
+	return string(b)
+}
+
+// pow returns base^exp, or 0 if it overflows uint64
+func pow(base uint64, exp int) uint64 {
+	result := uint64(1)
+	for i := 0; i < exp; i++ {
+		hi, lo := bits.Mul64(result, base)
+		if hi != 0 {
+			return 0
+		}
+		result = lo
+	}
+	return result
+}
+
+// encodeBase writes n in the alphabet's base, most significant digit first,
+// left-padded with the alphabet's first character to minLength.
+func encodeBase(n uint64, alphabet string, minLength int) string {
+	base := uint64(len(alphabet))
+	var buf [64]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = alphabet[n%base]
+		n /= base
+	}
+	for len(buf)-i < minLength {
+		i--
+		buf[i] = alphabet[0]
+	}
+	return string(buf[i:])
+}
+
+// randomString returns n characters drawn uniformly from alphabet using crypto/rand
+func randomString(alphabet string, n int) (string, error) {
+	max := big.NewInt(int64(len(alphabet)))
+	b := make([]byte, n)
+	for i := range b {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to read random bytes: %w", err)
+		}
+		b[i] = alphabet[idx.Int64()]
+	}
+	return string(b), nil
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sammyqtran/url-shortener/internal/metrics"
+	"github.com/sammyqtran/url-shortener/internal/models"
+	"github.com/sammyqtran/url-shortener/internal/repository"
+	pb "github.com/sammyqtran/url-shortener/proto"
+)
+
+// counterSource is an in-memory SequenceSource
+type counterSource struct {
+	mu   sync.Mutex
+	next int64
+}
+
+func (c *counterSource) NextShortCodeID(ctx context.Context) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.next++
+	return c.next, nil
+}
+
+// stubGenerator returns codes in order and records collision feedback
+type stubGenerator struct {
+	codes    []string
+	observed []bool
+}
+
+func (g *stubGenerator) Generate(ctx context.Context) (string, error) {
+	code := g.codes[0]
+	g.codes = g.codes[1:]
+	return code, nil
+}
+
+func (g *stubGenerator) ObserveCollision(collided bool) {
+	g.observed = append(g.observed, collided)
+}
+
+// codeGeneratorFunc adapts a function to CodeGenerator
+type codeGeneratorFunc func(ctx context.Context) (string, error)
+
+func (f codeGeneratorFunc) Generate(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+func TestNewCodeGenerator(t *testing.T) {
+	seq := &counterSource{}
+
+	tests := []struct {
+		name      string
+		modify    func(cfg *CodeGeneratorConfig)
+		seq       SequenceSource
+		expectErr string
+	}{
+		{name: "random", modify: func(cfg *CodeGeneratorConfig) {}},
+		{name: "sequence", modify: func(cfg *CodeGeneratorConfig) { cfg.Strategy = StrategySequence }, seq: seq},
+		{name: "snowflake", modify: func(cfg *CodeGeneratorConfig) { cfg.Strategy = StrategySnowflake; cfg.WorkerID = 7 }},
+		{name: "hashids", modify: func(cfg *CodeGeneratorConfig) { cfg.Strategy = StrategyHashids; cfg.Salt = "pepper" }, seq: seq},
+		{
+			name:      "unknown strategy",
+			modify:    func(cfg *CodeGeneratorConfig) { cfg.Strategy = "uuid" },
+			expectErr: "unknown short code strategy",
+		},
+		{
+			name:      "sequence without source",
+			modify:    func(cfg *CodeGeneratorConfig) { cfg.Strategy = StrategySequence },
+			expectErr: "needs a sequence source",
+		},
+		{
+			name:      "short alphabet",
+			modify:    func(cfg *CodeGeneratorConfig) { cfg.Alphabet = "abc" },
+			expectErr: "at least 16",
+		},
+		{
+			name:      "repeated alphabet character",
+			modify:    func(cfg *CodeGeneratorConfig) { cfg.Alphabet = "abcdefghijklmnoa" },
+			expectErr: "repeated",
+		},
+		{
+			name:      "unsafe alphabet character",
+			modify:    func(cfg *CodeGeneratorConfig) { cfg.Alphabet = "abcdefghijklmno/" },
+			expectErr: "not URL safe",
+		},
+		{
+			name:      "length too long",
+			modify:    func(cfg *CodeGeneratorConfig) { cfg.Length = 33 },
+			expectErr: "length must be between",
+		},
+		{
+			name:      "worker ID out of range",
+			modify:    func(cfg *CodeGeneratorConfig) { cfg.Strategy = StrategySnowflake; cfg.WorkerID = 1024 },
+			expectErr: "worker ID",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := DefaultCodeGeneratorConfig()
+			tc.modify(&cfg)
+
+			generator, err := NewCodeGenerator(cfg, tc.seq)
+			if tc.expectErr != "" {
+				require.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+
+			code, err := generator.Generate(context.Background())
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, len(code), cfg.Length)
+			require.LessOrEqual(t, len(code), maxAliasLength)
+			for _, c := range code {
+				require.True(t, strings.ContainsRune(cfg.Alphabet, c), "unexpected character %q", c)
+			}
+		})
+	}
+}
+
+func TestRandomCodeGenerator_GrowsOnCollisions(t *testing.T) {
+	generator := NewRandomCodeGenerator(charset, 6)
+
+	// a quiet window keeps the length
+	for i := 0; i < generator.growWindow; i++ {
+		generator.ObserveCollision(i%50 == 0)
+	}
+	require.Equal(t, 6, generator.Length())
+
+	// a noisy window grows it
+	for i := 0; i < generator.growWindow; i++ {
+		generator.ObserveCollision(i%10 == 0)
+	}
+	require.Equal(t, 7, generator.Length())
+
+	code, err := generator.Generate(context.Background())
+	require.NoError(t, err)
+	require.Len(t, code, 7)
+}
+
+func TestRandomCodeGenerator_LengthCapped(t *testing.T) {
+	generator := NewRandomCodeGenerator(charset, maxAliasLength)
+	for i := 0; i < generator.growWindow; i++ {
+		generator.ObserveCollision(true)
+	}
+	require.Equal(t, maxAliasLength, generator.Length())
+}
+
+func TestSequenceCodeGenerator(t *testing.T) {
+	alphabet := "0123456789abcdef"
+	generator := NewSequenceCodeGenerator(&counterSource{next: 254}, alphabet, 2)
+
+	for _, expected := range []string{"ff", "100", "101"} {
+		code, err := generator.Generate(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, expected, code)
+	}
+}
+
+func TestEncodeBase(t *testing.T) {
+	require.Equal(t, "aaaaaa", encodeBase(0, charset, 6))
+	require.Equal(t, "aaaaab", encodeBase(1, charset, 6))
+	require.Equal(t, "ba", encodeBase(62, charset, 1))
+	require.Len(t, encodeBase(1<<63-1, charset, 1), 11)
+}
+
+func TestSnowflakeCodeGenerator(t *testing.T) {
+	now := snowflakeEpoch.Add(time.Hour)
+	generator := NewSnowflakeCodeGenerator(5, charset, 6)
+	generator.now = func() time.Time { return now }
+
+	first := generator.nextID()
+	second := generator.nextID()
+	require.Greater(t, second, first)
+	require.Equal(t, int64(5), first>>snowflakeSequenceBits&snowflakeMaxWorker)
+	require.Equal(t, time.Hour.Milliseconds(), first>>(snowflakeWorkerBits+snowflakeSequenceBits))
+
+	// a clock going backwards must not repeat IDs
+	now = now.Add(-time.Second)
+	require.Greater(t, generator.nextID(), second)
+
+	// exhausting a millisecond's sequence moves on to the next one
+	now = now.Add(2 * time.Second)
+	seen := make(map[int64]bool)
+	for i := 0; i <= snowflakeMaxSequence+1; i++ {
+		id := generator.nextID()
+		require.False(t, seen[id], "duplicate ID")
+		seen[id] = true
+	}
+
+	// different workers never collide in the same millisecond
+	other := NewSnowflakeCodeGenerator(6, charset, 6)
+	other.now = func() time.Time { return now.Add(time.Minute) }
+	generator.now = other.now
+	require.NotEqual(t, generator.nextID(), other.nextID())
+}
+
+func TestHashidsCodeGenerator(t *testing.T) {
+	alphabet := "abcdefghijklmnopqrstuvwxyz"
+	generator := NewHashidsCodeGenerator(&counterSource{}, alphabet, 2, "pepper")
+
+	// every counter in the 2 and 3 character spaces maps to a distinct code
+	space2 := pow(26, 2)
+	seen := make(map[string]bool)
+	var sequential int
+	previous := ""
+	for n := uint64(1); n < space2+pow(26, 3); n++ {
+		code := generator.encode(n)
+		if n < space2 {
+			require.Len(t, code, 2)
+		} else {
+			require.Len(t, code, 3)
+		}
+		require.False(t, seen[code], "duplicate code %q for %d", code, n)
+		seen[code] = true
+		if previous != "" && code > previous && len(code) == len(previous) {
+			sequential++
+		}
+		previous = code
+	}
+	// codes shouldn't simply count upwards
+	require.Less(t, sequential, len(seen)*3/4)
+
+	// a different salt gives different codes
+	other := NewHashidsCodeGenerator(&counterSource{}, alphabet, 2, "salt")
+	require.NotEqual(t, generator.encode(1), other.encode(1))
+}
+
+func TestCreateShortURL_ReportsCollisions(t *testing.T) {
+	generator := &stubGenerator{codes: []string{"create", "taken1", "free01"}}
+	mockRepo := new(MockRepo)
+	cache, _ := redismock.NewClientMock()
+	service := &URLService{
+		repo:      mockRepo,
+		generator: generator,
+		cache:     cache,
+		Logger:    zap.NewNop(),
+		Metrics:   &metrics.NoopMetrics{},
+	}
+
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+		return u.ShortCode == "taken1"
+	})).Return(repository.ErrShortCodeExists)
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+		return u.ShortCode == "free01"
+	})).Return(nil)
+
+	resp, err := service.CreateShortURL(context.Background(), &pb.CreateURLRequest{OriginalUrl: "https://google.com"})
+	require.NoError(t, err)
+	require.Equal(t, "free01", resp.ShortCode)
+
+	// reserved route names count as collisions without a DB round trip
+	require.Equal(t, []bool{true, true, false}, generator.observed)
+	mockRepo.AssertNumberOfCalls(t, "Create", 2)
+}
+
+func TestCreateShortURL_SequenceError(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRepo.On("NextShortCodeID", mock.Anything).Return(int64(0), fmt.Errorf("sequence missing"))
+
+	service := &URLService{
+		repo:      mockRepo,
+		generator: NewSequenceCodeGenerator(mockRepo, charset, 6),
+		Logger:    zap.NewNop(),
+		Metrics:   &metrics.NoopMetrics{},
+	}
+
+	_, err := service.CreateShortURL(context.Background(), &pb.CreateURLRequest{OriginalUrl: "https://google.com"})
+	require.Equal(t, codes.Internal, status.Code(err))
+	require.ErrorContains(t, err, "sequence missing")
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sammyqtran/url-shortener/internal/metrics"
+	"github.com/sammyqtran/url-shortener/internal/repository"
+)
+
+type CodePoolConfig struct {
+	// LowWater is the depth below which the pool is refilled
+	LowWater int64
+	// Size is the depth a refill tops the pool up to
+	Size int64
+	// BatchSize caps how many codes are inserted per statement
+	BatchSize int
+	// RefillInterval is how often the depth is checked when nothing triggers a refill
+	RefillInterval time.Duration
+}
+
+func DefaultCodePoolConfig() CodePoolConfig {
+	return CodePoolConfig{
+		LowWater:       1000,
+		Size:           5000,
+		BatchSize:      500,
+		RefillInterval: 30 * time.Second,
+	}
+}
+
+// CodePool hands out short codes that a background refill loop has already
+// generated and checked against existing links, keeping generation off the
+// create path. It wraps another CodeGenerator, which fills the pool and is
+// used directly whenever the pool is empty or unreachable.
+type CodePool struct {
+	repo    repository.CodePoolRepository
+	source  CodeGenerator
+	cfg     CodePoolConfig
+	logger  *zap.Logger
+	metrics metrics.Metrics
+
+	// depth is this replica's estimate between refills, others take codes too
+	depth  atomic.Int64
+	refill chan struct{}
+}
+
+func NewCodePool(repo repository.CodePoolRepository, source CodeGenerator, cfg CodePoolConfig, logger *zap.Logger, metrics metrics.Metrics) *CodePool {
+	return &CodePool{
+		repo:    repo,
+		source:  source,
+		cfg:     cfg,
+		logger:  logger,
+		metrics: metrics,
+		refill:  make(chan struct{}, 1),
+	}
+}
+
+func (p *CodePool) Generate(ctx context.Context) (string, error) {
+	code, err := p.repo.Take(ctx)
+	if err == nil {
+		if p.depth.Add(-1) < p.cfg.LowWater {
+			p.requestRefill()
+		}
+		return code, nil
+	}
+
+	if errors.Is(err, repository.ErrCodePoolEmpty) {
+		p.depth.Store(0)
+		p.metrics.IncCodePoolMiss("url-service")
+		p.requestRefill()
+	} else {
+		p.logger.Warn("Failed to take code from pool, generating directly", zap.Error(err))
+	}
+	return p.source.Generate(ctx)
+}
+
+// requestRefill wakes Run without blocking, a pending request covers later ones
+func (p *CodePool) requestRefill() {
+	select {
+	case p.refill <- struct{}{}:
+	default:
+	}
+}
+
+// Run refills the pool on start, every RefillInterval and whenever a take
+// drops it below the low-water mark, until ctx is cancelled.
+func (p *CodePool) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.RefillInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.Refill(ctx); err != nil && ctx.Err() == nil {
+			p.logger.Warn("Failed to refill code pool", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-p.refill:
+		}
+	}
+}
+
+// Refill tops the pool up to Size once it has dropped below LowWater
+func (p *CodePool) Refill(ctx context.Context) error {
+	depth, err := p.repo.Count(ctx)
+	if err != nil {
+		return err
+	}
+	p.setDepth(depth)
+	if depth >= p.cfg.LowWater {
+		return nil
+	}
+
+	observer, _ := p.source.(CollisionObserver)
+	start := depth
+	for depth < p.cfg.Size {
+		codes, err := p.generateBatch(ctx, min(p.cfg.Size-depth, int64(p.cfg.BatchSize)))
+		if err != nil {
+			return err
+		}
+
+		added, err := p.repo.Add(ctx, codes)
+		if err != nil {
+			return err
+		}
+
+		// codes the repository dropped were already pooled or in use
+		if observer != nil {
+			for i := range codes {
+				observer.ObserveCollision(int64(i) >= added)
+			}
+		}
+
+		depth += added
+		p.setDepth(depth)
+		if added == 0 {
+			return fmt.Errorf("no new codes in a batch of %d, the code space may be exhausted", len(codes))
+		}
+	}
+
+	p.logger.Info("Refilled code pool", zap.Int64("added", depth-start), zap.Int64("depth", depth))
+	return nil
+}
+
+// generateBatch draws n distinct, non-reserved codes from the source generator
+func (p *CodePool) generateBatch(ctx context.Context, n int64) ([]string, error) {
+	codes := make([]string, 0, n)
+	seen := make(map[string]bool, n)
+	for attempts := int64(0); int64(len(codes)) < n && attempts < 2*n; attempts++ {
+		code, err := p.source.Generate(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error generating short code: %w", err)
+		}
+		if seen[code] || reservedAliases[strings.ToLower(code)] {
+			continue
+		}
+		seen[code] = true
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+func (p *CodePool) setDepth(depth int64) {
+	p.depth.Store(depth)
+	p.metrics.SetCodePoolDepth("url-service", depth)
+}
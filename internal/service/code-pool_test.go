@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/sammyqtran/url-shortener/internal/metrics"
+	"github.com/sammyqtran/url-shortener/internal/repository"
+)
+
+// memoryCodePool is an in-memory CodePoolRepository, used holds the codes links already have
+type memoryCodePool struct {
+	mu      sync.Mutex
+	codes   []string
+	used    map[string]bool
+	takeErr error
+}
+
+func (m *memoryCodePool) Add(ctx context.Context, codes []string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var added int64
+	for _, code := range codes {
+		if m.used[code] || m.pooled(code) {
+			continue
+		}
+		m.codes = append(m.codes, code)
+		added++
+	}
+	return added, nil
+}
+
+func (m *memoryCodePool) pooled(code string) bool {
+	for _, c := range m.codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *memoryCodePool) Take(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.takeErr != nil {
+		return "", m.takeErr
+	}
+	if len(m.codes) == 0 {
+		return "", repository.ErrCodePoolEmpty
+	}
+	code := m.codes[0]
+	m.codes = m.codes[1:]
+	return code, nil
+}
+
+func (m *memoryCodePool) Count(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return int64(len(m.codes)), nil
+}
+
+// poolMetrics records the pool gauges
+type poolMetrics struct {
+	metrics.NoopMetrics
+	depth  atomic.Int64
+	misses atomic.Int64
+}
+
+func (m *poolMetrics) SetCodePoolDepth(service string, depth int64) {
+	m.depth.Store(depth)
+}
+
+func (m *poolMetrics) IncCodePoolMiss(service string) {
+	m.misses.Add(1)
+}
+
+func newTestCodePool(repo repository.CodePoolRepository, source CodeGenerator, recorder metrics.Metrics) *CodePool {
+	cfg := CodePoolConfig{
+		LowWater:       4,
+		Size:           10,
+		BatchSize:      3,
+		RefillInterval: time.Hour,
+	}
+	return NewCodePool(repo, source, cfg, zap.NewNop(), recorder)
+}
+
+func TestCodePool_Refill(t *testing.T) {
+	repo := &memoryCodePool{used: map[string]bool{"seq002": true}}
+	recorder := &poolMetrics{}
+	var n atomic.Int64
+	source := codeGeneratorFunc(func(ctx context.Context) (string, error) {
+		return fmt.Sprintf("seq%03d", n.Add(1)), nil
+	})
+	pool := newTestCodePool(repo, source, recorder)
+
+	require.NoError(t, pool.Refill(context.Background()))
+	require.Len(t, repo.codes, 10)
+	require.Equal(t, int64(10), recorder.depth.Load())
+	require.NotContains(t, repo.codes, "seq002", "codes in use must not be pooled")
+
+	// above the low-water mark nothing is generated
+	generated := n.Load()
+	repo.codes = repo.codes[:5]
+	require.NoError(t, pool.Refill(context.Background()))
+	require.Len(t, repo.codes, 5)
+	require.Equal(t, generated, n.Load())
+
+	// below it the pool is topped back up to size
+	repo.codes = repo.codes[:3]
+	require.NoError(t, pool.Refill(context.Background()))
+	require.Len(t, repo.codes, 10)
+}
+
+func TestCodePool_RefillReportsCollisions(t *testing.T) {
+	repo := &memoryCodePool{used: map[string]bool{"taken1": true}}
+	source := &stubGenerator{codes: []string{"api", "taken1", "free01", "free01", "free02", "free03", "free04"}}
+	pool := NewCodePool(repo, source, CodePoolConfig{LowWater: 1, Size: 3, BatchSize: 3, RefillInterval: time.Hour}, zap.NewNop(), &metrics.NoopMetrics{})
+
+	require.NoError(t, pool.Refill(context.Background()))
+
+	// reserved and duplicate codes never reach the repository, the taken one is rejected there
+	require.Equal(t, []string{"free01", "free02", "free03"}, repo.codes)
+	require.Equal(t, []bool{false, false, true, false}, source.observed)
+}
+
+func TestCodePool_RefillExhausted(t *testing.T) {
+	repo := &memoryCodePool{used: map[string]bool{}}
+	source := codeGeneratorFunc(func(ctx context.Context) (string, error) {
+		return "same01", nil
+	})
+	pool := newTestCodePool(repo, source, &metrics.NoopMetrics{})
+
+	err := pool.Refill(context.Background())
+	require.ErrorContains(t, err, "code space may be exhausted")
+	require.Equal(t, []string{"same01"}, repo.codes)
+}
+
+func TestCodePool_Generate(t *testing.T) {
+	repo := &memoryCodePool{codes: []string{"pool01", "pool02"}}
+	recorder := &poolMetrics{}
+	source := &stubGenerator{codes: []string{"direct"}}
+	pool := newTestCodePool(repo, source, recorder)
+
+	code, err := pool.Generate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "pool01", code)
+
+	// dropping below the low-water mark asks Run for a refill
+	require.Len(t, pool.refill, 1)
+
+	code, err = pool.Generate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "pool02", code)
+
+	// an empty pool falls back to the source generator
+	code, err = pool.Generate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "direct", code)
+	require.Equal(t, int64(1), recorder.misses.Load())
+}
+
+func TestCodePool_GenerateRepositoryError(t *testing.T) {
+	repo := &memoryCodePool{codes: []string{"pool01"}, takeErr: fmt.Errorf("connection refused")}
+	recorder := &poolMetrics{}
+	pool := newTestCodePool(repo, &stubGenerator{codes: []string{"direct"}}, recorder)
+
+	code, err := pool.Generate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "direct", code)
+	require.Zero(t, recorder.misses.Load())
+}
+
+func TestCodePool_Run(t *testing.T) {
+	repo := &memoryCodePool{used: map[string]bool{}}
+	recorder := &poolMetrics{}
+	pool := newTestCodePool(repo, NewRandomCodeGenerator(charset, 8), recorder)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		pool.Run(ctx)
+		close(done)
+	}()
+
+	// the first refill happens on start
+	require.Eventually(t, func() bool { return recorder.depth.Load() == 10 }, time.Second, 5*time.Millisecond)
+
+	// draining the pool wakes the loop without waiting for the interval
+	for i := 0; i < 7; i++ {
+		_, err := pool.Generate(ctx)
+		require.NoError(t, err)
+	}
+	require.Eventually(t, func() bool {
+		count, _ := repo.Count(ctx)
+		return count == 10
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop after cancel")
+	}
+}
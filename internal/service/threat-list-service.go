@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sammyqtran/url-shortener/internal/metrics"
+	"github.com/sammyqtran/url-shortener/internal/repository"
+	"github.com/sammyqtran/url-shortener/internal/threatlist"
+	pb "github.com/sammyqtran/url-shortener/proto"
+)
+
+type ThreatListConfig struct {
+	// ScanInterval is how often the list is reloaded and existing links re-checked
+	ScanInterval time.Duration
+	// ScanBatchSize caps how many links are read per query during a scan
+	ScanBatchSize int
+}
+
+func DefaultThreatListConfig() ThreatListConfig {
+	return ThreatListConfig{
+		ScanInterval:  time.Hour,
+		ScanBatchSize: 1000,
+	}
+}
+
+// ThreatListService keeps the local threat list in sync with the database
+// and quarantines existing links whose destination matches it. List is shared
+// with URLService, which checks new destinations against it.
+type ThreatListService struct {
+	pb.UnimplementedThreatListServiceServer
+	repo    repository.ThreatListRepository
+	urls    repository.URLRepository
+	list    *threatlist.List
+	cache   *redis.Client
+	cfg     ThreatListConfig
+	Logger  *zap.Logger
+	Metrics metrics.Metrics
+
+	scan chan struct{}
+	// loaded is set by the first successful Reload, until then the list is
+	// empty and a scan would release every quarantined link
+	loaded atomic.Bool
+}
+
+// errThreatListNotLoaded stops scans before the list has been loaded
+var errThreatListNotLoaded = errors.New("threat list has not been loaded")
+
+func NewThreatListService(repo repository.ThreatListRepository, urls repository.URLRepository, list *threatlist.List, cache *redis.Client, cfg ThreatListConfig, logger *zap.Logger, metrics metrics.Metrics) *ThreatListService {
+	return &ThreatListService{
+		repo:    repo,
+		urls:    urls,
+		list:    list,
+		cache:   cache,
+		cfg:     cfg,
+		Logger:  logger,
+		Metrics: metrics,
+		scan:    make(chan struct{}, 1),
+	}
+}
+
+func (s *ThreatListService) UpdateThreatList(ctx context.Context, req *pb.UpdateThreatListRequest) (*pb.UpdateThreatListResponse, error) {
+	for _, prefixes := range [][][]byte{req.Additions, req.Removals} {
+		for _, prefix := range prefixes {
+			if err := threatlist.ValidatePrefix(prefix); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid hash prefix: %v", err)
+			}
+		}
+	}
+
+	resp := &pb.UpdateThreatListResponse{}
+	var err error
+	if req.Replace {
+		resp.Added, err = s.repo.Replace(ctx, req.Additions)
+	} else if resp.Removed, err = s.repo.Remove(ctx, req.Removals); err == nil {
+		resp.Added, err = s.repo.Add(ctx, req.Additions)
+	}
+	if err != nil {
+		s.Logger.Error("Failed to update threat list", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to update threat list: %v", err)
+	}
+
+	if err := s.Reload(ctx); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to reload threat list: %v", err)
+	}
+	resp.Total = int64(s.list.Len())
+
+	s.Logger.Info("Updated threat list", zap.Int64("added", resp.Added), zap.Int64("removed", resp.Removed), zap.Int64("total", resp.Total), zap.Bool("replace", req.Replace))
+	s.requestScan()
+	return resp, nil
+}
+
+// Ingest adds prefixes, usually read with threatlist.ParsePrefixes, to the stored list
+func (s *ThreatListService) Ingest(ctx context.Context, prefixes [][]byte) error {
+	added, err := s.repo.Add(ctx, prefixes)
+	if err != nil {
+		return err
+	}
+	s.Logger.Info("Ingested threat list", zap.Int("prefixes", len(prefixes)), zap.Int64("added", added))
+	return s.Reload(ctx)
+}
+
+// Reload replaces the local list with the stored prefixes
+func (s *ThreatListService) Reload(ctx context.Context) error {
+	prefixes, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	s.list.Replace(prefixes)
+	s.loaded.Store(true)
+	s.Metrics.SetThreatListSize("url-service", int64(s.list.Len()))
+	return nil
+}
+
+// requestScan wakes Run without blocking, a pending request covers later ones
+func (s *ThreatListService) requestScan() {
+	select {
+	case s.scan <- struct{}{}:
+	default:
+	}
+}
+
+// Run reloads the list and scans existing links on start, every
+// ScanInterval and after each update, until ctx is cancelled. Reloading
+// picks up updates made through other replicas. A failed reload skips that
+// scan, the list it would check against may be stale or empty.
+func (s *ThreatListService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.ScanInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Reload(ctx); err != nil {
+			if ctx.Err() == nil {
+				s.Logger.Warn("Failed to reload threat list, skipping scan", zap.Error(err))
+			}
+		} else if err := s.Scan(ctx); err != nil && ctx.Err() == nil {
+			s.Logger.Warn("Failed to scan links against threat list", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.scan:
+		}
+	}
+}
+
+// Scan checks every link against the list, quarantining the ones that now
+// match and releasing the ones that no longer do. It refuses to run before
+// the list has been loaded.
+func (s *ThreatListService) Scan(ctx context.Context) error {
+	if !s.loaded.Load() {
+		return errThreatListNotLoaded
+	}
+
+	var afterID int64
+	var quarantined, released int
+	for {
+		urls, err := s.urls.ListAfterID(ctx, afterID, s.cfg.ScanBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(urls) == 0 {
+			break
+		}
+		afterID = urls[len(urls)-1].ID
+
+		var quarantine, release []int64
+		var changed []string
+		for _, urlModel := range urls {
+			match := false
+			for _, destination := range urlModel.Destinations() {
+				if _, match = s.list.Match(destination); match {
+					break
+				}
+			}
+			switch {
+			case match && urlModel.QuarantinedAt == nil:
+				quarantine = append(quarantine, urlModel.ID)
+			case !match && urlModel.QuarantinedAt != nil:
+				release = append(release, urlModel.ID)
+			default:
+				continue
+			}
+			changed = append(changed, urlModel.ShortCode)
+		}
+
+		if len(quarantine) > 0 {
+			if err := s.urls.SetQuarantined(ctx, quarantine, true); err != nil {
+				return err
+			}
+		}
+		if len(release) > 0 {
+			if err := s.urls.SetQuarantined(ctx, release, false); err != nil {
+				return err
+			}
+		}
+		s.invalidate(ctx, changed)
+		quarantined += len(quarantine)
+		released += len(release)
+	}
+
+	if quarantined > 0 || released > 0 {
+		s.Metrics.IncLinksQuarantined("url-service", quarantined)
+		s.Logger.Info("Scanned links against threat list", zap.Int("quarantined", quarantined), zap.Int("released", released))
+	}
+	return nil
+}
+
+// invalidate drops cached links so redirects see the new quarantine state
+func (s *ThreatListService) invalidate(ctx context.Context, shortCodes []string) {
+	if len(shortCodes) == 0 {
+		return
+	}
+
+	keys := make([]string, len(shortCodes))
+	for i, shortCode := range shortCodes {
+		keys[i] = fmt.Sprintf("url:%s", shortCode)
+	}
+	if err := s.cache.Del(ctx, keys...).Err(); err != nil {
+		s.Metrics.IncCacheError("url-service", "map_url", "delete")
+		s.Logger.Error("Failed to remove quarantined links from cache", zap.Int("links", len(keys)), zap.Error(err))
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sammyqtran/url-shortener/internal/metrics"
+	"github.com/sammyqtran/url-shortener/internal/models"
+	"github.com/sammyqtran/url-shortener/internal/repository"
+	"github.com/sammyqtran/url-shortener/internal/threatlist"
+	pb "github.com/sammyqtran/url-shortener/proto"
+)
+
+// memoryThreatList is an in-memory ThreatListRepository
+type memoryThreatList struct {
+	mu       sync.Mutex
+	prefixes map[string]bool
+}
+
+func newMemoryThreatList() *memoryThreatList {
+	return &memoryThreatList{prefixes: make(map[string]bool)}
+}
+
+func (m *memoryThreatList) Add(ctx context.Context, prefixes [][]byte) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var added int64
+	for _, prefix := range prefixes {
+		if !m.prefixes[string(prefix)] {
+			m.prefixes[string(prefix)] = true
+			added++
+		}
+	}
+	return added, nil
+}
+
+func (m *memoryThreatList) Remove(ctx context.Context, prefixes [][]byte) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var removed int64
+	for _, prefix := range prefixes {
+		if m.prefixes[string(prefix)] {
+			delete(m.prefixes, string(prefix))
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (m *memoryThreatList) Replace(ctx context.Context, prefixes [][]byte) (int64, error) {
+	m.mu.Lock()
+	m.prefixes = make(map[string]bool)
+	m.mu.Unlock()
+	return m.Add(ctx, prefixes)
+}
+
+func (m *memoryThreatList) List(ctx context.Context) ([][]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	prefixes := make([][]byte, 0, len(m.prefixes))
+	for prefix := range m.prefixes {
+		prefixes = append(prefixes, []byte(prefix))
+	}
+	return prefixes, nil
+}
+
+// unavailableThreatList fails to list its prefixes, as when the database isn't up yet
+type unavailableThreatList struct {
+	repository.ThreatListRepository
+}
+
+func (unavailableThreatList) List(ctx context.Context) ([][]byte, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestUpdateThreatList(t *testing.T) {
+	evil := threatlist.HashPrefix("evil.example/", 4)
+	phish := threatlist.HashPrefix("phish.example/", 32)
+
+	list := threatlist.NewList()
+	service := NewThreatListService(newMemoryThreatList(), new(MockRepo), list, nil, DefaultThreatListConfig(), zap.NewNop(), &metrics.NoopMetrics{})
+
+	resp, err := service.UpdateThreatList(context.Background(), &pb.UpdateThreatListRequest{Additions: [][]byte{evil, phish, evil}})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), resp.Added)
+	require.Equal(t, int64(2), resp.Total)
+	_, ok := list.Match("https://evil.example/")
+	require.True(t, ok, "updates apply to the local list right away")
+
+	// an update asks Run for a scan
+	require.Len(t, service.scan, 1)
+
+	resp, err = service.UpdateThreatList(context.Background(), &pb.UpdateThreatListRequest{Removals: [][]byte{evil}})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), resp.Removed)
+	require.Equal(t, int64(1), resp.Total)
+	_, ok = list.Match("https://evil.example/")
+	require.False(t, ok)
+
+	resp, err = service.UpdateThreatList(context.Background(), &pb.UpdateThreatListRequest{Additions: [][]byte{evil}, Replace: true})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), resp.Total)
+	_, ok = list.Match("https://phish.example/")
+	require.False(t, ok)
+
+	_, err = service.UpdateThreatList(context.Background(), &pb.UpdateThreatListRequest{Additions: [][]byte{{0x01, 0x02}}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestThreatListScan(t *testing.T) {
+	flagged := ptrTime(time.Now().Add(-time.Hour))
+	batches := [][]*models.URL{
+		{
+			{ID: 1, ShortCode: "clean1", OriginalURL: "https://example.com/"},
+			{ID: 2, ShortCode: "evil01", OriginalURL: "https://www.evil.example/login"},
+		},
+		{
+			{ID: 3, ShortCode: "evil02", OriginalURL: "https://evil.example/", QuarantinedAt: flagged},
+			{ID: 4, ShortCode: "fixed1", OriginalURL: "https://example.org/", QuarantinedAt: flagged},
+		},
+		{
+			// only a geo route is flagged
+			{ID: 5, ShortCode: "geo001", OriginalURL: "https://example.com/", GeoRoutes: models.Routes{"DE": "https://evil.example/de"}},
+		},
+	}
+
+	repo := new(MockRepo)
+	repo.On("ListAfterID", mock.Anything, int64(0), 2).Return(batches[0], nil)
+	repo.On("ListAfterID", mock.Anything, int64(2), 2).Return(batches[1], nil)
+	repo.On("ListAfterID", mock.Anything, int64(4), 2).Return(batches[2], nil)
+	repo.On("ListAfterID", mock.Anything, int64(5), 2).Return([]*models.URL{}, nil)
+	repo.On("SetQuarantined", mock.Anything, []int64{2}, true).Return(nil)
+	repo.On("SetQuarantined", mock.Anything, []int64{4}, false).Return(nil)
+	repo.On("SetQuarantined", mock.Anything, []int64{5}, true).Return(nil)
+
+	cache, mockRedis := redismock.NewClientMock()
+	mockRedis.ExpectDel("url:evil01").SetVal(1)
+	mockRedis.ExpectDel("url:fixed1").SetVal(1)
+	mockRedis.ExpectDel("url:geo001").SetVal(1)
+
+	threats := newMemoryThreatList()
+	_, err := threats.Add(context.Background(), [][]byte{threatlist.HashPrefix("evil.example/", 4)})
+	require.NoError(t, err)
+	cfg := ThreatListConfig{ScanInterval: time.Hour, ScanBatchSize: 2}
+	service := NewThreatListService(threats, repo, threatlist.NewList(), cache, cfg, zap.NewNop(), &metrics.NoopMetrics{})
+
+	require.NoError(t, service.Reload(context.Background()))
+	require.NoError(t, service.Scan(context.Background()))
+	repo.AssertExpectations(t)
+	require.NoError(t, mockRedis.ExpectationsWereMet())
+}
+
+func TestThreatListScan_NeedsLoadedList(t *testing.T) {
+	// no ListAfterID or SetQuarantined expectations, nothing may be released
+	repo := new(MockRepo)
+	service := NewThreatListService(unavailableThreatList{}, repo, threatlist.NewList(), nil, DefaultThreatListConfig(), zap.NewNop(), &metrics.NoopMetrics{})
+
+	require.Error(t, service.Reload(context.Background()))
+	require.ErrorIs(t, service.Scan(context.Background()), errThreatListNotLoaded)
+
+	// Run skips the scan after a failed reload and stops once ctx is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	service.Run(ctx)
+	repo.AssertNotCalled(t, "ListAfterID", mock.Anything, mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "SetQuarantined", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -2,101 +2,334 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"math/rand"
+	"maps"
+	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
 	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/sammyqtran/url-shortener/internal/device"
+	"github.com/sammyqtran/url-shortener/internal/geoip"
 	"github.com/sammyqtran/url-shortener/internal/metrics"
 	"github.com/sammyqtran/url-shortener/internal/models"
+	"github.com/sammyqtran/url-shortener/internal/passthrough"
 	"github.com/sammyqtran/url-shortener/internal/repository"
+	"github.com/sammyqtran/url-shortener/internal/split"
+	"github.com/sammyqtran/url-shortener/internal/threatlist"
+	"github.com/sammyqtran/url-shortener/internal/urlnorm"
+	"github.com/sammyqtran/url-shortener/internal/urlpolicy"
 	pb "github.com/sammyqtran/url-shortener/proto"
 )
 
 const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 
+// cacheTTL is the default lifetime of a cached URL model
+const cacheTTL = 10 * time.Minute
+
+// page size bounds for ListURLs
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// custom alias bounds, the upper bound must fit the urls.short_code column
+const (
+	minAliasLength = 3
+	maxAliasLength = 32
+)
+
+// errorDomain is the ErrorInfo domain of errors that carry a reason
+const errorDomain = "url-shortener"
+
+// reasonThreatMatch rejects destinations on the threat list, next to the urlpolicy reasons
+const reasonThreatMatch = "threat_match"
+
+// reasonUnresolved labels the rejected-destination metric for hosts that don't resolve
+const reasonUnresolved = "unresolved"
+
+// redirectTypes are the statuses a link may redirect with, 302 unless asked otherwise
+var redirectTypes = map[int]bool{
+	http.StatusMovedPermanently:  true,
+	http.StatusFound:             true,
+	http.StatusTemporaryRedirect: true,
+	http.StatusPermanentRedirect: true,
+}
+
+// maxGeoRoutes bounds the per-country destinations of a link, about one per country
+const maxGeoRoutes = 250
+
+// A/B split bounds, a variant name must fit a metrics label
+const (
+	minVariants          = 2
+	maxVariants          = 10
+	maxVariantWeight     = 1000
+	maxVariantNameLength = 32
+)
+
+// maxScheduleEntries bounds the time windows of a link
+const maxScheduleEntries = 50
+
+// reservedAliases collide with gateway routes and can't be used as short codes
+var reservedAliases = map[string]bool{
+	"create":  true,
+	"healthz": true,
+	"metrics": true,
+	"api":     true,
+}
+
 type URLService struct {
 	pb.UnimplementedURLServiceServer
-	repo          repository.URLRepository
-	baseURL       string
-	codeGenerator func(ctx context.Context) (string, error)
-	cache         *redis.Client
-	Logger        *zap.Logger
-	Metrics       metrics.Metrics
+	repo      repository.URLRepository
+	baseURL   string
+	generator CodeGenerator
+	cache     *redis.Client
+	Logger    *zap.Logger
+	Metrics   metrics.Metrics
+	// URLOptions picks the optional canonicalization steps for destinations
+	URLOptions urlnorm.Options
+	// Policy rejects unsafe destinations, nil accepts any valid URL
+	Policy *urlpolicy.Policy
+	// Threats rejects destinations on the threat list, nil skips the check
+	Threats *threatlist.List
+	// Campaigns tags redirects with UTM parameters, nil rejects campaign_id
+	Campaigns *CampaignService
+	// GeoIP resolves visitor countries for geo_routes, nil sends everyone to original_url
+	GeoIP *geoip.Database
+}
+
+// SetBaseURL sets the prefix of returned short URLs
+func (s *URLService) SetBaseURL(baseURL string) {
+	s.baseURL = strings.TrimSuffix(baseURL, "/") + "/"
 }
 
-func NewURLService(repo repository.URLRepository, cache *redis.Client, logger *zap.Logger, metrics *metrics.PrometheusMetrics) *URLService {
-	service := &URLService{
-		repo:    repo,
-		baseURL: "http://localhost:8080/",
-		cache:   cache,
-		Logger:  logger,
-		Metrics: metrics,
+// NewURLService creates the service, generator picks the short code strategy (see NewCodeGenerator)
+func NewURLService(repo repository.URLRepository, generator CodeGenerator, cache *redis.Client, logger *zap.Logger, metrics *metrics.PrometheusMetrics) *URLService {
+	return &URLService{
+		repo:       repo,
+		baseURL:    "http://localhost:8080/",
+		generator:  generator,
+		cache:      cache,
+		Logger:     logger,
+		Metrics:    metrics,
+		URLOptions: urlnorm.DefaultOptions(),
 	}
-	service.codeGenerator = service.GenerateShortCode
-	return service
 }
 
 func (s *URLService) CreateShortURL(ctx context.Context, req *pb.CreateURLRequest) (*pb.CreateURLResponse, error) {
 	service := "url-service"
 
-	// url validation
-	if err := s.validateURL(req.OriginalUrl); err != nil {
+	// url validation, links store the canonical form
+	originalURL, err := s.canonicalURL(req.OriginalUrl)
+	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid URL: %v", err)
 	}
+	if err := s.checkDestination(ctx, originalURL); err != nil {
+		return nil, err
+	}
 
-	// short code generation
-	shortCode, err := s.codeGenerator(ctx)
+	now := time.Now()
+
+	expiresAt, err := resolveExpiry(req.ExpiresAt, req.TtlSeconds, now)
 	if err != nil {
-		s.Logger.Error("Failed to generate short code", zap.Error(err))
-		return nil, status.Errorf(codes.Internal, "failed to generate short code: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "invalid expiry: %v", err)
 	}
 
-	now := time.Now()
+	redirectType := http.StatusFound
+	if req.RedirectType != 0 {
+		if err := validateRedirectType(req.RedirectType); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid redirect_type: %v", err)
+		}
+		redirectType = int(req.RedirectType)
+	}
+
+	queryPassthrough := passthrough.QueryOff
+	if req.QueryPassthrough != "" {
+		if err := passthrough.ValidateQueryMode(req.QueryPassthrough); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid query_passthrough: %v", err)
+		}
+		queryPassthrough = req.QueryPassthrough
+	}
+
+	var campaignID *int64
+	if req.CampaignId != 0 {
+		if err := s.checkCampaign(ctx, req.CampaignId, req.UserId); err != nil {
+			return nil, err
+		}
+		campaignID = &req.CampaignId
+	}
+
+	geoRoutes, err := s.validateGeoRoutes(ctx, req.GeoRoutes)
+	if err != nil {
+		return nil, err
+	}
+	deviceRoutes, err := s.validateDeviceRoutes(ctx, req.DeviceRoutes)
+	if err != nil {
+		return nil, err
+	}
+	variants, err := s.validateVariants(ctx, req.Variants)
+	if err != nil {
+		return nil, err
+	}
+	activeFrom := unixTime(req.ActiveFrom)
+	if err := checkActiveWindow(activeFrom, expiresAt); err != nil {
+		return nil, err
+	}
+	schedule, err := s.validateSchedule(ctx, req.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	if req.MaxClicks < 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid max_clicks: must be positive")
+	}
+	var maxClicks *int64
+	if req.MaxClicks != 0 {
+		maxClicks = &req.MaxClicks
+	}
+
+	urlHash := hashURL(originalURL)
 
 	// Create URL model
 	urlModel := &models.URL{
-		UserID:      req.UserId,
-		ShortCode:   shortCode,
-		OriginalURL: req.OriginalUrl,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-		ClickCount:  0,
-		// ExpiresAt:   expiresAt,
+		UserID:           req.UserId,
+		OriginalURL:      originalURL,
+		URLHash:          urlHash,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+		ClickCount:       0,
+		ExpiresAt:        expiresAt,
+		Interstitial:     req.Interstitial,
+		RedirectType:     redirectType,
+		QueryPassthrough: queryPassthrough,
+		PathPassthrough:  req.PathPassthrough,
+		CampaignID:       campaignID,
+		GeoRoutes:        geoRoutes,
+		DeviceRoutes:     deviceRoutes,
+		Variants:         variants,
+		ActiveFrom:       activeFrom,
+		Schedule:         schedule,
+		MaxClicks:        maxClicks,
 	}
 
-	// record db operation and duration
-	s.Metrics.IncDBOperation(service, "Create")
-	dbTimer := time.Now()
-	// Save to database
-	if err := s.repo.Create(ctx, urlModel); err != nil {
-		s.Metrics.IncDBError(service, "Create")
-		s.Logger.Error("Failed to create short URL", zap.Error(err))
-		return nil, status.Errorf(codes.Internal, "failed to create URL: %v", err)
+	// an alias asks for a specific code, so dedupe only applies to generated
+	// ones, and the clicks of a click-limited link are never shared
+	if req.Dedupe && req.CustomAlias == "" && maxClicks == nil {
+		existing, err := s.findLiveDuplicate(ctx, req.UserId, urlHash)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && dedupeMatches(existing, urlModel) {
+			s.Logger.Info("Returning existing short URL", zap.String("shortCode", existing.ShortCode), zap.String("userID", req.UserId))
+			return s.newCreateResponse(existing, true), nil
+		}
+	}
+
+	if req.CustomAlias != "" {
+		// custom alias replaces the generated short code
+		if err := validateAlias(req.CustomAlias); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid alias: %v", err)
+		}
+
+		urlModel.ShortCode = req.CustomAlias
+		if err := s.insertURL(ctx, urlModel); err != nil {
+			if errors.Is(err, repository.ErrShortCodeExists) {
+				return nil, status.Errorf(codes.AlreadyExists, "alias %q is already taken", req.CustomAlias)
+			}
+			s.Logger.Error("Failed to create short URL", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "failed to create URL: %v", err)
+		}
+	} else if err := s.createWithGeneratedCode(ctx, urlModel); err != nil {
+		return nil, err
 	}
-	s.Metrics.ObserveDBOperationDuration(service, "Create", time.Since(dbTimer).Seconds())
 
 	// Put in cache
 
-	err = s.setCacheFromModel(ctx, shortCode, urlModel)
+	err = s.setCacheFromModel(ctx, urlModel.ShortCode, urlModel)
 	if err != nil {
 		s.Metrics.IncCacheError(service, "map_url", "set")
 		s.Logger.Error("Failed to cache short URL", zap.Error(err))
 	}
 
-	return &pb.CreateURLResponse{
-		ShortCode: shortCode,
-		ShortUrl:  s.baseURL + shortCode,
-		Success:   true,
-		Error:     "",
-	}, nil
+	return s.newCreateResponse(urlModel, false), nil
+}
+
+func (s *URLService) newCreateResponse(urlModel *models.URL, existing bool) *pb.CreateURLResponse {
+	resp := &pb.CreateURLResponse{
+		ShortCode:        urlModel.ShortCode,
+		ShortUrl:         s.baseURL + urlModel.ShortCode,
+		OriginalUrl:      urlModel.OriginalURL,
+		Success:          true,
+		Error:            "",
+		Existing:         existing,
+		Interstitial:     urlModel.Interstitial,
+		RedirectType:     redirectStatus(urlModel),
+		QueryPassthrough: queryPassthroughMode(urlModel),
+		PathPassthrough:  urlModel.PathPassthrough,
+		CampaignId:       campaignIDOf(urlModel),
+		GeoRoutes:        urlModel.GeoRoutes,
+		DeviceRoutes:     urlModel.DeviceRoutes,
+		Variants:         toVariantProtos(urlModel.Variants),
+		ActiveFrom:       unixOf(urlModel.ActiveFrom),
+		Schedule:         toScheduleProtos(urlModel.Schedule),
+		MaxClicks:        maxClicksOf(urlModel),
+	}
+	if urlModel.ExpiresAt != nil {
+		resp.ExpiresAt = urlModel.ExpiresAt.Unix()
+	}
+	return resp
+}
+
+// dedupeMatches reports whether an existing link behaves the same as the one
+// being created, so handing it back doesn't change what visitors get. A link
+// with another redirect status, interstitial or passthrough would treat
+// clients differently, one in another campaign would tag clicks with the wrong
+// parameters, and one with other routes, variants or times would send
+// visitors elsewhere. Expiry has to match too, so a ttl_seconds create always
+// makes a fresh link.
+func dedupeMatches(existing, candidate *models.URL) bool {
+	return redirectStatus(existing) == redirectStatus(candidate) &&
+		existing.Interstitial == candidate.Interstitial &&
+		queryPassthroughMode(existing) == queryPassthroughMode(candidate) &&
+		existing.PathPassthrough == candidate.PathPassthrough &&
+		campaignIDOf(existing) == campaignIDOf(candidate) &&
+		unixOf(existing.ExpiresAt) == unixOf(candidate.ExpiresAt) &&
+		maps.Equal(existing.GeoRoutes, candidate.GeoRoutes) &&
+		maps.Equal(existing.DeviceRoutes, candidate.DeviceRoutes) &&
+		slices.Equal(existing.Variants, candidate.Variants) &&
+		unixOf(existing.ActiveFrom) == unixOf(candidate.ActiveFrom) &&
+		slices.EqualFunc(existing.Schedule, candidate.Schedule, models.ScheduleEntry.Equal) &&
+		existing.MaxClicks == nil && candidate.MaxClicks == nil
+}
+
+// findLiveDuplicate returns the user's newest non-expired link to the same
+// destination, or nil if there isn't one
+func (s *URLService) findLiveDuplicate(ctx context.Context, userID, urlHash string) (*models.URL, error) {
+	service := "url-service"
+
+	s.Metrics.IncDBOperation(service, "GetLiveByURLHash")
+	dbTimer := time.Now()
+	existing, err := s.repo.GetLiveByURLHash(ctx, userID, urlHash)
+	s.Metrics.ObserveDBOperationDuration(service, "GetLiveByURLHash", time.Since(dbTimer).Seconds())
+	if err != nil {
+		if errors.Is(err, repository.ErrURLNotFound) {
+			return nil, nil
+		}
+		s.Metrics.IncDBError(service, "GetLiveByURLHash")
+		s.Logger.Error("Failed to look up duplicate URL", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to look up existing URL: %v", err)
+	}
+	return existing, nil
 }
 
 func (s *URLService) GetOriginalURL(ctx context.Context, req *pb.GetURLRequest) (*pb.GetURLResponse, error) {
@@ -114,18 +347,26 @@ func (s *URLService) GetOriginalURL(ctx context.Context, req *pb.GetURLRequest)
 			// URL expired - remove from cache
 			s.removeFromCache(ctx, req.ShortCode)
 			return &pb.GetURLResponse{
-				Found: false,
-				Error: "URL has expired",
+				Found:   false,
+				Error:   "URL has expired",
+				Expired: true,
 			}, nil
 		}
+		if !cachedURL.IsActive(time.Now()) {
+			return inactiveResponse(), nil
+		}
+		if req.WithPath && !cachedURL.PathPassthrough {
+			return notFoundResponse(), nil
+		}
+		if cachedURL.QuarantinedAt != nil {
+			return quarantinedResponse(), nil
+		}
 
-		// Increment click count asynchronously (don't block response)
-		go s.incrementClickCountAsync(req.ShortCode)
+		if exhausted, err := s.countClick(ctx, req.ShortCode, cachedURL); exhausted != nil || err != nil {
+			return exhausted, err
+		}
 
-		return &pb.GetURLResponse{
-			OriginalUrl: cachedURL.OriginalURL,
-			Found:       true,
-		}, nil
+		return s.redirectResponse(ctx, req, cachedURL), nil
 	}
 	s.Logger.Info("Cache miss", zap.String("shortCode", req.ShortCode))
 
@@ -138,10 +379,7 @@ func (s *URLService) GetOriginalURL(ctx context.Context, req *pb.GetURLRequest)
 		s.Metrics.IncDBError("url-service", "GetByShortCode")
 		s.Logger.Error("Error retrieving from repository", zap.Error(err))
 		if err == repository.ErrURLNotFound {
-			return &pb.GetURLResponse{
-				Found: false,
-				Error: "URL not found",
-			}, nil
+			return notFoundResponse(), nil
 		}
 		return &pb.GetURLResponse{
 			Found: false,
@@ -151,8 +389,9 @@ func (s *URLService) GetOriginalURL(ctx context.Context, req *pb.GetURLRequest)
 	// Check if the URL has expired
 	if urlModel.ExpiresAt != nil && urlModel.ExpiresAt.Before(time.Now()) {
 		return &pb.GetURLResponse{
-			Found: false,
-			Error: "URL has expired",
+			Found:   false,
+			Error:   "URL has expired",
+			Expired: true,
 		}, nil
 	}
 	s.Logger.Info("Database fetch", zap.String("shortCode", req.ShortCode))
@@ -160,14 +399,214 @@ func (s *URLService) GetOriginalURL(ctx context.Context, req *pb.GetURLRequest)
 	// populate cache from db
 	s.setCacheFromModel(ctx, req.ShortCode, urlModel)
 
-	// increment click count asynchronously
-	go s.incrementClickCountAsync(req.ShortCode)
+	if !urlModel.IsActive(time.Now()) {
+		return inactiveResponse(), nil
+	}
+	if req.WithPath && !urlModel.PathPassthrough {
+		return notFoundResponse(), nil
+	}
+	if urlModel.QuarantinedAt != nil {
+		return quarantinedResponse(), nil
+	}
+
+	if exhausted, err := s.countClick(ctx, req.ShortCode, urlModel); exhausted != nil || err != nil {
+		return exhausted, err
+	}
 
 	// Return the original URL if found
+	return s.redirectResponse(ctx, req, urlModel), nil
+}
+
+// redirectResponse tells the gateway where and how to redirect
+func (s *URLService) redirectResponse(ctx context.Context, req *pb.GetURLRequest, urlModel *models.URL) *pb.GetURLResponse {
+	now := time.Now()
+	country := s.country(req.ClientIp)
+	platform := device.Classify(req.UserAgent)
+	destination := urlModel.OriginalURL
+	variant := ""
+	perVisitor := len(urlModel.GeoRoutes) > 0 || len(urlModel.DeviceRoutes) > 0 || len(urlModel.Variants) > 0
+	if scheduled, ok := urlModel.Schedule.Destination(now); ok {
+		destination = scheduled
+		perVisitor = false
+	} else if routed, ok := urlModel.DeviceRoutes[string(platform)]; ok && platform != device.PlatformUnknown {
+		destination = routed
+	} else if routed, ok := urlModel.GeoRoutes[country]; ok {
+		destination = routed
+	} else if i := split.Pick(urlModel.Variants.Weights(), urlModel.ShortCode, visitorKey(req)); i >= 0 {
+		destination = urlModel.Variants[i].URL
+		variant = urlModel.Variants[i].Name
+	}
+
+	resp := &pb.GetURLResponse{
+		OriginalUrl:      s.campaignDestination(ctx, urlModel, destination),
+		Found:            true,
+		Interstitial:     urlModel.Interstitial,
+		RedirectType:     redirectStatus(urlModel),
+		QueryPassthrough: queryPassthroughMode(urlModel),
+		PathPassthrough:  urlModel.PathPassthrough,
+		CampaignId:       campaignIDOf(urlModel),
+		Country:          country,
+		PerVisitor:       perVisitor,
+		Platform:         string(platform),
+		Variant:          variant,
+		ClickLimited:     urlModel.MaxClicks != nil,
+	}
+	if urlModel.ExpiresAt != nil {
+		resp.ExpiresAt = urlModel.ExpiresAt.Unix()
+	}
+	if next, ok := urlModel.NextChange(now); ok {
+		resp.ChangesAt = next.Unix()
+	}
+	return resp
+}
+
+// visitorKey identifies the visitor for sticky variants, their visitor ID
+// when the gateway sent one, otherwise their address and browser
+func visitorKey(req *pb.GetURLRequest) string {
+	if req.VisitorId != "" {
+		return req.VisitorId
+	}
+	return req.ClientIp + "\x00" + req.UserAgent
+}
+
+// country is the visitor's country, "" when it can't be resolved
+func (s *URLService) country(clientIP string) string {
+	if s.GeoIP == nil || clientIP == "" {
+		return ""
+	}
+	return s.GeoIP.Country(clientIP)
+}
+
+// campaignDestination is destination with the link's campaign's UTM
+// parameters added. A campaign that can't be loaded doesn't stop the
+// redirect, the link just goes out untagged.
+func (s *URLService) campaignDestination(ctx context.Context, urlModel *models.URL, destination string) string {
+	if urlModel.CampaignID == nil || s.Campaigns == nil {
+		return destination
+	}
+
+	campaign, err := s.Campaigns.lookup(ctx, *urlModel.CampaignID)
+	if err != nil {
+		s.Logger.Warn("Failed to load campaign, redirecting without UTM parameters",
+			zap.String("shortCode", urlModel.ShortCode), zap.Int64("campaignID", *urlModel.CampaignID), zap.Error(err))
+		return destination
+	}
+	return appendUTM(destination, campaign)
+}
+
+// checkCampaign makes sure a link can be put in campaign id, it must exist
+// and belong to the link's owner
+func (s *URLService) checkCampaign(ctx context.Context, id int64, userID string) error {
+	if s.Campaigns == nil {
+		return status.Error(codes.Unavailable, "campaigns are not enabled")
+	}
+	if _, err := s.Campaigns.getOwnedCampaign(ctx, id, userID); err != nil {
+		// the link is what's being written, a missing campaign is a bad argument
+		if status.Code(err) == codes.NotFound {
+			return status.Errorf(codes.InvalidArgument, "campaign %d not found", id)
+		}
+		return err
+	}
+	return nil
+}
+
+// campaignIDOf is the link's campaign, 0 for none
+func campaignIDOf(urlModel *models.URL) int64 {
+	if urlModel.CampaignID == nil {
+		return 0
+	}
+	return *urlModel.CampaignID
+}
+
+// notFoundResponse is also used for paths after a link that isn't a
+// wildcard link, /{short_code}/... only exists for those
+func notFoundResponse() *pb.GetURLResponse {
+	return &pb.GetURLResponse{
+		Found: false,
+		Error: "URL not found",
+	}
+}
+
+// queryPassthroughMode is the link's query mode, off for entries cached
+// before links had one
+func queryPassthroughMode(urlModel *models.URL) string {
+	if urlModel.QueryPassthrough == "" {
+		return passthrough.QueryOff
+	}
+	return urlModel.QueryPassthrough
+}
+
+// redirectStatus is the link's redirect status, 302 for entries cached
+// before links had one
+func redirectStatus(urlModel *models.URL) int32 {
+	if urlModel.RedirectType == 0 {
+		return http.StatusFound
+	}
+	return int32(urlModel.RedirectType)
+}
+
+// countClick counts a redirect of the link. Most clicks are counted in the
+// background, but a click-limited link's are claimed before redirecting so
+// only one request can take its last click. Returns the response to send
+// instead of redirecting once it has none left.
+func (s *URLService) countClick(ctx context.Context, shortCode string, urlModel *models.URL) (*pb.GetURLResponse, error) {
+	service := "url-service"
+
+	if urlModel.MaxClicks == nil {
+		go s.incrementClickCountAsync(shortCode)
+		return nil, nil
+	}
+
+	s.Metrics.IncDBOperation(service, "ClaimClick")
+	dbTimer := time.Now()
+	err := s.repo.ClaimClick(ctx, shortCode)
+	s.Metrics.ObserveDBOperationDuration(service, "ClaimClick", time.Since(dbTimer).Seconds())
+	if err != nil {
+		if errors.Is(err, repository.ErrClicksExhausted) {
+			return exhaustedResponse(), nil
+		}
+		s.Metrics.IncDBError(service, "ClaimClick")
+		s.Logger.Error("Failed to claim click", zap.String("shortCode", shortCode), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to count click: %v", err)
+	}
+	return nil, nil
+}
+
+// maxClicksOf is the link's click limit, 0 for none
+func maxClicksOf(urlModel *models.URL) int64 {
+	if urlModel.MaxClicks == nil {
+		return 0
+	}
+	return *urlModel.MaxClicks
+}
+
+// exhaustedResponse is for click-limited links that have used up their clicks
+func exhaustedResponse() *pb.GetURLResponse {
+	return &pb.GetURLResponse{
+		Found:     false,
+		Exhausted: true,
+		Error:     "URL has no clicks left",
+	}
+}
+
+// inactiveResponse is for launch links before their active_from, they
+// don't resolve and aren't counted
+func inactiveResponse() *pb.GetURLResponse {
+	return &pb.GetURLResponse{
+		Found:    false,
+		Inactive: true,
+		Error:    "URL is not active yet",
+	}
+}
+
+// quarantinedResponse tells the gateway to warn instead of redirecting, the
+// destination isn't passed on so it can't be followed
+func quarantinedResponse() *pb.GetURLResponse {
 	return &pb.GetURLResponse{
-		OriginalUrl: urlModel.OriginalURL,
 		Found:       true,
-	}, nil
+		Quarantined: true,
+		Error:       "destination has been flagged as unsafe",
+	}
 }
 
 func (s *URLService) HealthCheck(ctx context.Context, req *pb.HealthRequest) (*pb.HealthResponse, error) {
@@ -176,6 +615,366 @@ func (s *URLService) HealthCheck(ctx context.Context, req *pb.HealthRequest) (*p
 	}, nil
 }
 
+func (s *URLService) UpdateShortURL(ctx context.Context, req *pb.UpdateURLRequest) (*pb.UpdateURLResponse, error) {
+	service := "url-service"
+
+	urlModel, err := s.getOwnedURL(ctx, req.ShortCode, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	destinationsChanged := false
+	if req.OriginalUrl != "" {
+		originalURL, err := s.canonicalURL(req.OriginalUrl)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid URL: %v", err)
+		}
+		if err := s.checkDestination(ctx, originalURL); err != nil {
+			return nil, err
+		}
+		urlModel.OriginalURL = originalURL
+		destinationsChanged = true
+	}
+
+	if req.ClearExpiry {
+		if req.ExpiresAt != 0 || req.TtlSeconds != 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid expiry: clear_expiry cannot be combined with a new expiry")
+		}
+		urlModel.ExpiresAt = nil
+	} else if req.ExpiresAt != 0 || req.TtlSeconds != 0 {
+		expiresAt, err := resolveExpiry(req.ExpiresAt, req.TtlSeconds, time.Now())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid expiry: %v", err)
+		}
+		urlModel.ExpiresAt = expiresAt
+	}
+	if req.ClearActiveFrom {
+		if req.ActiveFrom != 0 {
+			return nil, status.Error(codes.InvalidArgument, "clear_active_from cannot be combined with a new active_from")
+		}
+		urlModel.ActiveFrom = nil
+	} else if req.ActiveFrom != 0 {
+		urlModel.ActiveFrom = unixTime(req.ActiveFrom)
+	}
+	if err := checkActiveWindow(urlModel.ActiveFrom, urlModel.ExpiresAt); err != nil {
+		return nil, err
+	}
+	if req.ClearMaxClicks {
+		if req.MaxClicks != 0 {
+			return nil, status.Error(codes.InvalidArgument, "clear_max_clicks cannot be combined with a new max_clicks")
+		}
+		urlModel.MaxClicks = nil
+	} else if req.MaxClicks < 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid max_clicks: must be positive")
+	} else if req.MaxClicks != 0 {
+		urlModel.MaxClicks = &req.MaxClicks
+	}
+	if req.Interstitial != nil {
+		urlModel.Interstitial = *req.Interstitial
+	}
+	if req.RedirectType != 0 {
+		if err := validateRedirectType(req.RedirectType); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid redirect_type: %v", err)
+		}
+		urlModel.RedirectType = int(req.RedirectType)
+	}
+	if req.QueryPassthrough != "" {
+		if err := passthrough.ValidateQueryMode(req.QueryPassthrough); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid query_passthrough: %v", err)
+		}
+		urlModel.QueryPassthrough = req.QueryPassthrough
+	}
+	if req.PathPassthrough != nil {
+		urlModel.PathPassthrough = *req.PathPassthrough
+	}
+	if req.ClearCampaign {
+		if req.CampaignId != 0 {
+			return nil, status.Error(codes.InvalidArgument, "clear_campaign cannot be combined with a new campaign_id")
+		}
+		urlModel.CampaignID = nil
+	} else if req.CampaignId != 0 {
+		if err := s.checkCampaign(ctx, req.CampaignId, req.UserId); err != nil {
+			return nil, err
+		}
+		urlModel.CampaignID = &req.CampaignId
+	}
+	if req.ClearGeoRoutes {
+		if len(req.GeoRoutes) > 0 {
+			return nil, status.Error(codes.InvalidArgument, "clear_geo_routes cannot be combined with new geo_routes")
+		}
+		urlModel.GeoRoutes = nil
+		destinationsChanged = true
+	} else if len(req.GeoRoutes) > 0 {
+		geoRoutes, err := s.validateGeoRoutes(ctx, req.GeoRoutes)
+		if err != nil {
+			return nil, err
+		}
+		urlModel.GeoRoutes = geoRoutes
+		destinationsChanged = true
+	}
+	if req.ClearDeviceRoutes {
+		if len(req.DeviceRoutes) > 0 {
+			return nil, status.Error(codes.InvalidArgument, "clear_device_routes cannot be combined with new device_routes")
+		}
+		urlModel.DeviceRoutes = nil
+		destinationsChanged = true
+	} else if len(req.DeviceRoutes) > 0 {
+		deviceRoutes, err := s.validateDeviceRoutes(ctx, req.DeviceRoutes)
+		if err != nil {
+			return nil, err
+		}
+		urlModel.DeviceRoutes = deviceRoutes
+		destinationsChanged = true
+	}
+	if req.ClearVariants {
+		if len(req.Variants) > 0 {
+			return nil, status.Error(codes.InvalidArgument, "clear_variants cannot be combined with new variants")
+		}
+		urlModel.Variants = nil
+		destinationsChanged = true
+	} else if len(req.Variants) > 0 {
+		variants, err := s.validateVariants(ctx, req.Variants)
+		if err != nil {
+			return nil, err
+		}
+		urlModel.Variants = variants
+		destinationsChanged = true
+	}
+	if req.ClearSchedule {
+		if len(req.Schedule) > 0 {
+			return nil, status.Error(codes.InvalidArgument, "clear_schedule cannot be combined with a new schedule")
+		}
+		urlModel.Schedule = nil
+		destinationsChanged = true
+	} else if len(req.Schedule) > 0 {
+		schedule, err := s.validateSchedule(ctx, req.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		urlModel.Schedule = schedule
+		destinationsChanged = true
+	}
+	// new destinations passed the threat list, release the link unless a kept one still matches
+	release := urlModel.QuarantinedAt != nil && destinationsChanged && !s.matchesThreatList(urlModel)
+	// reads don't load the hash, always rewrite it so it tracks the destination
+	urlModel.URLHash = hashURL(urlModel.OriginalURL)
+
+	s.Metrics.IncDBOperation(service, "Update")
+	dbTimer := time.Now()
+	err = s.repo.Update(ctx, urlModel)
+	s.Metrics.ObserveDBOperationDuration(service, "Update", time.Since(dbTimer).Seconds())
+	if err != nil {
+		s.Metrics.IncDBError(service, "Update")
+		if errors.Is(err, repository.ErrURLNotFound) {
+			return nil, status.Error(codes.NotFound, "URL not found")
+		}
+		s.Logger.Error("Failed to update URL", zap.String("shortCode", req.ShortCode), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to update URL: %v", err)
+	}
+	urlModel.UpdatedAt = time.Now()
+
+	if release {
+		s.Metrics.IncDBOperation(service, "SetQuarantined")
+		dbTimer := time.Now()
+		err := s.repo.SetQuarantined(ctx, []int64{urlModel.ID}, false)
+		s.Metrics.ObserveDBOperationDuration(service, "SetQuarantined", time.Since(dbTimer).Seconds())
+		if err != nil {
+			s.Metrics.IncDBError(service, "SetQuarantined")
+			s.Logger.Error("Failed to release URL from quarantine", zap.String("shortCode", req.ShortCode), zap.Error(err))
+			s.removeFromCache(ctx, req.ShortCode)
+			return nil, status.Errorf(codes.Internal, "failed to release URL from quarantine: %v", err)
+		}
+		urlModel.QuarantinedAt = nil
+	}
+
+	// drop the stale entry, the next redirect repopulates it
+	s.removeFromCache(ctx, req.ShortCode)
+
+	return &pb.UpdateURLResponse{Url: s.toURLDetails(urlModel)}, nil
+}
+
+func (s *URLService) DeleteShortURL(ctx context.Context, req *pb.DeleteURLRequest) (*pb.DeleteURLResponse, error) {
+	service := "url-service"
+
+	if _, err := s.getOwnedURL(ctx, req.ShortCode, req.UserId); err != nil {
+		return nil, err
+	}
+
+	s.Metrics.IncDBOperation(service, "Delete")
+	dbTimer := time.Now()
+	err := s.repo.Delete(ctx, req.ShortCode)
+	s.Metrics.ObserveDBOperationDuration(service, "Delete", time.Since(dbTimer).Seconds())
+	if err != nil {
+		s.Metrics.IncDBError(service, "Delete")
+		if errors.Is(err, repository.ErrURLNotFound) {
+			return nil, status.Error(codes.NotFound, "URL not found")
+		}
+		s.Logger.Error("Failed to delete URL", zap.String("shortCode", req.ShortCode), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to delete URL: %v", err)
+	}
+
+	s.removeFromCache(ctx, req.ShortCode)
+
+	return &pb.DeleteURLResponse{Success: true}, nil
+}
+
+func (s *URLService) GetURLDetails(ctx context.Context, req *pb.GetURLDetailsRequest) (*pb.GetURLDetailsResponse, error) {
+	urlModel, err := s.getOwnedURL(ctx, req.ShortCode, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetURLDetailsResponse{Url: s.toURLDetails(urlModel)}, nil
+}
+
+func (s *URLService) ListURLs(ctx context.Context, req *pb.ListURLsRequest) (*pb.ListURLsResponse, error) {
+	service := "url-service"
+
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id cannot be empty")
+	}
+	if req.Limit < 0 || req.Offset < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit and offset must not be negative")
+	}
+
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	s.Metrics.IncDBOperation(service, "ListURLsByUser")
+	dbTimer := time.Now()
+	urls, err := s.repo.ListURLsByUser(ctx, req.UserId, limit, int(req.Offset))
+	s.Metrics.ObserveDBOperationDuration(service, "ListURLsByUser", time.Since(dbTimer).Seconds())
+	if err != nil {
+		s.Metrics.IncDBError(service, "ListURLsByUser")
+		s.Logger.Error("Failed to list URLs", zap.String("userID", req.UserId), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to list URLs: %v", err)
+	}
+
+	resp := &pb.ListURLsResponse{Urls: make([]*pb.URLDetails, 0, len(urls))}
+	for _, urlModel := range urls {
+		resp.Urls = append(resp.Urls, s.toURLDetails(urlModel))
+	}
+	return resp, nil
+}
+
+// GetURLPreview summarizes a link for anyone holding its short code. Unlike
+// GetOriginalURL it goes to the database for the click count and doesn't
+// count as a click itself.
+func (s *URLService) GetURLPreview(ctx context.Context, req *pb.GetURLPreviewRequest) (*pb.GetURLPreviewResponse, error) {
+	service := "url-service"
+
+	if req.ShortCode == "" {
+		return nil, status.Error(codes.InvalidArgument, "short_code cannot be empty")
+	}
+
+	s.Metrics.IncDBOperation(service, "GetStats")
+	dbTimer := time.Now()
+	urlModel, err := s.repo.GetStats(ctx, req.ShortCode)
+	s.Metrics.ObserveDBOperationDuration(service, "GetStats", time.Since(dbTimer).Seconds())
+	if err != nil {
+		if errors.Is(err, repository.ErrURLNotFound) {
+			return nil, status.Error(codes.NotFound, "URL not found")
+		}
+		s.Metrics.IncDBError(service, "GetStats")
+		s.Logger.Error("Failed to load URL preview", zap.String("shortCode", req.ShortCode), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to load URL: %v", err)
+	}
+
+	now := time.Now()
+	resp := &pb.GetURLPreviewResponse{
+		ShortCode:    urlModel.ShortCode,
+		OriginalUrl:  urlModel.OriginalURL,
+		CreatedAt:    urlModel.CreatedAt.Unix(),
+		ClickCount:   urlModel.ClickCount,
+		Expired:      urlModel.ExpiresAt != nil && urlModel.ExpiresAt.Before(now),
+		Quarantined:  urlModel.QuarantinedAt != nil,
+		Interstitial: urlModel.Interstitial,
+		ActiveFrom:   unixOf(urlModel.ActiveFrom),
+		Inactive:     !urlModel.IsActive(now),
+		MaxClicks:    maxClicksOf(urlModel),
+		Exhausted:    urlModel.MaxClicks != nil && urlModel.ClickCount >= *urlModel.MaxClicks,
+	}
+	if urlModel.ExpiresAt != nil {
+		resp.ExpiresAt = urlModel.ExpiresAt.Unix()
+	}
+	if scheduled, ok := urlModel.Schedule.Destination(now); ok {
+		resp.OriginalUrl = scheduled
+	}
+	// same as redirects, a flagged destination is never handed out, and a
+	// launch link's destination stays secret until launch. A click-limited
+	// link's is only for whoever uses up a click.
+	if resp.Quarantined || resp.Inactive || resp.MaxClicks != 0 {
+		resp.OriginalUrl = ""
+	}
+	return resp, nil
+}
+
+// getOwnedURL loads a URL for a management call and checks it belongs to userID.
+// Errors are already gRPC statuses.
+func (s *URLService) getOwnedURL(ctx context.Context, shortCode, userID string) (*models.URL, error) {
+	service := "url-service"
+
+	if shortCode == "" {
+		return nil, status.Error(codes.InvalidArgument, "short_code cannot be empty")
+	}
+	if userID == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id cannot be empty")
+	}
+
+	s.Metrics.IncDBOperation(service, "GetStats")
+	dbTimer := time.Now()
+	urlModel, err := s.repo.GetStats(ctx, shortCode)
+	s.Metrics.ObserveDBOperationDuration(service, "GetStats", time.Since(dbTimer).Seconds())
+	if err != nil {
+		if errors.Is(err, repository.ErrURLNotFound) {
+			return nil, status.Error(codes.NotFound, "URL not found")
+		}
+		s.Metrics.IncDBError(service, "GetStats")
+		s.Logger.Error("Failed to load URL", zap.String("shortCode", shortCode), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to load URL: %v", err)
+	}
+
+	if urlModel.UserID != userID {
+		return nil, status.Error(codes.PermissionDenied, "URL belongs to another user")
+	}
+	return urlModel, nil
+}
+
+// toURLDetails converts a URL model to its protobuf representation.
+func (s *URLService) toURLDetails(urlModel *models.URL) *pb.URLDetails {
+	details := &pb.URLDetails{
+		Id:               urlModel.ID,
+		ShortCode:        urlModel.ShortCode,
+		ShortUrl:         s.baseURL + urlModel.ShortCode,
+		OriginalUrl:      urlModel.OriginalURL,
+		UserId:           urlModel.UserID,
+		CreatedAt:        urlModel.CreatedAt.Unix(),
+		UpdatedAt:        urlModel.UpdatedAt.Unix(),
+		ClickCount:       urlModel.ClickCount,
+		Quarantined:      urlModel.QuarantinedAt != nil,
+		Interstitial:     urlModel.Interstitial,
+		RedirectType:     redirectStatus(urlModel),
+		QueryPassthrough: queryPassthroughMode(urlModel),
+		PathPassthrough:  urlModel.PathPassthrough,
+		CampaignId:       campaignIDOf(urlModel),
+		GeoRoutes:        urlModel.GeoRoutes,
+		DeviceRoutes:     urlModel.DeviceRoutes,
+		Variants:         toVariantProtos(urlModel.Variants),
+		ActiveFrom:       unixOf(urlModel.ActiveFrom),
+		Schedule:         toScheduleProtos(urlModel.Schedule),
+		MaxClicks:        maxClicksOf(urlModel),
+	}
+	if urlModel.ExpiresAt != nil {
+		details.ExpiresAt = urlModel.ExpiresAt.Unix()
+	}
+	return details
+}
+
 // validateURL checks if the provided URL is valid.
 func (s *URLService) validateURL(urlStr string) error {
 	if urlStr == "" {
@@ -197,35 +996,414 @@ func (s *URLService) validateURL(urlStr string) error {
 	return nil
 }
 
-// Temporary short code generator - will be improved later
-func generateRandomCode() string {
+// canonicalURL validates rawURL and returns its canonical form (see urlnorm.Normalize)
+func (s *URLService) canonicalURL(rawURL string) (string, error) {
+	if err := s.validateURL(rawURL); err != nil {
+		return "", err
+	}
+	return urlnorm.Normalize(rawURL, s.URLOptions)
+}
+
+// checkDestination applies the threat list and the destination policy.
+// Rejections are PermissionDenied with an ErrorInfo whose reason names the
+// broken rule, hosts that don't resolve are InvalidArgument.
+func (s *URLService) checkDestination(ctx context.Context, canonicalURL string) error {
+	// checked first so known-bad hosts are never resolved
+	if s.Threats != nil {
+		if expression, ok := s.Threats.Match(canonicalURL); ok {
+			return s.rejectDestination(reasonThreatMatch, "destination is on the threat list", map[string]string{"expression": expression})
+		}
+	}
+
+	if s.Policy == nil {
+		return nil
+	}
+
+	err := s.Policy.Check(ctx, canonicalURL)
+	var violation *urlpolicy.Violation
+	if !errors.As(err, &violation) {
+		if errors.Is(err, urlpolicy.ErrUnresolvedHost) {
+			s.Metrics.IncDestinationRejected("url-service", reasonUnresolved)
+			return status.Error(codes.InvalidArgument, "destination host does not resolve")
+		}
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid URL: %v", err)
+		}
+		return nil
+	}
+	return s.rejectDestination(string(violation.Reason), violation.Error(), map[string]string{"host": violation.Host})
+}
+
+// validateGeoRoutes checks the countries and destinations of geo routes,
+// destinations get the same checks as original_url. Errors are already gRPC
+// statuses.
+func (s *URLService) validateGeoRoutes(ctx context.Context, routes map[string]string) (models.Routes, error) {
+	if len(routes) == 0 {
+		return nil, nil
+	}
+	if len(routes) > maxGeoRoutes {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid geo_routes: at most %d countries", maxGeoRoutes)
+	}
+
+	geoRoutes := make(models.Routes, len(routes))
+	for code, destination := range routes {
+		country, err := geoip.NormalizeCountry(code)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid geo_routes: %v", err)
+		}
+		if _, dup := geoRoutes[country]; dup {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid geo_routes: %s is listed twice", country)
+		}
+
+		canonical, err := s.canonicalURL(destination)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid URL for %s: %v", country, err)
+		}
+		if err := s.checkDestination(ctx, canonical); err != nil {
+			return nil, err
+		}
+		geoRoutes[country] = canonical
+	}
+	return geoRoutes, nil
+}
+
+// validateDeviceRoutes checks the platforms and destinations of device
+// routes. Destinations get the same checks as original_url, except that
+// android may open an app with an intent URL, whose browser fallback is
+// checked instead. Errors are already gRPC statuses.
+func (s *URLService) validateDeviceRoutes(ctx context.Context, routes map[string]string) (models.Routes, error) {
+	if len(routes) == 0 {
+		return nil, nil
+	}
+
+	deviceRoutes := make(models.Routes, len(routes))
+	for name, destination := range routes {
+		platform, err := device.ParsePlatform(name)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid device_routes: %v", err)
+		}
+		if _, dup := deviceRoutes[string(platform)]; dup {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid device_routes: %s is listed twice", platform)
+		}
+
+		if device.IsIntentURL(destination) {
+			if platform != device.PlatformAndroid {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid device_routes: intent URLs only work on %s", device.PlatformAndroid)
+			}
+			fallback, err := device.ParseIntentURL(destination)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid device_routes: %v", err)
+			}
+			if fallback != "" {
+				canonical, err := s.canonicalURL(fallback)
+				if err != nil {
+					return nil, status.Errorf(codes.InvalidArgument, "invalid browser fallback URL for %s: %v", platform, err)
+				}
+				if err := s.checkDestination(ctx, canonical); err != nil {
+					return nil, err
+				}
+			}
+			deviceRoutes[string(platform)] = destination
+			continue
+		}
+
+		canonical, err := s.canonicalURL(destination)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid URL for %s: %v", platform, err)
+		}
+		if err := s.checkDestination(ctx, canonical); err != nil {
+			return nil, err
+		}
+		deviceRoutes[string(platform)] = canonical
+	}
+	return deviceRoutes, nil
+}
+
+// validateVariants checks the names, weights and destinations of an A/B
+// split, destinations get the same checks as original_url. Errors are
+// already gRPC statuses.
+func (s *URLService) validateVariants(ctx context.Context, variants []*pb.Variant) (models.Variants, error) {
+	if len(variants) == 0 {
+		return nil, nil
+	}
+	if len(variants) < minVariants || len(variants) > maxVariants {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid variants: must have between %d and %d", minVariants, maxVariants)
+	}
+
+	validated := make(models.Variants, 0, len(variants))
+	seen := make(map[string]bool, len(variants))
+	for _, variant := range variants {
+		if err := validateVariantName(variant.Name); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid variants: %v", err)
+		}
+		if seen[variant.Name] {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid variants: %s is listed twice", variant.Name)
+		}
+		seen[variant.Name] = true
+		if variant.Weight < 1 || variant.Weight > maxVariantWeight {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid variants: weight of %s must be between 1 and %d", variant.Name, maxVariantWeight)
+		}
+
+		canonical, err := s.canonicalURL(variant.Url)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid URL for %s: %v", variant.Name, err)
+		}
+		if err := s.checkDestination(ctx, canonical); err != nil {
+			return nil, err
+		}
+		validated = append(validated, models.Variant{Name: variant.Name, URL: canonical, Weight: int(variant.Weight)})
+	}
+	return validated, nil
+}
+
+// validateVariantName checks a variant name is short and safe to report
+func validateVariantName(name string) error {
+	if name == "" || len(name) > maxVariantNameLength {
+		return fmt.Errorf("names must be between 1 and %d characters", maxVariantNameLength)
+	}
+	for _, c := range name {
+		if !strings.ContainsRune(charset, c) && c != '-' && c != '_' {
+			return fmt.Errorf("name %q may only contain letters, digits, '-' and '_'", name)
+		}
+	}
+	return nil
+}
+
+// toVariantProtos converts an A/B split to its protobuf representation
+func toVariantProtos(variants models.Variants) []*pb.Variant {
+	if len(variants) == 0 {
+		return nil
+	}
+	protos := make([]*pb.Variant, len(variants))
+	for i, variant := range variants {
+		protos[i] = &pb.Variant{Name: variant.Name, Url: variant.URL, Weight: int32(variant.Weight)}
+	}
+	return protos
+}
 
-	const length = 6
+// validateSchedule checks the windows and destinations of a schedule,
+// destinations get the same checks as original_url. Entries keep their
+// order, the first one containing the current time wins. Errors are
+// already gRPC statuses.
+func (s *URLService) validateSchedule(ctx context.Context, entries []*pb.ScheduleEntry) (models.Schedule, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	if len(entries) > maxScheduleEntries {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid schedule: at most %d entries", maxScheduleEntries)
+	}
 
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = charset[rand.Intn(len(charset))]
+	schedule := make(models.Schedule, 0, len(entries))
+	for i, entry := range entries {
+		if entry.Start == 0 && entry.End == 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid schedule: entry %d needs a start or an end", i)
+		}
+		if entry.Start < 0 || entry.End < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid schedule: entry %d has a negative time", i)
+		}
+		if entry.Start != 0 && entry.End != 0 && entry.End <= entry.Start {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid schedule: entry %d ends before it starts", i)
+		}
+
+		canonical, err := s.canonicalURL(entry.Url)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid URL for schedule entry %d: %v", i, err)
+		}
+		if err := s.checkDestination(ctx, canonical); err != nil {
+			return nil, err
+		}
+		schedule = append(schedule, models.ScheduleEntry{Start: unixTime(entry.Start), End: unixTime(entry.End), URL: canonical})
+	}
+	return schedule, nil
+}
+
+// toScheduleProtos converts a schedule to its protobuf representation
+func toScheduleProtos(schedule models.Schedule) []*pb.ScheduleEntry {
+	if len(schedule) == 0 {
+		return nil
+	}
+	protos := make([]*pb.ScheduleEntry, len(schedule))
+	for i, entry := range schedule {
+		protos[i] = &pb.ScheduleEntry{Start: unixOf(entry.Start), End: unixOf(entry.End), Url: entry.URL}
+	}
+	return protos
+}
+
+// checkActiveWindow rejects launch links that would expire before they resolve
+func checkActiveWindow(activeFrom, expiresAt *time.Time) error {
+	if activeFrom != nil && expiresAt != nil && !activeFrom.Before(*expiresAt) {
+		return status.Error(codes.InvalidArgument, "invalid active_from: must be before the expiry")
+	}
+	return nil
+}
+
+// unixTime converts unix seconds to a time, treating 0 as unset
+func unixTime(seconds int64) *time.Time {
+	if seconds == 0 {
+		return nil
 	}
-	return string(b)
+	t := time.Unix(seconds, 0).UTC()
+	return &t
 }
 
-func (s *URLService) GenerateShortCode(ctx context.Context) (string, error) {
-	// In a real application, you would check for uniqueness in the database.
-	// Here we just generate a random code.
-	const maxAttempts = 10
-	for attempts := 0; attempts < maxAttempts; attempts++ {
-		shortCode := generateRandomCode()
-		exists, err := s.repo.IsShortCodeExists(ctx, shortCode)
+// unixOf converts a time to unix seconds, 0 when unset
+func unixOf(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+// matchesThreatList reports whether any destination of the link is on the threat list
+func (s *URLService) matchesThreatList(urlModel *models.URL) bool {
+	if s.Threats == nil {
+		return false
+	}
+	for _, destination := range urlModel.Destinations() {
+		if _, match := s.Threats.Match(destination); match {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *URLService) rejectDestination(reason, msg string, metadata map[string]string) error {
+	s.Metrics.IncDestinationRejected("url-service", reason)
+	s.Logger.Info("Rejected destination", zap.String("reason", reason), zap.Any("details", metadata))
+
+	st := status.New(codes.PermissionDenied, msg)
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   strings.ToUpper(reason),
+		Domain:   errorDomain,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// hashURL returns the hex SHA-256 of a canonical URL, stored as urls.url_hash
+func hashURL(canonicalURL string) string {
+	sum := sha256.Sum256([]byte(canonicalURL))
+	return hex.EncodeToString(sum[:])
+}
+
+// resolveExpiry turns the absolute expiry or relative TTL of a create request into an expiry time.
+// It returns nil when the link should never expire.
+func resolveExpiry(expiresAtUnix, ttlSeconds int64, now time.Time) (*time.Time, error) {
+	if expiresAtUnix != 0 && ttlSeconds != 0 {
+		return nil, fmt.Errorf("expires_at and ttl_seconds are mutually exclusive")
+	}
+
+	switch {
+	case ttlSeconds < 0:
+		return nil, fmt.Errorf("ttl_seconds must be positive")
+	case ttlSeconds > 0:
+		expiresAt := now.Add(time.Duration(ttlSeconds) * time.Second)
+		return &expiresAt, nil
+	case expiresAtUnix != 0:
+		expiresAt := time.Unix(expiresAtUnix, 0)
+		if !expiresAt.After(now) {
+			return nil, fmt.Errorf("expires_at must be in the future")
+		}
+		return &expiresAt, nil
+	}
+	return nil, nil
+}
+
+// validateAlias checks a custom alias against the allowed charset, length bounds and reserved words.
+func validateAlias(alias string) error {
+	if len(alias) < minAliasLength || len(alias) > maxAliasLength {
+		return fmt.Errorf("alias must be between %d and %d characters", minAliasLength, maxAliasLength)
+	}
+
+	for _, c := range alias {
+		if !strings.ContainsRune(charset, c) && c != '-' && c != '_' {
+			return fmt.Errorf("alias may only contain letters, digits, '-' and '_'")
+		}
+	}
+
+	if reservedAliases[strings.ToLower(alias)] {
+		return fmt.Errorf("alias %q is reserved", alias)
+	}
+	return nil
+}
+
+// validateRedirectType checks a requested redirect status is one links can use.
+func validateRedirectType(redirectType int32) error {
+	if !redirectTypes[int(redirectType)] {
+		return fmt.Errorf("must be 301, 302, 307 or 308, got %d", redirectType)
+	}
+	return nil
+}
+
+// maxCreateAttempts bounds how many generated codes CreateShortURL tries
+const maxCreateAttempts = 10
+
+// createWithGeneratedCode inserts urlModel under generated short codes until
+// one isn't taken. The unique index on short_code decides, so replicas racing
+// for the same code can't both win it.
+func (s *URLService) createWithGeneratedCode(ctx context.Context, urlModel *models.URL) error {
+	observer, _ := s.generator.(CollisionObserver)
+
+	for attempt := 1; attempt <= maxCreateAttempts; attempt++ {
+		shortCode, err := s.generator.Generate(ctx)
 		if err != nil {
-			return "", fmt.Errorf("error checking short code existence: %w", err)
+			s.Logger.Error("Failed to generate short code", zap.Error(err))
+			return status.Errorf(codes.Internal, "failed to generate short code: %v", err)
 		}
 
-		if !exists {
-			return shortCode, nil
+		// generated codes must not shadow gateway routes either
+		if reservedAliases[strings.ToLower(shortCode)] {
+			s.observeCollision(observer, "reserved")
+			continue
 		}
+
+		urlModel.ShortCode = shortCode
+		err = s.insertURL(ctx, urlModel)
+		if errors.Is(err, repository.ErrShortCodeExists) {
+			s.observeCollision(observer, "taken")
+			s.Logger.Info("Short code collision", zap.String("shortCode", shortCode), zap.Int("attempt", attempt))
+			continue
+		}
+		if err != nil {
+			s.Logger.Error("Failed to create short URL", zap.Error(err))
+			return status.Errorf(codes.Internal, "failed to create URL: %v", err)
+		}
+
+		if observer != nil {
+			observer.ObserveCollision(false)
+		}
+		return nil
 	}
 
-	return "", fmt.Errorf("failed to generate a unique short code after %d attempts", maxAttempts)
+	s.Logger.Error("Ran out of short code attempts", zap.Int("attempts", maxCreateAttempts))
+	return status.Errorf(codes.Internal, "failed to generate a unique short code after %d attempts", maxCreateAttempts)
+}
+
+func (s *URLService) observeCollision(observer CollisionObserver, reason string) {
+	s.Metrics.IncShortCodeCollision("url-service", reason)
+	if observer != nil {
+		observer.ObserveCollision(true)
+	}
+}
+
+// insertURL saves urlModel, a taken short code comes back as repository.ErrShortCodeExists
+func (s *URLService) insertURL(ctx context.Context, urlModel *models.URL) error {
+	service := "url-service"
+
+	// record db operation and duration
+	s.Metrics.IncDBOperation(service, "Create")
+	dbTimer := time.Now()
+	err := s.repo.Create(ctx, urlModel)
+	s.Metrics.ObserveDBOperationDuration(service, "Create", time.Since(dbTimer).Seconds())
+
+	// conflicts are expected and counted as collisions, not DB errors
+	if err != nil && !errors.Is(err, repository.ErrShortCodeExists) {
+		s.Metrics.IncDBError(service, "Create")
+	}
+	return err
 }
 
 // async function to increment click count
@@ -250,8 +1428,26 @@ func (s *URLService) setCacheFromModel(ctx context.Context, shortCode string, ur
 		return fmt.Errorf("failed to marshal cache data for %s: %w", shortCode, err)
 	}
 
-	// Set cache with TTL
-	err = s.cache.Set(ctx, cacheKey, data, 10*time.Minute).Err()
+	// Set cache with TTL, never outliving the link itself or the
+	// destination it resolves to now
+	ttl := cacheTTL
+	if urlModel.ExpiresAt != nil {
+		remaining := time.Until(*urlModel.ExpiresAt)
+		if remaining <= 0 {
+			return nil
+		}
+		if remaining < ttl {
+			ttl = remaining
+		}
+	}
+	if next, ok := urlModel.NextChange(time.Now()); ok {
+		ttl = min(ttl, time.Until(next))
+		if ttl <= 0 {
+			return nil
+		}
+	}
+
+	err = s.cache.Set(ctx, cacheKey, data, ttl).Err()
 	if err != nil {
 		s.Logger.Error("Failed to set cache", zap.String("shortCode", shortCode), zap.Error(err))
 		return fmt.Errorf("failed to set cache for %s: %w", shortCode, err)
@@ -3,19 +3,38 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"net/http"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/go-redis/redismock/v9"
+	"github.com/redis/go-redis/v9"
+	"github.com/sammyqtran/url-shortener/internal/geoip"
 	"github.com/sammyqtran/url-shortener/internal/metrics"
 	"github.com/sammyqtran/url-shortener/internal/models"
+	"github.com/sammyqtran/url-shortener/internal/passthrough"
 	"github.com/sammyqtran/url-shortener/internal/repository"
+	"github.com/sammyqtran/url-shortener/internal/threatlist"
+	"github.com/sammyqtran/url-shortener/internal/urlnorm"
+	"github.com/sammyqtran/url-shortener/internal/urlpolicy"
 	pb "github.com/sammyqtran/url-shortener/proto"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 type MockRepo struct {
@@ -36,16 +55,32 @@ func (m *MockRepo) GetByShortCode(ctx context.Context, shortCode string) (*model
 	return nil, args.Error(1)
 }
 
+func (m *MockRepo) GetLiveByURLHash(ctx context.Context, userID, urlHash string) (*models.URL, error) {
+	args := m.Called(ctx, userID, urlHash)
+
+	if url, ok := args.Get(0).(*models.URL); ok {
+		return url, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockRepo) GetByID(ctx context.Context, id int64) (*models.URL, error) {
 	return nil, nil
 }
 
 func (m *MockRepo) Update(ctx context.Context, url *models.URL) error {
-	return nil
+	args := m.Called(ctx, url)
+	return args.Error(0)
 }
 
 func (m *MockRepo) Delete(ctx context.Context, shortCode string) error {
-	return nil
+	args := m.Called(ctx, shortCode)
+	return args.Error(0)
+}
+
+func (m *MockRepo) ClaimClick(ctx context.Context, shortCode string) error {
+	args := m.Called(ctx, shortCode)
+	return args.Error(0)
 }
 
 func (m *MockRepo) IncrementClickCount(ctx context.Context, shortCode string) error {
@@ -54,7 +89,12 @@ func (m *MockRepo) IncrementClickCount(ctx context.Context, shortCode string) er
 
 // GetStats returns URL statistics
 func (m *MockRepo) GetStats(ctx context.Context, shortCode string) (*models.URL, error) {
-	return nil, nil
+	args := m.Called(ctx, shortCode)
+
+	if url, ok := args.Get(0).(*models.URL); ok {
+		return url, args.Error(1)
+	}
+	return nil, args.Error(1)
 }
 
 // ListURLs returns paginated list of URLs
@@ -62,6 +102,35 @@ func (m *MockRepo) ListURLs(ctx context.Context, limit, offset int) ([]*models.U
 	return nil, nil
 }
 
+// ListURLsByUser returns paginated list of URLs owned by a user
+func (m *MockRepo) ListURLsByUser(ctx context.Context, userID string, limit, offset int) ([]*models.URL, error) {
+	args := m.Called(ctx, userID, limit, offset)
+
+	if urls, ok := args.Get(0).([]*models.URL); ok {
+		return urls, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockRepo) ListAfterID(ctx context.Context, afterID int64, limit int) ([]*models.URL, error) {
+	args := m.Called(ctx, afterID, limit)
+
+	if urls, ok := args.Get(0).([]*models.URL); ok {
+		return urls, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockRepo) SetQuarantined(ctx context.Context, ids []int64, quarantined bool) error {
+	args := m.Called(ctx, ids, quarantined)
+	return args.Error(0)
+}
+
+func (m *MockRepo) NextShortCodeID(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // IsShortCodeExists checks if short code already exists
 func (m *MockRepo) IsShortCodeExists(ctx context.Context, shortCode string) (bool, error) {
 	args := m.Called(ctx, shortCode)
@@ -69,7 +138,8 @@ func (m *MockRepo) IsShortCodeExists(ctx context.Context, shortCode string) (boo
 }
 
 func TestGenerateRandomCode(t *testing.T) {
-	shortCode := generateRandomCode()
+	shortCode, err := NewRandomCodeGenerator(charset, 6).Generate(context.Background())
+	require.NoError(t, err)
 
 	if len(shortCode) != 6 {
 		t.Errorf("expected length 6, got %d", len(shortCode))
@@ -84,55 +154,6 @@ func TestGenerateRandomCode(t *testing.T) {
 	}
 }
 
-func TestGenerateShortCode(t *testing.T) {
-	mockRepo := new(MockRepo)
-	service := &URLService{
-		repo:    mockRepo,
-		baseURL: "https://localhost:8080",
-		Logger:  zap.NewNop(),
-	}
-
-	mockRepo.On("IsShortCodeExists", mock.Anything, mock.AnythingOfType("string")).Return(false, nil)
-
-	shortCode, err := service.GenerateShortCode(context.Background())
-
-	require.NoError(t, err)
-	require.NotEmpty(t, shortCode)
-
-}
-
-func TestGenerateShortCode_FailedGeneration(t *testing.T) {
-	mockMetrics := &metrics.NoopMetrics{}
-	mockRepo := new(MockRepo)
-	service := &URLService{
-		repo:    mockRepo,
-		baseURL: "https://localhost:8080",
-		Logger:  zap.NewNop(),
-		Metrics: mockMetrics,
-	}
-
-	mockRepo.On("IsShortCodeExists", mock.Anything, mock.AnythingOfType("string")).Return(true, nil)
-
-	shortCode, err := service.GenerateShortCode(context.Background())
-
-	require.Error(t, err)
-	require.Empty(t, shortCode)
-
-	mockRepo = new(MockRepo)
-	service = &URLService{
-		repo:    mockRepo,
-		baseURL: "https://localhost:8080",
-		Logger:  zap.NewNop(),
-	}
-
-	mockRepo.On("IsShortCodeExists", mock.Anything, mock.AnythingOfType("string")).Return(false, fmt.Errorf("random error"))
-	shortCode, err = service.GenerateShortCode(context.Background())
-
-	require.Error(t, err)
-	require.Empty(t, shortCode)
-	require.Contains(t, err.Error(), "error checking short code existence:")
-}
-
 func TestValidateURL(t *testing.T) {
 
 	tests := []struct {
@@ -197,6 +218,55 @@ func TestValidateURL(t *testing.T) {
 	}
 }
 
+func TestCanonicalURL(t *testing.T) {
+	service := &URLService{Logger: zap.NewNop(), URLOptions: urlnorm.DefaultOptions()}
+
+	canonical, err := service.canonicalURL("HTTP://Example.com:80/a/../b?")
+	require.NoError(t, err)
+	require.Equal(t, "http://example.com/b", canonical)
+
+	_, err = service.canonicalURL("javascript://example.com/%0Aalert(1)")
+	require.ErrorIs(t, err, urlnorm.ErrUnsupportedScheme)
+
+	// validateURL still runs first
+	_, err = service.canonicalURL("https://localhost/")
+	require.ErrorContains(t, err, "valid domain")
+
+	service.URLOptions.StripFragment = true
+	canonical, err = service.canonicalURL("https://example.com/docs#install")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/docs", canonical)
+}
+
+func TestValidateAlias(t *testing.T) {
+	tests := []struct {
+		name  string
+		alias string
+		err   string
+	}{
+		{name: "valid", alias: "spring-sale"},
+		{name: "valid underscore", alias: "Spring_2025"},
+		{name: "too short", alias: "ab", err: "between"},
+		{name: "too long", alias: strings.Repeat("a", maxAliasLength+1), err: "between"},
+		{name: "bad charset", alias: "spring sale!", err: "may only contain"},
+		{name: "path separator", alias: "spring/sale", err: "may only contain"},
+		{name: "reserved", alias: "create", err: "reserved"},
+		{name: "reserved case insensitive", alias: "HealthZ", err: "reserved"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateAlias(tc.alias)
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+			}
+		})
+	}
+}
+
 func TestHealthCheck(t *testing.T) {
 	mockMetrics := &metrics.NoopMetrics{}
 	repo := new(MockRepo)
@@ -308,6 +378,7 @@ func TestGetOriginalURL(t *testing.T) {
 				require.NotNil(t, resp)
 				require.False(t, resp.Found)
 				require.Contains(t, resp.Error, "URL has expired")
+				require.True(t, resp.Expired)
 			},
 		},
 		{
@@ -347,6 +418,7 @@ func TestGetOriginalURL(t *testing.T) {
 				require.NotNil(t, resp)
 				require.False(t, resp.Found)
 				require.Contains(t, resp.Error, "URL has expired")
+				require.True(t, resp.Expired)
 			},
 			hitCache: true,
 		},
@@ -400,171 +472,2179 @@ func TestGetOriginalURL(t *testing.T) {
 	}
 }
 
-func TestCreateShortURL(t *testing.T) {
-	tests := []struct {
-		name          string
-		codeGenerator func(ctx context.Context) (string, error)
-		mockSetup     func(m *MockRepo, mockRedis redismock.ClientMock)
-		request       *pb.CreateURLRequest
-		expectError   bool
-		checkResponse func(t *testing.T, resp *pb.CreateURLResponse, err error)
-	}{
-		{
-			name: "success",
-			codeGenerator: func(ctx context.Context) (string, error) {
-				return "abc123", nil
-			},
-			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
-				m.On("Create", mock.Anything, mock.AnythingOfType("*models.URL")).Return(nil)
-				mockRedis.ExpectSet("abc123", mock.Anything, 10*time.Minute).SetVal("OK")
-
-			},
-			request: &pb.CreateURLRequest{
-				OriginalUrl: "https://google.com",
-				UserId:      "user123",
-			},
-			expectError: false,
-			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
-				require.NoError(t, err)
-				require.NotNil(t, resp)
-				require.True(t, resp.Success)
-				require.Equal(t, "abc123", resp.ShortCode)
-				require.Equal(t, "https://localhost:8080/abc123", resp.ShortUrl)
-			},
-		},
-		{
-			name:      "invalid url",
-			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {},
-			codeGenerator: func(ctx context.Context) (string, error) {
-				return "abc123", nil
-			},
-			request: &pb.CreateURLRequest{
-				OriginalUrl: "https://",
-				UserId:      "user123",
-			},
-			expectError: true,
-			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
-				require.Error(t, err)
-				require.Nil(t, resp)
-				require.Contains(t, err.Error(), "invalid URL:")
-			},
-		},
-		{
-			name: "failed code generation",
-			codeGenerator: func(ctx context.Context) (string, error) {
-				return "", fmt.Errorf("failed to generate a unique short code after 10 attempts")
-			},
-			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
-			},
-			request: &pb.CreateURLRequest{
-				OriginalUrl: "https://google.com",
-				UserId:      "user123",
-			},
-			expectError: true,
-			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
-				require.Error(t, err)
-				require.Nil(t, resp)
-				require.Contains(t, err.Error(), "failed to generate a unique short code")
-			},
-		},
-		{
-			name: "failed save to db",
-			codeGenerator: func(ctx context.Context) (string, error) {
-				return "abc123", nil
-			},
-			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
-				m.On("Create", mock.Anything, mock.AnythingOfType("*models.URL")).Return(fmt.Errorf("random failure"))
-			},
-			request: &pb.CreateURLRequest{
-				OriginalUrl: "https://google.com",
-				UserId:      "user123",
-			},
-			expectError: true,
-			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
-				require.Error(t, err)
-				require.Nil(t, resp)
-				require.Contains(t, err.Error(), "failed to create URL:")
-			},
-		},
+func TestGetOriginalURL_Quarantined(t *testing.T) {
+	quarantined := &models.URL{
+		ShortCode:     "abc123",
+		OriginalURL:   "https://evil.example/login",
+		QuarantinedAt: ptrTime(time.Now().Add(-time.Minute)),
 	}
+	data, err := json.Marshal(quarantined)
+	require.NoError(t, err)
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
+	for _, cached := range []bool{true, false} {
+		t.Run(fmt.Sprintf("cached=%v", cached), func(t *testing.T) {
+			db, mockClient := redismock.NewClientMock()
 			repo := new(MockRepo)
-
-			cache, mockRedis := redismock.NewClientMock()
-			tt.mockSetup(repo, mockRedis)
-			mockMetrics := &metrics.NoopMetrics{}
 			service := &URLService{
-				repo:          repo,
-				baseURL:       "https://localhost:8080/",
-				codeGenerator: tt.codeGenerator,
-				cache:         cache,
-				Logger:        zap.NewNop(),
-				Metrics:       mockMetrics,
+				repo:    repo,
+				baseURL: "https://localhost:8080/",
+				cache:   db,
+				Logger:  zap.NewNop(),
+				Metrics: &metrics.NoopMetrics{},
 			}
 
-			resp, err := service.CreateShortURL(context.Background(), tt.request)
-
-			if tt.expectError {
-				require.Error(t, err)
+			if cached {
+				mockClient.ExpectGet("url:abc123").SetVal(string(data))
 			} else {
-				require.NoError(t, err)
+				mockClient.ExpectGet("url:abc123").RedisNil()
+				repo.On("GetByShortCode", mock.Anything, "abc123").Return(quarantined, nil)
 			}
 
-			tt.checkResponse(t, resp, err)
-			repo.AssertExpectations(t)
+			// no IncrementClickCount expectation, a warning isn't a click
+			resp, err := service.GetOriginalURL(context.Background(), &pb.GetURLRequest{ShortCode: "abc123"})
+			require.NoError(t, err)
+			require.True(t, resp.Found)
+			require.True(t, resp.Quarantined)
+			require.Empty(t, resp.OriginalUrl)
 		})
 	}
 }
 
-func TestGetFromCache_CacheHit(t *testing.T) {
-	// create redis mock client and mock controller
-	db, mock := redismock.NewClientMock()
+func TestGetOriginalURL_Interstitial(t *testing.T) {
+	data, err := json.Marshal(&models.URL{ShortCode: "abc123", OriginalURL: "https://google.com/", Interstitial: true})
+	require.NoError(t, err)
+
+	db, mockClient := redismock.NewClientMock()
 	repo := new(MockRepo)
-	mockMetrics := &metrics.NoopMetrics{}
+	repo.On("IncrementClickCount", mock.Anything, "abc123").Return(nil).Maybe()
 	service := &URLService{
 		repo:    repo,
 		cache:   db,
-		baseURL: "https://localhost:8080",
 		Logger:  zap.NewNop(),
-		Metrics: mockMetrics,
+		Metrics: &metrics.NoopMetrics{},
 	}
+	mockClient.ExpectGet("url:abc123").SetVal(string(data))
 
-	urlModel := &models.URL{
-		OriginalURL: "https://google.com",
-		ShortCode:   "abc123",
+	resp, err := service.GetOriginalURL(context.Background(), &pb.GetURLRequest{ShortCode: "abc123"})
+	require.NoError(t, err)
+	require.True(t, resp.Interstitial, "the flag survives the cache")
+	require.Equal(t, "https://google.com/", resp.OriginalUrl)
+	require.Equal(t, int32(302), resp.RedirectType, "entries cached without a redirect type get 302")
+}
+
+func TestGetOriginalURL_WithPath(t *testing.T) {
+	for _, wildcard := range []bool{true, false} {
+		t.Run(fmt.Sprintf("wildcard=%v", wildcard), func(t *testing.T) {
+			db, mockClient := redismock.NewClientMock()
+			repo := new(MockRepo)
+			service := &URLService{
+				repo:    repo,
+				cache:   db,
+				Logger:  zap.NewNop(),
+				Metrics: &metrics.NoopMetrics{},
+			}
+
+			urlModel := &models.URL{
+				ShortCode:        "abc123",
+				OriginalURL:      "https://example.com/docs/",
+				QueryPassthrough: passthrough.QueryPreferRequest,
+				PathPassthrough:  wildcard,
+			}
+			mockClient.ExpectGet("url:abc123").RedisNil()
+			repo.On("GetByShortCode", mock.Anything, "abc123").Return(urlModel, nil)
+			if wildcard {
+				repo.On("IncrementClickCount", mock.Anything, "abc123").Return(nil).Maybe()
+			}
+
+			resp, err := service.GetOriginalURL(context.Background(), &pb.GetURLRequest{ShortCode: "abc123", WithPath: true})
+			require.NoError(t, err)
+			require.Equal(t, wildcard, resp.Found, "paths only match wildcard links")
+			if wildcard {
+				require.True(t, resp.PathPassthrough)
+				require.Equal(t, passthrough.QueryPreferRequest, resp.QueryPassthrough)
+			}
+		})
 	}
+}
 
-	data, err := json.Marshal(urlModel)
+func TestGetOriginalURL_GeoRoutes(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "geoip.csv")
+	require.NoError(t, os.WriteFile(file, []byte("81.2.69.0/24,GB\n2001:db8::/32,DE\n"), 0o644))
+	geoDB, err := geoip.Open(file, zap.NewNop())
 	require.NoError(t, err)
 
-	// mock redis GET command expectation for the key "url:abc123"
-	mock.ExpectGet("url:abc123").SetVal(string(data))
-
-	result, err := service.getFromCache(context.Background(), "abc123")
+	data, err := json.Marshal(&models.URL{
+		ShortCode:   "abc123",
+		OriginalURL: "https://example.com/",
+		GeoRoutes:   models.Routes{"GB": "https://example.co.uk/", "DE": "https://example.de/"},
+	})
 	require.NoError(t, err)
-	require.Equal(t, urlModel.OriginalURL, result.OriginalURL)
 
-	require.NoError(t, mock.ExpectationsWereMet())
+	tests := []struct {
+		clientIP        string
+		expectedURL     string
+		expectedCountry string
+	}{
+		{clientIP: "81.2.69.10", expectedURL: "https://example.co.uk/", expectedCountry: "GB"},
+		{clientIP: "2001:db8::1", expectedURL: "https://example.de/", expectedCountry: "DE"},
+		{clientIP: "198.51.100.1", expectedURL: "https://example.com/"},
+		{clientIP: "", expectedURL: "https://example.com/"},
+	}
 
-}
+	for _, tt := range tests {
+		t.Run(tt.clientIP, func(t *testing.T) {
+			db, mockClient := redismock.NewClientMock()
+			repo := new(MockRepo)
+			repo.On("IncrementClickCount", mock.Anything, "abc123").Return(nil).Maybe()
+			service := &URLService{
+				repo:    repo,
+				cache:   db,
+				Logger:  zap.NewNop(),
+				Metrics: &metrics.NoopMetrics{},
+				GeoIP:   geoDB,
+			}
+			mockClient.ExpectGet("url:abc123").SetVal(string(data))
 
-func TestGetFromCache_CacheMiss(t *testing.T) {
-	db, mock := redismock.NewClientMock()
-	mockMetrics := &metrics.NoopMetrics{}
-	service := &URLService{
-		repo:    new(MockRepo),
-		cache:   db,
-		Logger:  zap.NewNop(),
-		Metrics: mockMetrics,
+			resp, err := service.GetOriginalURL(context.Background(), &pb.GetURLRequest{ShortCode: "abc123", ClientIp: tt.clientIP})
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedURL, resp.OriginalUrl)
+			require.Equal(t, tt.expectedCountry, resp.Country)
+			require.True(t, resp.PerVisitor)
+		})
 	}
+}
 
-	mock.ExpectGet("url:abc123").RedisNil()
+func TestGetOriginalURL_DeviceRoutes(t *testing.T) {
+	const (
+		iPhone  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+		android = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/123.0.6312.99 Mobile Safari/537.36"
+		windows = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/123.0.0.0 Safari/537.36"
+		bot     = "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"
+	)
+
+	file := filepath.Join(t.TempDir(), "geoip.csv")
+	require.NoError(t, os.WriteFile(file, []byte("81.2.69.0/24,GB\n"), 0o644))
+	geoDB, err := geoip.Open(file, zap.NewNop())
+	require.NoError(t, err)
 
-	result, err := service.getFromCache(context.Background(), "abc123")
-	require.Error(t, err)
-	require.Nil(t, result)
+	data, err := json.Marshal(&models.URL{
+		ShortCode:   "abc123",
+		OriginalURL: "https://example.com/",
+		DeviceRoutes: models.Routes{
+			"ios":     "https://apps.apple.com/app/id123",
+			"android": "intent://open/#Intent;scheme=shop;package=com.example.shop;end",
+		},
+		GeoRoutes: models.Routes{"GB": "https://example.co.uk/"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name             string
+		userAgent        string
+		clientIP         string
+		expectedURL      string
+		expectedPlatform string
+	}{
+		{name: "iPhone", userAgent: iPhone, expectedURL: "https://apps.apple.com/app/id123", expectedPlatform: "ios"},
+		{name: "Android", userAgent: android, expectedURL: "intent://open/#Intent;scheme=shop;package=com.example.shop;end", expectedPlatform: "android"},
+		{name: "desktop without a route", userAgent: windows, expectedURL: "https://example.com/", expectedPlatform: "desktop"},
+		{name: "desktop falls back to geo routes", userAgent: windows, clientIP: "81.2.69.10", expectedURL: "https://example.co.uk/", expectedPlatform: "desktop"},
+		{name: "device routes win over geo routes", userAgent: iPhone, clientIP: "81.2.69.10", expectedURL: "https://apps.apple.com/app/id123", expectedPlatform: "ios"},
+		{name: "bots get the default", userAgent: bot, expectedURL: "https://example.com/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mockClient := redismock.NewClientMock()
+			repo := new(MockRepo)
+			repo.On("IncrementClickCount", mock.Anything, "abc123").Return(nil).Maybe()
+			service := &URLService{
+				repo:    repo,
+				cache:   db,
+				Logger:  zap.NewNop(),
+				Metrics: &metrics.NoopMetrics{},
+				GeoIP:   geoDB,
+			}
+			mockClient.ExpectGet("url:abc123").SetVal(string(data))
+
+			resp, err := service.GetOriginalURL(context.Background(), &pb.GetURLRequest{
+				ShortCode: "abc123",
+				ClientIp:  tt.clientIP,
+				UserAgent: tt.userAgent,
+			})
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedURL, resp.OriginalUrl)
+			require.Equal(t, tt.expectedPlatform, resp.Platform)
+			require.True(t, resp.PerVisitor)
+		})
+	}
+}
+
+func TestValidateDeviceRoutes(t *testing.T) {
+	service := &URLService{
+		Logger:     zap.NewNop(),
+		Metrics:    &metrics.NoopMetrics{},
+		URLOptions: urlnorm.DefaultOptions(),
+	}
+
+	tests := []struct {
+		name      string
+		routes    map[string]string
+		expected  models.Routes
+		expectErr codes.Code
+	}{
+		{
+			name:     "web destinations are canonical",
+			routes:   map[string]string{"iOS": "HTTPS://apps.apple.com/app/id123", "desktop": "https://example.com"},
+			expected: models.Routes{"ios": "https://apps.apple.com/app/id123", "desktop": "https://example.com/"},
+		},
+		{
+			name:     "android intent kept as given",
+			routes:   map[string]string{"android": "intent://open/#Intent;package=com.example.shop;S.browser_fallback_url=https%3A%2F%2Fexample.com%2F;end"},
+			expected: models.Routes{"android": "intent://open/#Intent;package=com.example.shop;S.browser_fallback_url=https%3A%2F%2Fexample.com%2F;end"},
+		},
+		{
+			name:      "intent only on android",
+			routes:    map[string]string{"ios": "intent://open/#Intent;package=com.example.shop;end"},
+			expectErr: codes.InvalidArgument,
+		},
+		{
+			name:      "intent without package",
+			routes:    map[string]string{"android": "intent://open/#Intent;scheme=shop;end"},
+			expectErr: codes.InvalidArgument,
+		},
+		{
+			name:      "intent with a bad fallback",
+			routes:    map[string]string{"android": "intent://open/#Intent;package=com.example.shop;S.browser_fallback_url=javascript%3Aalert(1);end"},
+			expectErr: codes.InvalidArgument,
+		},
+		{
+			name:      "unknown platform",
+			routes:    map[string]string{"windows": "https://example.com/"},
+			expectErr: codes.InvalidArgument,
+		},
+		{
+			name:      "custom scheme",
+			routes:    map[string]string{"ios": "shop://item/42"},
+			expectErr: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes, err := service.validateDeviceRoutes(context.Background(), tt.routes)
+			if tt.expectErr != codes.OK {
+				require.Equal(t, tt.expectErr, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, routes)
+		})
+	}
+}
+
+func TestGetOriginalURL_Variants(t *testing.T) {
+	const iPhone = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+
+	data, err := json.Marshal(&models.URL{
+		ShortCode:   "abc123",
+		OriginalURL: "https://example.com/",
+		Variants: models.Variants{
+			{Name: "a", URL: "https://example.com/a", Weight: 1},
+			{Name: "b", URL: "https://example.com/b", Weight: 1},
+		},
+		DeviceRoutes: models.Routes{"ios": "https://apps.apple.com/app/id123"},
+	})
+	require.NoError(t, err)
+
+	getURL := func(t *testing.T, req *pb.GetURLRequest) *pb.GetURLResponse {
+		db, mockClient := redismock.NewClientMock()
+		repo := new(MockRepo)
+		repo.On("IncrementClickCount", mock.Anything, "abc123").Return(nil).Maybe()
+		service := &URLService{
+			repo:    repo,
+			cache:   db,
+			Logger:  zap.NewNop(),
+			Metrics: &metrics.NoopMetrics{},
+		}
+		mockClient.ExpectGet("url:abc123").SetVal(string(data))
+
+		resp, err := service.GetOriginalURL(context.Background(), req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("visitors stick to their variant", func(t *testing.T) {
+		seen := map[string]bool{}
+		for i := 0; i < 50; i++ {
+			req := &pb.GetURLRequest{ShortCode: "abc123", VisitorId: fmt.Sprintf("visitor-%d", i)}
+			first := getURL(t, req)
+			require.True(t, first.PerVisitor)
+			require.Equal(t, "https://example.com/"+first.Variant, first.OriginalUrl)
+			require.Equal(t, first.Variant, getURL(t, req).Variant)
+			seen[first.Variant] = true
+		}
+		require.Equal(t, map[string]bool{"a": true, "b": true}, seen)
+	})
+
+	t.Run("without a visitor ID the address and browser are used", func(t *testing.T) {
+		req := &pb.GetURLRequest{ShortCode: "abc123", ClientIp: "198.51.100.7", UserAgent: "Mozilla/5.0 (X11; Linux x86_64)"}
+		first := getURL(t, req)
+		require.NotEmpty(t, first.Variant)
+		require.Equal(t, first.Variant, getURL(t, req).Variant)
+	})
+
+	t.Run("device routes win over variants", func(t *testing.T) {
+		resp := getURL(t, &pb.GetURLRequest{ShortCode: "abc123", VisitorId: "visitor-1", UserAgent: iPhone})
+		require.Equal(t, "https://apps.apple.com/app/id123", resp.OriginalUrl)
+		require.Empty(t, resp.Variant)
+	})
+}
+
+func TestValidateVariants(t *testing.T) {
+	service := &URLService{
+		Logger:     zap.NewNop(),
+		Metrics:    &metrics.NoopMetrics{},
+		URLOptions: urlnorm.DefaultOptions(),
+	}
+	variant := func(name, url string, weight int32) *pb.Variant {
+		return &pb.Variant{Name: name, Url: url, Weight: weight}
+	}
+
+	tests := []struct {
+		name      string
+		variants  []*pb.Variant
+		expected  models.Variants
+		expectErr codes.Code
+	}{
+		{
+			name:     "destinations are canonical and order is kept",
+			variants: []*pb.Variant{variant("control", "HTTPS://example.com/a", 90), variant("new-page", "https://example.com/b", 10)},
+			expected: models.Variants{{Name: "control", URL: "https://example.com/a", Weight: 90}, {Name: "new-page", URL: "https://example.com/b", Weight: 10}},
+		},
+		{
+			name:      "a single variant",
+			variants:  []*pb.Variant{variant("a", "https://example.com/a", 1)},
+			expectErr: codes.InvalidArgument,
+		},
+		{
+			name:      "duplicate names",
+			variants:  []*pb.Variant{variant("a", "https://example.com/a", 1), variant("a", "https://example.com/b", 1)},
+			expectErr: codes.InvalidArgument,
+		},
+		{
+			name:      "empty name",
+			variants:  []*pb.Variant{variant("", "https://example.com/a", 1), variant("b", "https://example.com/b", 1)},
+			expectErr: codes.InvalidArgument,
+		},
+		{
+			name:      "name with spaces",
+			variants:  []*pb.Variant{variant("page a", "https://example.com/a", 1), variant("b", "https://example.com/b", 1)},
+			expectErr: codes.InvalidArgument,
+		},
+		{
+			name:      "zero weight",
+			variants:  []*pb.Variant{variant("a", "https://example.com/a", 0), variant("b", "https://example.com/b", 1)},
+			expectErr: codes.InvalidArgument,
+		},
+		{
+			name:      "weight too large",
+			variants:  []*pb.Variant{variant("a", "https://example.com/a", 1001), variant("b", "https://example.com/b", 1)},
+			expectErr: codes.InvalidArgument,
+		},
+		{
+			name:      "invalid destination",
+			variants:  []*pb.Variant{variant("a", "https://example.com/a", 1), variant("b", "not-a-url", 1)},
+			expectErr: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variants, err := service.validateVariants(context.Background(), tt.variants)
+			if tt.expectErr != codes.OK {
+				require.Equal(t, tt.expectErr, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, variants)
+		})
+	}
+}
+
+func TestGetOriginalURL_ClickLimited(t *testing.T) {
+	data, err := json.Marshal(&models.URL{
+		ShortCode:   "abc123",
+		OriginalURL: "https://example.com/onboarding",
+		MaxClicks:   ptrInt64(1),
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		claimErr  error
+		checkResp func(t *testing.T, resp *pb.GetURLResponse, err error)
+	}{
+		{
+			name: "click left",
+			checkResp: func(t *testing.T, resp *pb.GetURLResponse, err error) {
+				require.NoError(t, err)
+				require.True(t, resp.Found)
+				require.True(t, resp.ClickLimited)
+				require.Equal(t, "https://example.com/onboarding", resp.OriginalUrl)
+			},
+		},
+		{
+			name:     "used up",
+			claimErr: repository.ErrClicksExhausted,
+			checkResp: func(t *testing.T, resp *pb.GetURLResponse, err error) {
+				require.NoError(t, err)
+				require.False(t, resp.Found)
+				require.True(t, resp.Exhausted)
+				require.Empty(t, resp.OriginalUrl)
+			},
+		},
+		{
+			name:     "database down",
+			claimErr: errors.New("connection refused"),
+			checkResp: func(t *testing.T, resp *pb.GetURLResponse, err error) {
+				require.Nil(t, resp)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mockClient := redismock.NewClientMock()
+			repo := new(MockRepo)
+			repo.On("ClaimClick", mock.Anything, "abc123").Return(tt.claimErr).Once()
+			service := &URLService{
+				repo:    repo,
+				cache:   db,
+				Logger:  zap.NewNop(),
+				Metrics: &metrics.NoopMetrics{},
+			}
+			mockClient.ExpectGet("url:abc123").SetVal(string(data))
+
+			resp, err := service.GetOriginalURL(context.Background(), &pb.GetURLRequest{ShortCode: "abc123"})
+			tt.checkResp(t, resp, err)
+			repo.AssertExpectations(t)
+			// the claim already counted the click
+			repo.AssertNotCalled(t, "IncrementClickCount", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGetOriginalURL_ClickLimitedConcurrent(t *testing.T) {
+	const (
+		workers   = 20
+		maxClicks = 3
+	)
+
+	repo := &memoryRepo{urls: map[string]models.URL{
+		"abc123": {ShortCode: "abc123", OriginalURL: "https://example.com/onboarding", MaxClicks: ptrInt64(maxClicks)},
+	}}
+	// an unreachable cache sends every request to the repository
+	cache := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0", MaxRetries: -1})
+	service := &URLService{
+		repo:    repo,
+		cache:   cache,
+		Logger:  zap.NewNop(),
+		Metrics: &metrics.NoopMetrics{},
+	}
+
+	var redirected, exhausted atomic.Int64
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			resp, err := service.GetOriginalURL(context.Background(), &pb.GetURLRequest{ShortCode: "abc123"})
+			require.NoError(t, err)
+			if resp.Found {
+				redirected.Add(1)
+			}
+			if resp.Exhausted {
+				exhausted.Add(1)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	require.Equal(t, int64(maxClicks), redirected.Load())
+	require.Equal(t, int64(workers-maxClicks), exhausted.Load())
+	require.Equal(t, int64(maxClicks), repo.urls["abc123"].ClickCount)
+}
+
+func TestGetOriginalURL_Schedule(t *testing.T) {
+	now := time.Now()
+	hour := func(n int) *time.Time { return ptrTime(now.Add(time.Duration(n) * time.Hour)) }
+
+	tests := []struct {
+		name              string
+		urlModel          *models.URL
+		expectedURL       string
+		expectedInactive  bool
+		expectedChangesAt *time.Time
+	}{
+		{
+			name:             "before launch",
+			urlModel:         &models.URL{OriginalURL: "https://example.com/product", ActiveFrom: hour(2)},
+			expectedInactive: true,
+		},
+		{
+			name:        "after launch",
+			urlModel:    &models.URL{OriginalURL: "https://example.com/product", ActiveFrom: hour(-2)},
+			expectedURL: "https://example.com/product",
+		},
+		{
+			name: "during the event",
+			urlModel: &models.URL{
+				OriginalURL: "https://example.com/recording",
+				Schedule:    models.Schedule{{Start: hour(-1), End: hour(2), URL: "https://example.com/live"}},
+			},
+			expectedURL:       "https://example.com/live",
+			expectedChangesAt: hour(2),
+		},
+		{
+			name: "after the event",
+			urlModel: &models.URL{
+				OriginalURL: "https://example.com/recording",
+				Schedule:    models.Schedule{{Start: hour(-3), End: hour(-1), URL: "https://example.com/live"}},
+			},
+			expectedURL: "https://example.com/recording",
+		},
+		{
+			name: "before the event",
+			urlModel: &models.URL{
+				OriginalURL: "https://example.com/signup",
+				Schedule:    models.Schedule{{Start: hour(1), End: hour(3), URL: "https://example.com/live"}},
+			},
+			expectedURL:       "https://example.com/signup",
+			expectedChangesAt: hour(1),
+		},
+		{
+			name: "first matching entry wins",
+			urlModel: &models.URL{
+				OriginalURL: "https://example.com/",
+				Schedule: models.Schedule{
+					{Start: hour(-1), End: hour(1), URL: "https://example.com/first"},
+					{Start: hour(-2), URL: "https://example.com/second"},
+				},
+			},
+			expectedURL:       "https://example.com/first",
+			expectedChangesAt: hour(1),
+		},
+		{
+			name: "schedule wins over routes and variants",
+			urlModel: &models.URL{
+				OriginalURL:  "https://example.com/",
+				DeviceRoutes: models.Routes{"desktop": "https://example.com/desktop"},
+				Variants: models.Variants{
+					{Name: "a", URL: "https://example.com/a", Weight: 1},
+					{Name: "b", URL: "https://example.com/b", Weight: 1},
+				},
+				Schedule: models.Schedule{{End: hour(1), URL: "https://example.com/sale"}},
+			},
+			expectedURL:       "https://example.com/sale",
+			expectedChangesAt: hour(1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.urlModel.ShortCode = "abc123"
+			data, err := json.Marshal(tt.urlModel)
+			require.NoError(t, err)
+
+			db, mockClient := redismock.NewClientMock()
+			repo := new(MockRepo)
+			repo.On("IncrementClickCount", mock.Anything, "abc123").Return(nil).Maybe()
+			service := &URLService{
+				repo:    repo,
+				cache:   db,
+				Logger:  zap.NewNop(),
+				Metrics: &metrics.NoopMetrics{},
+			}
+			mockClient.ExpectGet("url:abc123").SetVal(string(data))
+
+			resp, err := service.GetOriginalURL(context.Background(), &pb.GetURLRequest{
+				ShortCode: "abc123",
+				UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64)",
+			})
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedInactive, resp.Inactive)
+			if tt.expectedInactive {
+				require.False(t, resp.Found)
+				require.Empty(t, resp.OriginalUrl)
+				repo.AssertNotCalled(t, "IncrementClickCount", mock.Anything, mock.Anything)
+				return
+			}
+			require.True(t, resp.Found)
+			require.Equal(t, tt.expectedURL, resp.OriginalUrl)
+			if tt.expectedChangesAt == nil {
+				require.Zero(t, resp.ChangesAt)
+			} else {
+				require.Equal(t, tt.expectedChangesAt.Unix(), resp.ChangesAt)
+			}
+		})
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	service := &URLService{
+		Logger:     zap.NewNop(),
+		Metrics:    &metrics.NoopMetrics{},
+		URLOptions: urlnorm.DefaultOptions(),
+	}
+	start := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	end := start.Add(8 * time.Hour)
+
+	tests := []struct {
+		name      string
+		entries   []*pb.ScheduleEntry
+		expected  models.Schedule
+		expectErr codes.Code
+	}{
+		{
+			name: "windows keep their order",
+			entries: []*pb.ScheduleEntry{
+				{Start: start.Unix(), End: end.Unix(), Url: "HTTPS://example.com/live"},
+				{Start: end.Unix(), Url: "https://example.com/recording"},
+			},
+			expected: models.Schedule{
+				{Start: &start, End: &end, URL: "https://example.com/live"},
+				{Start: &end, URL: "https://example.com/recording"},
+			},
+		},
+		{
+			name:     "open start",
+			entries:  []*pb.ScheduleEntry{{End: end.Unix(), Url: "https://example.com/early-bird"}},
+			expected: models.Schedule{{End: &end, URL: "https://example.com/early-bird"}},
+		},
+		{
+			name:      "no window",
+			entries:   []*pb.ScheduleEntry{{Url: "https://example.com/"}},
+			expectErr: codes.InvalidArgument,
+		},
+		{
+			name:      "ends before it starts",
+			entries:   []*pb.ScheduleEntry{{Start: end.Unix(), End: start.Unix(), Url: "https://example.com/"}},
+			expectErr: codes.InvalidArgument,
+		},
+		{
+			name:      "invalid destination",
+			entries:   []*pb.ScheduleEntry{{Start: start.Unix(), Url: "not-a-url"}},
+			expectErr: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := service.validateSchedule(context.Background(), tt.entries)
+			if tt.expectErr != codes.OK {
+				require.Equal(t, tt.expectErr, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, slices.EqualFunc(tt.expected, schedule, models.ScheduleEntry.Equal), "got %+v", schedule)
+		})
+	}
+}
+
+func TestCreateShortURL(t *testing.T) {
+	tests := []struct {
+		name          string
+		generator     CodeGenerator
+		mockSetup     func(m *MockRepo, mockRedis redismock.ClientMock)
+		request       *pb.CreateURLRequest
+		expectError   bool
+		checkResponse func(t *testing.T, resp *pb.CreateURLResponse, err error)
+	}{
+		{
+			name:      "success",
+			generator: &stubGenerator{codes: []string{"abc123"}},
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("Create", mock.Anything, mock.AnythingOfType("*models.URL")).Return(nil)
+				mockRedis.ExpectSet("abc123", mock.Anything, 10*time.Minute).SetVal("OK")
+
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://google.com",
+				UserId:      "user123",
+			},
+			expectError: false,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, resp)
+				require.True(t, resp.Success)
+				require.Equal(t, "abc123", resp.ShortCode)
+				require.Equal(t, "https://localhost:8080/abc123", resp.ShortUrl)
+				require.False(t, resp.Existing)
+			},
+		},
+		{
+			name:      "stores canonical url",
+			generator: &stubGenerator{codes: []string{"abc123"}},
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("Create", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.OriginalURL == "http://example.com/b" && u.URLHash == hashURL("http://example.com/b")
+				})).Return(nil)
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "HTTP://Example.com:80/a/../b?",
+				UserId:      "user123",
+			},
+			expectError: false,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "abc123", resp.ShortCode)
+			},
+		},
+		{
+			name:      "permanent redirect",
+			generator: &stubGenerator{codes: []string{"abc123"}},
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("Create", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.RedirectType == 308
+				})).Return(nil)
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl:  "https://google.com",
+				UserId:       "user123",
+				RedirectType: 308,
+			},
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int32(308), resp.RedirectType)
+			},
+		},
+		{
+			name:      "redirect type defaults to 302",
+			generator: &stubGenerator{codes: []string{"abc123"}},
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("Create", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.RedirectType == 302
+				})).Return(nil)
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://google.com",
+				UserId:      "user123",
+			},
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int32(302), resp.RedirectType)
+			},
+		},
+		{
+			name:      "unsupported redirect type rejected",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {},
+			request: &pb.CreateURLRequest{
+				OriginalUrl:  "https://google.com",
+				UserId:       "user123",
+				RedirectType: 303,
+			},
+			expectError: true,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				require.Contains(t, err.Error(), "redirect_type")
+			},
+		},
+		{
+			name:      "unknown query passthrough rejected",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {},
+			request: &pb.CreateURLRequest{
+				OriginalUrl:      "https://google.com",
+				UserId:           "user123",
+				QueryPassthrough: "merge",
+			},
+			expectError: true,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				require.Contains(t, err.Error(), "query_passthrough")
+			},
+		},
+		{
+			name:      "non-http scheme rejected",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "javascript://example.com/%0Aalert(1)",
+				UserId:      "user123",
+			},
+			expectError: true,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.Nil(t, resp)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				require.Contains(t, err.Error(), "only http and https")
+			},
+		},
+		{
+			name: "dedupe returns existing link",
+			generator: codeGeneratorFunc(func(ctx context.Context) (string, error) {
+				return "", fmt.Errorf("generator should not be called for duplicates")
+			}),
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("GetLiveByURLHash", mock.Anything, "user123", hashURL("https://google.com/")).Return(&models.URL{
+					ShortCode:   "old123",
+					OriginalURL: "https://google.com",
+					ExpiresAt:   ptrTime(time.Unix(1_900_000_000, 0)),
+				}, nil)
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "HTTPS://Google.com:443",
+				UserId:      "user123",
+				ExpiresAt:   1_900_000_000,
+				Dedupe:      true,
+			},
+			expectError: false,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "old123", resp.ShortCode)
+				require.Equal(t, "https://localhost:8080/old123", resp.ShortUrl)
+				require.Equal(t, "https://google.com", resp.OriginalUrl)
+				require.True(t, resp.Existing)
+				require.Equal(t, int64(1_900_000_000), resp.ExpiresAt)
+			},
+		},
+		{
+			name:      "dedupe skips existing link with another expiry",
+			generator: &stubGenerator{codes: []string{"abc123"}},
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("GetLiveByURLHash", mock.Anything, "user123", mock.Anything).Return(&models.URL{
+					ShortCode:   "old123",
+					OriginalURL: "https://google.com/",
+					ExpiresAt:   ptrTime(time.Now().Add(time.Hour)),
+				}, nil)
+				m.On("Create", mock.Anything, mock.AnythingOfType("*models.URL")).Return(nil)
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://google.com",
+				UserId:      "user123",
+				Dedupe:      true,
+			},
+			expectError: false,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "abc123", resp.ShortCode)
+				require.False(t, resp.Existing)
+				require.Zero(t, resp.ExpiresAt)
+			},
+		},
+		{
+			name:      "dedupe skips existing link with another redirect type",
+			generator: &stubGenerator{codes: []string{"abc123"}},
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("GetLiveByURLHash", mock.Anything, "user123", mock.Anything).Return(&models.URL{
+					ShortCode:   "old123",
+					OriginalURL: "https://google.com/",
+				}, nil)
+				m.On("Create", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.RedirectType == http.StatusMovedPermanently
+				})).Return(nil)
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl:  "https://google.com",
+				UserId:       "user123",
+				RedirectType: http.StatusMovedPermanently,
+				Dedupe:       true,
+			},
+			expectError: false,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "abc123", resp.ShortCode)
+				require.False(t, resp.Existing)
+			},
+		},
+		{
+			name:      "dedupe skips existing link without interstitial",
+			generator: &stubGenerator{codes: []string{"abc123"}},
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("GetLiveByURLHash", mock.Anything, "user123", mock.Anything).Return(&models.URL{
+					ShortCode:    "old123",
+					OriginalURL:  "https://google.com/",
+					RedirectType: http.StatusFound,
+				}, nil)
+				m.On("Create", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.Interstitial
+				})).Return(nil)
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl:  "https://google.com",
+				UserId:       "user123",
+				Interstitial: true,
+				Dedupe:       true,
+			},
+			expectError: false,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "abc123", resp.ShortCode)
+				require.False(t, resp.Existing)
+			},
+		},
+		{
+			name:      "dedupe skips existing link without query passthrough",
+			generator: &stubGenerator{codes: []string{"abc123"}},
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("GetLiveByURLHash", mock.Anything, "user123", mock.Anything).Return(&models.URL{
+					ShortCode:   "old123",
+					OriginalURL: "https://google.com/",
+				}, nil)
+				m.On("Create", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.QueryPassthrough == passthrough.QueryPreferRequest
+				})).Return(nil)
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl:      "https://google.com",
+				UserId:           "user123",
+				QueryPassthrough: passthrough.QueryPreferRequest,
+				Dedupe:           true,
+			},
+			expectError: false,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "abc123", resp.ShortCode)
+				require.False(t, resp.Existing)
+			},
+		},
+		{
+			name:      "dedupe skips existing link without path passthrough",
+			generator: &stubGenerator{codes: []string{"abc123"}},
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("GetLiveByURLHash", mock.Anything, "user123", mock.Anything).Return(&models.URL{
+					ShortCode:        "old123",
+					OriginalURL:      "https://google.com/",
+					QueryPassthrough: passthrough.QueryOff,
+				}, nil)
+				m.On("Create", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.PathPassthrough
+				})).Return(nil)
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl:     "https://google.com",
+				UserId:          "user123",
+				PathPassthrough: true,
+				Dedupe:          true,
+			},
+			expectError: false,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "abc123", resp.ShortCode)
+				require.False(t, resp.Existing)
+			},
+		},
+		{
+			name:      "dedupe with ttl creates a fresh link",
+			generator: &stubGenerator{codes: []string{"abc123"}},
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("GetLiveByURLHash", mock.Anything, "user123", mock.Anything).Return(&models.URL{
+					ShortCode:   "old123",
+					OriginalURL: "https://google.com/",
+				}, nil)
+				m.On("Create", mock.Anything, mock.AnythingOfType("*models.URL")).Return(nil)
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://google.com",
+				UserId:      "user123",
+				TtlSeconds:  3600,
+				Dedupe:      true,
+			},
+			expectError: false,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "abc123", resp.ShortCode)
+				require.False(t, resp.Existing)
+				require.NotZero(t, resp.ExpiresAt)
+			},
+		},
+		{
+			name:      "dedupe without existing link creates one",
+			generator: &stubGenerator{codes: []string{"abc123"}},
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("GetLiveByURLHash", mock.Anything, "user123", mock.Anything).Return(nil, repository.ErrURLNotFound)
+				m.On("Create", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.URLHash == hashURL("https://google.com/")
+				})).Return(nil)
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://google.com",
+				UserId:      "user123",
+				Dedupe:      true,
+			},
+			expectError: false,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "abc123", resp.ShortCode)
+				require.Equal(t, "https://google.com/", resp.OriginalUrl)
+				require.False(t, resp.Existing)
+			},
+		},
+		{
+			name: "dedupe lookup failure",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("GetLiveByURLHash", mock.Anything, "user123", mock.Anything).Return(nil, fmt.Errorf("connection reset"))
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://google.com",
+				UserId:      "user123",
+				Dedupe:      true,
+			},
+			expectError: true,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.Nil(t, resp)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "dedupe ignored for custom alias",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("Create", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.ShortCode == "spring-sale"
+				})).Return(nil)
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://google.com",
+				UserId:      "user123",
+				CustomAlias: "spring-sale",
+				Dedupe:      true,
+			},
+			expectError: false,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "spring-sale", resp.ShortCode)
+				require.False(t, resp.Existing)
+			},
+		},
+		{
+			name:      "invalid url",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {},
+			generator: &stubGenerator{codes: []string{"abc123"}},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://",
+				UserId:      "user123",
+			},
+			expectError: true,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.Error(t, err)
+				require.Nil(t, resp)
+				require.Contains(t, err.Error(), "invalid URL:")
+			},
+		},
+		{
+			name: "failed code generation",
+			generator: codeGeneratorFunc(func(ctx context.Context) (string, error) {
+				return "", fmt.Errorf("sequence unavailable")
+			}),
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://google.com",
+				UserId:      "user123",
+			},
+			expectError: true,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.Error(t, err)
+				require.Nil(t, resp)
+				require.Contains(t, err.Error(), "failed to generate short code: sequence unavailable")
+			},
+		},
+		{
+			name:      "failed save to db",
+			generator: &stubGenerator{codes: []string{"abc123"}},
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("Create", mock.Anything, mock.AnythingOfType("*models.URL")).Return(fmt.Errorf("random failure"))
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://google.com",
+				UserId:      "user123",
+			},
+			expectError: true,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.Error(t, err)
+				require.Nil(t, resp)
+				require.Contains(t, err.Error(), "failed to create URL:")
+			},
+		},
+		{
+			name:      "generated code collision is retried",
+			generator: &stubGenerator{codes: []string{"abc123", "def456"}},
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("Create", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.ShortCode == "abc123"
+				})).Return(repository.ErrShortCodeExists).Once()
+				m.On("Create", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.ShortCode == "def456"
+				})).Return(nil).Once()
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://google.com",
+				UserId:      "user123",
+			},
+			expectError: false,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "def456", resp.ShortCode)
+			},
+		},
+		{
+			name:      "reserved generated code is skipped",
+			generator: &stubGenerator{codes: []string{"Create", "abc123"}},
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("Create", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.ShortCode == "abc123"
+				})).Return(nil).Once()
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://google.com",
+				UserId:      "user123",
+			},
+			expectError: false,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "abc123", resp.ShortCode)
+			},
+		},
+		{
+			name: "collision attempts exhausted",
+			generator: codeGeneratorFunc(func(ctx context.Context) (string, error) {
+				return "abc123", nil
+			}),
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("Create", mock.Anything, mock.AnythingOfType("*models.URL")).Return(repository.ErrShortCodeExists).Times(maxCreateAttempts)
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://google.com",
+				UserId:      "user123",
+			},
+			expectError: true,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.Nil(t, resp)
+				require.Equal(t, codes.Internal, status.Code(err))
+				require.Contains(t, err.Error(), "failed to generate a unique short code after 10 attempts")
+			},
+		},
+		{
+			name: "custom alias",
+			generator: codeGeneratorFunc(func(ctx context.Context) (string, error) {
+				return "", fmt.Errorf("generator should not be called for aliases")
+			}),
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("Create", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.ShortCode == "spring-sale"
+				})).Return(nil)
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://google.com",
+				UserId:      "user123",
+				CustomAlias: "spring-sale",
+			},
+			expectError: false,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "spring-sale", resp.ShortCode)
+				require.Equal(t, "https://localhost:8080/spring-sale", resp.ShortUrl)
+			},
+		},
+		{
+			name: "custom alias already taken",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("Create", mock.Anything, mock.AnythingOfType("*models.URL")).Return(repository.ErrShortCodeExists)
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://google.com",
+				UserId:      "user123",
+				CustomAlias: "spring-sale",
+			},
+			expectError: true,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.Nil(t, resp)
+				require.Equal(t, codes.AlreadyExists, status.Code(err))
+			},
+		},
+		{
+			name:      "ttl seconds",
+			generator: &stubGenerator{codes: []string{"abc123"}},
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("Create", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.ExpiresAt != nil && time.Until(*u.ExpiresAt) > 59*time.Minute
+				})).Return(nil)
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://google.com",
+				UserId:      "user123",
+				TtlSeconds:  3600,
+			},
+			expectError: false,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.NoError(t, err)
+				require.InDelta(t, time.Now().Add(time.Hour).Unix(), resp.ExpiresAt, 5)
+			},
+		},
+		{
+			name:      "absolute expiry",
+			generator: &stubGenerator{codes: []string{"abc123"}},
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("Create", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.ExpiresAt != nil
+				})).Return(nil)
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://google.com",
+				UserId:      "user123",
+				ExpiresAt:   time.Now().Add(24 * time.Hour).Unix(),
+			},
+			expectError: false,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.NoError(t, err)
+				require.NotZero(t, resp.ExpiresAt)
+			},
+		},
+		{
+			name:      "expiry in the past",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://google.com",
+				UserId:      "user123",
+				ExpiresAt:   time.Now().Add(-time.Hour).Unix(),
+			},
+			expectError: true,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.Nil(t, resp)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				require.Contains(t, err.Error(), "expires_at must be in the future")
+			},
+		},
+		{
+			name:      "expiry and ttl both set",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://google.com",
+				UserId:      "user123",
+				ExpiresAt:   time.Now().Add(time.Hour).Unix(),
+				TtlSeconds:  60,
+			},
+			expectError: true,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.Nil(t, resp)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				require.Contains(t, err.Error(), "mutually exclusive")
+			},
+		},
+		{
+			name:      "custom alias invalid",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://google.com",
+				UserId:      "user123",
+				CustomAlias: "healthz",
+			},
+			expectError: true,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.Nil(t, resp)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				require.Contains(t, err.Error(), "reserved")
+			},
+		},
+		{
+			name:      "geo routes stored canonical",
+			generator: &stubGenerator{codes: []string{"abc123"}},
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("Create", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return maps.Equal(u.GeoRoutes, models.Routes{"DE": "https://example.de/", "FR": "https://example.fr/"})
+				})).Return(nil)
+			},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://example.com",
+				UserId:      "user123",
+				GeoRoutes:   map[string]string{"de": "HTTPS://Example.de", "FR": "https://example.fr/"},
+			},
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.Equal(t, "https://example.de/", resp.GeoRoutes["DE"])
+			},
+		},
+		{
+			name:      "geo route with invalid country rejected",
+			generator: &stubGenerator{codes: []string{"abc123"}},
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://example.com",
+				UserId:      "user123",
+				GeoRoutes:   map[string]string{"GER": "https://example.de/"},
+			},
+			expectError: true,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name:      "geo route with invalid destination rejected",
+			generator: &stubGenerator{codes: []string{"abc123"}},
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {},
+			request: &pb.CreateURLRequest{
+				OriginalUrl: "https://example.com",
+				UserId:      "user123",
+				GeoRoutes:   map[string]string{"DE": "javascript:alert(1)"},
+			},
+			expectError: true,
+			checkResponse: func(t *testing.T, resp *pb.CreateURLResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRepo)
+
+			cache, mockRedis := redismock.NewClientMock()
+			tt.mockSetup(repo, mockRedis)
+			mockMetrics := &metrics.NoopMetrics{}
+			service := &URLService{
+				repo:      repo,
+				baseURL:   "https://localhost:8080/",
+				generator: tt.generator,
+				cache:     cache,
+				Logger:    zap.NewNop(),
+				Metrics:   mockMetrics,
+			}
+
+			resp, err := service.CreateShortURL(context.Background(), tt.request)
+
+			if tt.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			tt.checkResponse(t, resp, err)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+// memoryRepo enforces short_code uniqueness the way the urls table does
+type memoryRepo struct {
+	repository.URLRepository
+	mu   sync.Mutex
+	urls map[string]models.URL
+}
+
+func (r *memoryRepo) Create(ctx context.Context, url *models.URL) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.urls[url.ShortCode]; ok {
+		return repository.ErrShortCodeExists
+	}
+	r.urls[url.ShortCode] = *url
+	return nil
+}
+
+func (r *memoryRepo) GetByShortCode(ctx context.Context, shortCode string) (*models.URL, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	url, ok := r.urls[shortCode]
+	if !ok {
+		return nil, repository.ErrURLNotFound
+	}
+	return &url, nil
+}
+
+// ClaimClick checks and counts under one lock, as the conditional UPDATE does
+func (r *memoryRepo) ClaimClick(ctx context.Context, shortCode string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	url := r.urls[shortCode]
+	if url.MaxClicks != nil && url.ClickCount >= *url.MaxClicks {
+		return repository.ErrClicksExhausted
+	}
+	url.ClickCount++
+	r.urls[shortCode] = url
+	return nil
+}
+
+// racingGenerator hands the same code to the first contested callers, holding
+// them until all have it so their inserts race, then falls back to random codes
+type racingGenerator struct {
+	calls     atomic.Int64
+	contested int64
+	ready     sync.WaitGroup
+	fallback  CodeGenerator
+}
+
+func newRacingGenerator(contested int) *racingGenerator {
+	g := &racingGenerator{contested: int64(contested), fallback: NewRandomCodeGenerator(charset, 8)}
+	g.ready.Add(contested)
+	return g
+}
+
+func (g *racingGenerator) Generate(ctx context.Context) (string, error) {
+	if g.calls.Add(1) <= g.contested {
+		g.ready.Done()
+		g.ready.Wait()
+		return "race01", nil
+	}
+	return g.fallback.Generate(ctx)
+}
+
+type collisionMetrics struct {
+	metrics.NoopMetrics
+	collisions atomic.Int64
+}
+
+func (m *collisionMetrics) IncShortCodeCollision(service, reason string) {
+	m.collisions.Add(1)
+}
+
+func TestCreateShortURL_ConcurrentCollisions(t *testing.T) {
+	const workers = 20
+
+	repo := &memoryRepo{urls: make(map[string]models.URL)}
+	recorder := &collisionMetrics{}
+	cache, _ := redismock.NewClientMock()
+	service := &URLService{
+		repo:      repo,
+		baseURL:   "https://localhost:8080/",
+		generator: newRacingGenerator(workers),
+		cache:     cache,
+		Logger:    zap.NewNop(),
+		Metrics:   recorder,
+	}
+
+	shortCodes := make([]string, workers)
+	errs := make([]error, workers)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			resp, err := service.CreateShortURL(context.Background(), &pb.CreateURLRequest{
+				OriginalUrl: "https://google.com",
+				UserId:      "user123",
+			})
+			errs[i] = err
+			if resp != nil {
+				shortCodes[i] = resp.ShortCode
+			}
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	// every request succeeds, exactly one wins the contested code and the rest retried
+	seen := make(map[string]bool)
+	for i := 0; i < workers; i++ {
+		require.NoError(t, errs[i])
+		require.False(t, seen[shortCodes[i]], "short code %q handed out twice", shortCodes[i])
+		seen[shortCodes[i]] = true
+	}
+	require.True(t, seen["race01"])
+	require.Len(t, repo.urls, workers)
+	require.Equal(t, int64(workers-1), recorder.collisions.Load())
+}
+
+// rejectionMetrics records destination policy rejections
+type rejectionMetrics struct {
+	metrics.NoopMetrics
+	reasons []string
+}
+
+func (m *rejectionMetrics) IncDestinationRejected(service, reason string) {
+	m.reasons = append(m.reasons, reason)
+}
+
+func TestDestinationPolicy(t *testing.T) {
+	policy, err := urlpolicy.New(urlpolicy.Config{SelfHosts: []string{"sho.rt"}}, zap.NewNop())
+	require.NoError(t, err)
+	policy.SetRules(urlpolicy.Rules{Block: []string{"bit.ly"}})
+
+	tests := []struct {
+		name   string
+		url    string
+		reason string
+	}{
+		{name: "blocked domain", url: "https://BIT.ly/abc", reason: "BLOCKED_DOMAIN"},
+		{name: "ip literal", url: "http://10.0.0.5:8080/admin", reason: "IP_LITERAL"},
+		{name: "self reference", url: "https://sho.rt/abc123", reason: "SELF_REFERENCE"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockRepo)
+			recorder := &rejectionMetrics{}
+			service := &URLService{
+				repo:    mockRepo,
+				baseURL: "https://sho.rt/",
+				Logger:  zap.NewNop(),
+				Metrics: recorder,
+				Policy:  policy,
+			}
+
+			_, err := service.CreateShortURL(context.Background(), &pb.CreateURLRequest{OriginalUrl: tc.url, UserId: "user123"})
+			st := status.Convert(err)
+			require.Equal(t, codes.PermissionDenied, st.Code())
+			require.Len(t, st.Details(), 1)
+			info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+			require.True(t, ok)
+			require.Equal(t, tc.reason, info.Reason)
+			require.Equal(t, []string{strings.ToLower(tc.reason)}, recorder.reasons)
+
+			// updates go through the same policy
+			mockRepo.On("GetStats", mock.Anything, "abc123").Return(&models.URL{ShortCode: "abc123", UserID: "user123", OriginalURL: "https://google.com/"}, nil)
+			_, err = service.UpdateShortURL(context.Background(), &pb.UpdateURLRequest{ShortCode: "abc123", UserId: "user123", OriginalUrl: tc.url})
+			require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// failingResolver answers every lookup with an error, like a host that doesn't exist
+type failingResolver struct{}
+
+func (failingResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	return nil, errors.New("no such host")
+}
+
+func TestDestinationPolicy_Unresolved(t *testing.T) {
+	policy, err := urlpolicy.New(urlpolicy.Config{Resolver: failingResolver{}}, zap.NewNop())
+	require.NoError(t, err)
+
+	mockRepo := new(MockRepo)
+	recorder := &rejectionMetrics{}
+	service := &URLService{
+		repo:    mockRepo,
+		baseURL: "https://sho.rt/",
+		Logger:  zap.NewNop(),
+		Metrics: recorder,
+		Policy:  policy,
+	}
+
+	_, err = service.CreateShortURL(context.Background(), &pb.CreateURLRequest{OriginalUrl: "https://not-registered.example/", UserId: "user123"})
+	st := status.Convert(err)
+	require.Equal(t, codes.InvalidArgument, st.Code())
+	require.Equal(t, "destination host does not resolve", st.Message())
+	require.Equal(t, []string{"unresolved"}, recorder.reasons)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateShortURL_ThreatList(t *testing.T) {
+	threats := threatlist.NewList()
+	threats.Replace([][]byte{threatlist.HashPrefix("evil.example/", 4)})
+
+	mockRepo := new(MockRepo)
+	recorder := &rejectionMetrics{}
+	service := &URLService{
+		repo:    mockRepo,
+		baseURL: "https://localhost:8080/",
+		Logger:  zap.NewNop(),
+		Metrics: recorder,
+		Threats: threats,
+	}
+
+	_, err := service.CreateShortURL(context.Background(), &pb.CreateURLRequest{OriginalUrl: "https://www.Evil.example/login", UserId: "user123"})
+	st := status.Convert(err)
+	require.Equal(t, codes.PermissionDenied, st.Code())
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	require.Equal(t, "THREAT_MATCH", info.Reason)
+	require.Equal(t, "evil.example/", info.Metadata["expression"])
+	require.Equal(t, []string{"threat_match"}, recorder.reasons)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestUpdateShortURL(t *testing.T) {
+	owned := func() *models.URL {
+		return &models.URL{
+			ID:          1,
+			UserID:      "user123",
+			ShortCode:   "abc123",
+			OriginalURL: "https://google.com",
+		}
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func(m *MockRepo, mockRedis redismock.ClientMock)
+		request       *pb.UpdateURLRequest
+		checkResponse func(t *testing.T, resp *pb.UpdateURLResponse, err error)
+	}{
+		{
+			name: "update destination invalidates cache",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("GetStats", mock.Anything, "abc123").Return(owned(), nil)
+				m.On("Update", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.OriginalURL == "https://example.com/" && u.URLHash == hashURL("https://example.com/")
+				})).Return(nil)
+				mockRedis.ExpectDel("url:abc123").SetVal(1)
+			},
+			request: &pb.UpdateURLRequest{
+				ShortCode:   "abc123",
+				UserId:      "user123",
+				OriginalUrl: "https://Example.com",
+			},
+			checkResponse: func(t *testing.T, resp *pb.UpdateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "https://example.com/", resp.Url.OriginalUrl)
+				require.Equal(t, "https://localhost:8080/abc123", resp.Url.ShortUrl)
+			},
+		},
+		{
+			name: "turn on interstitial",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("GetStats", mock.Anything, "abc123").Return(owned(), nil)
+				m.On("Update", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.Interstitial && u.OriginalURL == "https://google.com"
+				})).Return(nil)
+				mockRedis.ExpectDel("url:abc123").SetVal(1)
+			},
+			request: &pb.UpdateURLRequest{
+				ShortCode:    "abc123",
+				UserId:       "user123",
+				Interstitial: proto.Bool(true),
+			},
+			checkResponse: func(t *testing.T, resp *pb.UpdateURLResponse, err error) {
+				require.NoError(t, err)
+				require.True(t, resp.Url.Interstitial)
+			},
+		},
+		{
+			name: "new destination releases a quarantined link",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				url := owned()
+				url.QuarantinedAt = ptrTime(time.Now().Add(-time.Hour))
+				m.On("GetStats", mock.Anything, "abc123").Return(url, nil)
+				m.On("Update", mock.Anything, mock.Anything).Return(nil)
+				m.On("SetQuarantined", mock.Anything, []int64{1}, false).Return(nil)
+				mockRedis.ExpectDel("url:abc123").SetVal(1)
+			},
+			request: &pb.UpdateURLRequest{
+				ShortCode:   "abc123",
+				UserId:      "user123",
+				OriginalUrl: "https://example.com",
+			},
+			checkResponse: func(t *testing.T, resp *pb.UpdateURLResponse, err error) {
+				require.NoError(t, err)
+				require.False(t, resp.Url.Quarantined)
+			},
+		},
+		{
+			name: "other changes keep a link quarantined",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				url := owned()
+				url.QuarantinedAt = ptrTime(time.Now().Add(-time.Hour))
+				m.On("GetStats", mock.Anything, "abc123").Return(url, nil)
+				m.On("Update", mock.Anything, mock.Anything).Return(nil)
+				mockRedis.ExpectDel("url:abc123").SetVal(1)
+			},
+			request: &pb.UpdateURLRequest{
+				ShortCode:    "abc123",
+				UserId:       "user123",
+				Interstitial: proto.Bool(true),
+			},
+			checkResponse: func(t *testing.T, resp *pb.UpdateURLResponse, err error) {
+				require.NoError(t, err)
+				require.True(t, resp.Url.Quarantined)
+			},
+		},
+		{
+			name: "change redirect type",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("GetStats", mock.Anything, "abc123").Return(owned(), nil)
+				m.On("Update", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.RedirectType == 301
+				})).Return(nil)
+				mockRedis.ExpectDel("url:abc123").SetVal(1)
+			},
+			request: &pb.UpdateURLRequest{
+				ShortCode:    "abc123",
+				UserId:       "user123",
+				RedirectType: 301,
+			},
+			checkResponse: func(t *testing.T, resp *pb.UpdateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int32(301), resp.Url.RedirectType)
+			},
+		},
+		{
+			name: "clear expiry",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				url := owned()
+				url.ExpiresAt = ptrTime(time.Now().Add(time.Hour))
+				m.On("GetStats", mock.Anything, "abc123").Return(url, nil)
+				m.On("Update", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.ExpiresAt == nil
+				})).Return(nil)
+				mockRedis.ExpectDel("url:abc123").SetVal(1)
+			},
+			request: &pb.UpdateURLRequest{
+				ShortCode:   "abc123",
+				UserId:      "user123",
+				ClearExpiry: true,
+			},
+			checkResponse: func(t *testing.T, resp *pb.UpdateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Zero(t, resp.Url.ExpiresAt)
+			},
+		},
+		{
+			name: "replace variants",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				url := owned()
+				url.Variants = models.Variants{{Name: "a", URL: "https://example.com/a", Weight: 1}, {Name: "b", URL: "https://example.com/b", Weight: 1}}
+				m.On("GetStats", mock.Anything, "abc123").Return(url, nil)
+				m.On("Update", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return len(u.Variants) == 2 && u.Variants[0].Weight == 9 && u.Variants[1].URL == "https://example.com/c"
+				})).Return(nil)
+				mockRedis.ExpectDel("url:abc123").SetVal(1)
+			},
+			request: &pb.UpdateURLRequest{
+				ShortCode: "abc123",
+				UserId:    "user123",
+				Variants: []*pb.Variant{
+					{Name: "a", Url: "https://example.com/a", Weight: 9},
+					{Name: "c", Url: "https://example.com/c", Weight: 1},
+				},
+			},
+			checkResponse: func(t *testing.T, resp *pb.UpdateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, resp.Url.Variants, 2)
+				require.Equal(t, "c", resp.Url.Variants[1].Name)
+			},
+		},
+		{
+			name: "launch now",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				url := owned()
+				url.ActiveFrom = ptrTime(time.Now().Add(time.Hour))
+				m.On("GetStats", mock.Anything, "abc123").Return(url, nil)
+				m.On("Update", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.ActiveFrom == nil
+				})).Return(nil)
+				mockRedis.ExpectDel("url:abc123").SetVal(1)
+			},
+			request: &pb.UpdateURLRequest{
+				ShortCode:       "abc123",
+				UserId:          "user123",
+				ClearActiveFrom: true,
+			},
+			checkResponse: func(t *testing.T, resp *pb.UpdateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Zero(t, resp.Url.ActiveFrom)
+			},
+		},
+		{
+			name: "active_from after expiry",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				url := owned()
+				url.ExpiresAt = ptrTime(time.Now().Add(time.Hour))
+				m.On("GetStats", mock.Anything, "abc123").Return(url, nil)
+			},
+			request: &pb.UpdateURLRequest{
+				ShortCode:  "abc123",
+				UserId:     "user123",
+				ActiveFrom: time.Now().Add(2 * time.Hour).Unix(),
+			},
+			checkResponse: func(t *testing.T, resp *pb.UpdateURLResponse, err error) {
+				require.Nil(t, resp)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "replace schedule",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("GetStats", mock.Anything, "abc123").Return(owned(), nil)
+				m.On("Update", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return len(u.Schedule) == 1 && u.Schedule[0].URL == "https://example.com/live"
+				})).Return(nil)
+				mockRedis.ExpectDel("url:abc123").SetVal(1)
+			},
+			request: &pb.UpdateURLRequest{
+				ShortCode: "abc123",
+				UserId:    "user123",
+				Schedule:  []*pb.ScheduleEntry{{End: time.Now().Add(time.Hour).Unix(), Url: "https://example.com/live"}},
+			},
+			checkResponse: func(t *testing.T, resp *pb.UpdateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, resp.Url.Schedule, 1)
+			},
+		},
+		{
+			name: "raise click limit",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				url := owned()
+				url.MaxClicks = ptrInt64(1)
+				url.ClickCount = 1
+				m.On("GetStats", mock.Anything, "abc123").Return(url, nil)
+				m.On("Update", mock.Anything, mock.MatchedBy(func(u *models.URL) bool {
+					return u.MaxClicks != nil && *u.MaxClicks == 2
+				})).Return(nil)
+				mockRedis.ExpectDel("url:abc123").SetVal(1)
+			},
+			request: &pb.UpdateURLRequest{
+				ShortCode: "abc123",
+				UserId:    "user123",
+				MaxClicks: 2,
+			},
+			checkResponse: func(t *testing.T, resp *pb.UpdateURLResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(2), resp.Url.MaxClicks)
+			},
+		},
+		{
+			name: "negative click limit",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("GetStats", mock.Anything, "abc123").Return(owned(), nil)
+			},
+			request: &pb.UpdateURLRequest{
+				ShortCode: "abc123",
+				UserId:    "user123",
+				MaxClicks: -1,
+			},
+			checkResponse: func(t *testing.T, resp *pb.UpdateURLResponse, err error) {
+				require.Nil(t, resp)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "clear variants with new variants",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("GetStats", mock.Anything, "abc123").Return(owned(), nil)
+			},
+			request: &pb.UpdateURLRequest{
+				ShortCode:     "abc123",
+				UserId:        "user123",
+				ClearVariants: true,
+				Variants: []*pb.Variant{
+					{Name: "a", Url: "https://example.com/a", Weight: 1},
+					{Name: "b", Url: "https://example.com/b", Weight: 1},
+				},
+			},
+			checkResponse: func(t *testing.T, resp *pb.UpdateURLResponse, err error) {
+				require.Nil(t, resp)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "other owner",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("GetStats", mock.Anything, "abc123").Return(owned(), nil)
+			},
+			request: &pb.UpdateURLRequest{
+				ShortCode:   "abc123",
+				UserId:      "intruder",
+				OriginalUrl: "https://example.com",
+			},
+			checkResponse: func(t *testing.T, resp *pb.UpdateURLResponse, err error) {
+				require.Nil(t, resp)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "not found",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("GetStats", mock.Anything, "abc123").Return(nil, repository.ErrURLNotFound)
+			},
+			request: &pb.UpdateURLRequest{
+				ShortCode:   "abc123",
+				UserId:      "user123",
+				OriginalUrl: "https://example.com",
+			},
+			checkResponse: func(t *testing.T, resp *pb.UpdateURLResponse, err error) {
+				require.Nil(t, resp)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "invalid destination",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {
+				m.On("GetStats", mock.Anything, "abc123").Return(owned(), nil)
+			},
+			request: &pb.UpdateURLRequest{
+				ShortCode:   "abc123",
+				UserId:      "user123",
+				OriginalUrl: "not-a-url",
+			},
+			checkResponse: func(t *testing.T, resp *pb.UpdateURLResponse, err error) {
+				require.Nil(t, resp)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name:      "missing user",
+			mockSetup: func(m *MockRepo, mockRedis redismock.ClientMock) {},
+			request: &pb.UpdateURLRequest{
+				ShortCode: "abc123",
+			},
+			checkResponse: func(t *testing.T, resp *pb.UpdateURLResponse, err error) {
+				require.Nil(t, resp)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRepo)
+			cache, mockRedis := redismock.NewClientMock()
+			tt.mockSetup(repo, mockRedis)
+			service := &URLService{
+				repo:    repo,
+				baseURL: "https://localhost:8080/",
+				cache:   cache,
+				Logger:  zap.NewNop(),
+				Metrics: &metrics.NoopMetrics{},
+			}
+
+			resp, err := service.UpdateShortURL(context.Background(), tt.request)
+
+			tt.checkResponse(t, resp, err)
+			repo.AssertExpectations(t)
+			require.NoError(t, mockRedis.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteShortURL(t *testing.T) {
+	repo := new(MockRepo)
+	cache, mockRedis := redismock.NewClientMock()
+	service := &URLService{
+		repo:    repo,
+		cache:   cache,
+		Logger:  zap.NewNop(),
+		Metrics: &metrics.NoopMetrics{},
+	}
+
+	repo.On("GetStats", mock.Anything, "abc123").Return(&models.URL{UserID: "user123", ShortCode: "abc123"}, nil)
+	repo.On("Delete", mock.Anything, "abc123").Return(nil)
+	mockRedis.ExpectDel("url:abc123").SetVal(1)
+
+	resp, err := service.DeleteShortURL(context.Background(), &pb.DeleteURLRequest{ShortCode: "abc123", UserId: "user123"})
+	require.NoError(t, err)
+	require.True(t, resp.Success)
+	repo.AssertExpectations(t)
+	require.NoError(t, mockRedis.ExpectationsWereMet())
+
+	// another user's link is left alone
+	resp, err = service.DeleteShortURL(context.Background(), &pb.DeleteURLRequest{ShortCode: "abc123", UserId: "intruder"})
+	require.Nil(t, resp)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	repo.AssertNumberOfCalls(t, "Delete", 1)
+}
+
+func TestGetURLDetails(t *testing.T) {
+	repo := new(MockRepo)
+	service := &URLService{
+		repo:    repo,
+		baseURL: "https://localhost:8080/",
+		Logger:  zap.NewNop(),
+		Metrics: &metrics.NoopMetrics{},
+	}
+
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.On("GetStats", mock.Anything, "abc123").Return(&models.URL{
+		ID:          7,
+		UserID:      "user123",
+		ShortCode:   "abc123",
+		OriginalURL: "https://google.com",
+		CreatedAt:   created,
+		UpdatedAt:   created,
+		ClickCount:  42,
+	}, nil)
+
+	resp, err := service.GetURLDetails(context.Background(), &pb.GetURLDetailsRequest{ShortCode: "abc123", UserId: "user123"})
+	require.NoError(t, err)
+	require.Equal(t, int64(7), resp.Url.Id)
+	require.Equal(t, int64(42), resp.Url.ClickCount)
+	require.Equal(t, created.Unix(), resp.Url.CreatedAt)
+	require.Zero(t, resp.Url.ExpiresAt)
+}
+
+func TestGetURLPreview(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	expired := created.Add(time.Hour)
+
+	repo := new(MockRepo)
+	repo.On("GetStats", mock.Anything, "abc123").Return(&models.URL{
+		UserID:       "user123",
+		ShortCode:    "abc123",
+		OriginalURL:  "https://google.com/",
+		CreatedAt:    created,
+		ClickCount:   42,
+		ExpiresAt:    &expired,
+		Interstitial: true,
+	}, nil)
+	repo.On("GetStats", mock.Anything, "evil01").Return(&models.URL{
+		ShortCode:     "evil01",
+		OriginalURL:   "https://evil.example/login",
+		CreatedAt:     created,
+		QuarantinedAt: ptrTime(created),
+	}, nil)
+	repo.On("GetStats", mock.Anything, "launch").Return(&models.URL{
+		ShortCode:   "launch",
+		OriginalURL: "https://example.com/new-product",
+		CreatedAt:   created,
+		ActiveFrom:  ptrTime(time.Now().Add(24 * time.Hour)),
+	}, nil)
+	repo.On("GetStats", mock.Anything, "event").Return(&models.URL{
+		ShortCode:   "event",
+		OriginalURL: "https://example.com/recording",
+		CreatedAt:   created,
+		Schedule:    models.Schedule{{End: ptrTime(time.Now().Add(time.Hour)), URL: "https://example.com/live"}},
+	}, nil)
+	repo.On("GetStats", mock.Anything, "once").Return(&models.URL{
+		ShortCode:   "once",
+		OriginalURL: "https://example.com/onboarding?token=secret",
+		CreatedAt:   created,
+		ClickCount:  1,
+		MaxClicks:   ptrInt64(1),
+	}, nil)
+	repo.On("GetStats", mock.Anything, "nope").Return(nil, repository.ErrURLNotFound)
+
+	service := &URLService{
+		repo:    repo,
+		Logger:  zap.NewNop(),
+		Metrics: &metrics.NoopMetrics{},
+	}
+
+	// anyone can preview, no user id needed
+	resp, err := service.GetURLPreview(context.Background(), &pb.GetURLPreviewRequest{ShortCode: "abc123"})
+	require.NoError(t, err)
+	require.Equal(t, "https://google.com/", resp.OriginalUrl)
+	require.Equal(t, int64(42), resp.ClickCount)
+	require.Equal(t, created.Unix(), resp.CreatedAt)
+	require.Equal(t, expired.Unix(), resp.ExpiresAt)
+	require.True(t, resp.Expired)
+	require.True(t, resp.Interstitial)
+
+	resp, err = service.GetURLPreview(context.Background(), &pb.GetURLPreviewRequest{ShortCode: "evil01"})
+	require.NoError(t, err)
+	require.True(t, resp.Quarantined)
+	require.Empty(t, resp.OriginalUrl)
+
+	// a launch link's destination stays secret until launch
+	resp, err = service.GetURLPreview(context.Background(), &pb.GetURLPreviewRequest{ShortCode: "launch"})
+	require.NoError(t, err)
+	require.True(t, resp.Inactive)
+	require.NotZero(t, resp.ActiveFrom)
+	require.Empty(t, resp.OriginalUrl)
+
+	// a preview mustn't stand in for a click
+	resp, err = service.GetURLPreview(context.Background(), &pb.GetURLPreviewRequest{ShortCode: "once"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), resp.MaxClicks)
+	require.True(t, resp.Exhausted)
+	require.Empty(t, resp.OriginalUrl)
+
+	resp, err = service.GetURLPreview(context.Background(), &pb.GetURLPreviewRequest{ShortCode: "event"})
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/live", resp.OriginalUrl)
+
+	_, err = service.GetURLPreview(context.Background(), &pb.GetURLPreviewRequest{ShortCode: "nope"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = service.GetURLPreview(context.Background(), &pb.GetURLPreviewRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// previews aren't clicks
+	repo.AssertNotCalled(t, "IncrementClickCount", mock.Anything, mock.Anything)
+}
+
+func TestListURLs(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       *pb.ListURLsRequest
+		expectLimit   int
+		expectCode    codes.Code
+		expectedCount int
+	}{
+		{name: "default limit", request: &pb.ListURLsRequest{UserId: "user123"}, expectLimit: defaultListLimit, expectedCount: 2},
+		{name: "limit capped", request: &pb.ListURLsRequest{UserId: "user123", Limit: 1000}, expectLimit: maxListLimit, expectedCount: 2},
+		{name: "missing user", request: &pb.ListURLsRequest{}, expectCode: codes.InvalidArgument},
+		{name: "negative offset", request: &pb.ListURLsRequest{UserId: "user123", Offset: -1}, expectCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRepo)
+			service := &URLService{
+				repo:    repo,
+				baseURL: "https://localhost:8080/",
+				Logger:  zap.NewNop(),
+				Metrics: &metrics.NoopMetrics{},
+			}
+			if tt.expectLimit != 0 {
+				repo.On("ListURLsByUser", mock.Anything, "user123", tt.expectLimit, 0).Return([]*models.URL{
+					{ShortCode: "abc123", UserID: "user123"},
+					{ShortCode: "def456", UserID: "user123"},
+				}, nil)
+			}
+
+			resp, err := service.ListURLs(context.Background(), tt.request)
+			if tt.expectCode != codes.OK {
+				require.Equal(t, tt.expectCode, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, resp.Urls, tt.expectedCount)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetFromCache_CacheHit(t *testing.T) {
+	// create redis mock client and mock controller
+	db, mock := redismock.NewClientMock()
+	repo := new(MockRepo)
+	mockMetrics := &metrics.NoopMetrics{}
+	service := &URLService{
+		repo:    repo,
+		cache:   db,
+		baseURL: "https://localhost:8080",
+		Logger:  zap.NewNop(),
+		Metrics: mockMetrics,
+	}
+
+	urlModel := &models.URL{
+		OriginalURL: "https://google.com",
+		ShortCode:   "abc123",
+	}
+
+	data, err := json.Marshal(urlModel)
+	require.NoError(t, err)
+
+	// mock redis GET command expectation for the key "url:abc123"
+	mock.ExpectGet("url:abc123").SetVal(string(data))
+
+	result, err := service.getFromCache(context.Background(), "abc123")
+	require.NoError(t, err)
+	require.Equal(t, urlModel.OriginalURL, result.OriginalURL)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+
+}
+
+func TestGetFromCache_CacheMiss(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	mockMetrics := &metrics.NoopMetrics{}
+	service := &URLService{
+		repo:    new(MockRepo),
+		cache:   db,
+		Logger:  zap.NewNop(),
+		Metrics: mockMetrics,
+	}
+
+	mock.ExpectGet("url:abc123").RedisNil()
+
+	result, err := service.getFromCache(context.Background(), "abc123")
+	require.Error(t, err)
+	require.Nil(t, result)
 
 	require.NoError(t, mock.ExpectationsWereMet())
 }
@@ -613,6 +2693,127 @@ func TestSetCacheFromModel(t *testing.T) {
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestSetCacheFromModel_ClampsTTLToExpiry(t *testing.T) {
+	db, mockRedis := redismock.NewClientMock()
+	service := &URLService{
+		repo:    new(MockRepo),
+		cache:   db,
+		Logger:  zap.NewNop(),
+		Metrics: &metrics.NoopMetrics{},
+	}
+
+	urlModel := &models.URL{
+		OriginalURL: "https://google.com",
+		ShortCode:   "abc123",
+		ExpiresAt:   ptrTime(time.Now().Add(30 * time.Second)),
+	}
+
+	data, err := json.Marshal(urlModel)
+	require.NoError(t, err)
+
+	mockRedis.CustomMatch(func(expected, actual []interface{}) error {
+		// actual is [set key value px <ms>]
+		ttl, ok := actual[4].(int64)
+		if !ok || ttl <= 0 || ttl > (30*time.Second).Milliseconds() {
+			return fmt.Errorf("unexpected cache ttl %v", actual[4])
+		}
+		return nil
+	}).ExpectSet("url:abc123", data, 30*time.Second).SetVal("OK")
+
+	err = service.setCacheFromModel(context.Background(), "abc123", urlModel)
+	require.NoError(t, err)
+	require.NoError(t, mockRedis.ExpectationsWereMet())
+}
+
+func TestSetCacheFromModel_ClampsTTLToSchedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		urlModel *models.URL
+	}{
+		{
+			name: "before launch",
+			urlModel: &models.URL{
+				OriginalURL: "https://google.com",
+				ShortCode:   "abc123",
+				ActiveFrom:  ptrTime(time.Now().Add(30 * time.Second)),
+			},
+		},
+		{
+			name: "window ending",
+			urlModel: &models.URL{
+				OriginalURL: "https://google.com",
+				ShortCode:   "abc123",
+				Schedule: models.Schedule{
+					{Start: ptrTime(time.Now().Add(-time.Hour)), End: ptrTime(time.Now().Add(30 * time.Second)), URL: "https://example.com/live"},
+				},
+			},
+		},
+		{
+			name: "window starting",
+			urlModel: &models.URL{
+				OriginalURL: "https://google.com",
+				ShortCode:   "abc123",
+				Schedule: models.Schedule{
+					{Start: ptrTime(time.Now().Add(time.Hour)), URL: "https://example.com/later"},
+					{Start: ptrTime(time.Now().Add(30 * time.Second)), URL: "https://example.com/soon"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mockRedis := redismock.NewClientMock()
+			service := &URLService{
+				repo:    new(MockRepo),
+				cache:   db,
+				Logger:  zap.NewNop(),
+				Metrics: &metrics.NoopMetrics{},
+			}
+
+			data, err := json.Marshal(tt.urlModel)
+			require.NoError(t, err)
+
+			mockRedis.CustomMatch(func(expected, actual []interface{}) error {
+				// actual is [set key value px <ms>]
+				ttl, ok := actual[4].(int64)
+				if !ok || ttl <= 0 || ttl > (30*time.Second).Milliseconds() {
+					return fmt.Errorf("unexpected cache ttl %v", actual[4])
+				}
+				return nil
+			}).ExpectSet("url:abc123", data, 30*time.Second).SetVal("OK")
+
+			require.NoError(t, service.setCacheFromModel(context.Background(), "abc123", tt.urlModel))
+			require.NoError(t, mockRedis.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSetCacheFromModel_SkipsExpired(t *testing.T) {
+	db, mockRedis := redismock.NewClientMock()
+	service := &URLService{
+		repo:    new(MockRepo),
+		cache:   db,
+		Logger:  zap.NewNop(),
+		Metrics: &metrics.NoopMetrics{},
+	}
+
+	urlModel := &models.URL{
+		OriginalURL: "https://google.com",
+		ShortCode:   "abc123",
+		ExpiresAt:   ptrTime(time.Now().Add(-time.Second)),
+	}
+
+	// no SET is expected for an already expired link
+	err := service.setCacheFromModel(context.Background(), "abc123", urlModel)
+	require.NoError(t, err)
+	require.NoError(t, mockRedis.ExpectationsWereMet())
+}
+
 func ptrTime(t time.Time) *time.Time {
 	return &t
 }
+
+func ptrInt64(n int64) *int64 {
+	return &n
+}
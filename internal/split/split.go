@@ -0,0 +1,34 @@
+// Package split assigns visitors to the weighted variants of an A/B test.
+// Assignment is a hash of the visitor, so the same visitor keeps getting the
+// same variant without anything being stored.
+package split
+
+import "hash/fnv"
+
+// Pick returns the index of the variant visitor is assigned, each variant
+// getting weights[i] out of the sum of weights. salt separates tests, so a
+// visitor's variant in one doesn't decide the others. Weights must be
+// positive, Pick returns -1 when there are none.
+func Pick(weights []int, salt, visitor string) int {
+	total := 0
+	for _, weight := range weights {
+		total += weight
+	}
+	if total <= 0 {
+		return -1
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(salt))
+	h.Write([]byte{0})
+	h.Write([]byte(visitor))
+	bucket := int(h.Sum64() % uint64(total))
+
+	for i, weight := range weights {
+		if bucket < weight {
+			return i
+		}
+		bucket -= weight
+	}
+	return len(weights) - 1
+}
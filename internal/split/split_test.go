@@ -0,0 +1,65 @@
+package split
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPickSticky(t *testing.T) {
+	weights := []int{50, 50}
+	for i := 0; i < 100; i++ {
+		visitor := fmt.Sprintf("visitor-%d", i)
+		require.Equal(t, Pick(weights, "abc123", visitor), Pick(weights, "abc123", visitor))
+	}
+}
+
+func TestPickWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights []int
+	}{
+		{name: "even", weights: []int{1, 1}},
+		{name: "uneven", weights: []int{90, 10}},
+		{name: "three way", weights: []int{1, 2, 7}},
+	}
+
+	const visitors = 20000
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total := 0
+			for _, weight := range tt.weights {
+				total += weight
+			}
+
+			counts := make([]int, len(tt.weights))
+			for i := 0; i < visitors; i++ {
+				counts[Pick(tt.weights, "abc123", fmt.Sprintf("visitor-%d", i))]++
+			}
+			for i, weight := range tt.weights {
+				share := float64(counts[i]) / visitors
+				require.InDelta(t, float64(weight)/float64(total), share, 0.02, "variant %d", i)
+			}
+		})
+	}
+}
+
+func TestPickSalt(t *testing.T) {
+	// a visitor in variant 0 of one test isn't always in variant 0 of another
+	weights := []int{1, 1}
+	differ := 0
+	for i := 0; i < 100; i++ {
+		visitor := fmt.Sprintf("visitor-%d", i)
+		if Pick(weights, "abc123", visitor) != Pick(weights, "xyz789", visitor) {
+			differ++
+		}
+	}
+	require.Greater(t, differ, 20)
+}
+
+func TestPickEdgeCases(t *testing.T) {
+	require.Equal(t, -1, Pick(nil, "abc123", "visitor"))
+	require.Equal(t, 0, Pick([]int{5}, "abc123", "visitor"))
+	require.Equal(t, 0, Pick([]int{5}, "abc123", ""))
+}
@@ -0,0 +1,204 @@
+// Package threatlist matches URLs against a local list of SHA-256 hash
+// prefixes of unsafe URL expressions, in the style of the Safe Browsing
+// Update API but without looking full hashes up from a live service: any
+// prefix hit counts as a match.
+package threatlist
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// prefix length bounds in bytes, Safe Browsing lists use 4 byte prefixes up to full hashes
+const (
+	MinPrefixLength = 4
+	MaxPrefixLength = sha256.Size
+)
+
+// host and path combinations checked per URL, as in Safe Browsing
+const (
+	maxHostSuffixes = 4
+	maxPathPrefixes = 4
+)
+
+// List is a set of hash prefixes that is safe for concurrent use
+type List struct {
+	mu sync.RWMutex
+	// byLength groups prefixes by length so a hash is checked once per length
+	byLength map[int]map[string]struct{}
+	size     int
+}
+
+func NewList() *List {
+	return &List{byLength: make(map[int]map[string]struct{})}
+}
+
+// Replace swaps the contents of the list for prefixes
+func (l *List) Replace(prefixes [][]byte) {
+	byLength := make(map[int]map[string]struct{})
+	size := 0
+	for _, prefix := range prefixes {
+		set, ok := byLength[len(prefix)]
+		if !ok {
+			set = make(map[string]struct{})
+			byLength[len(prefix)] = set
+		}
+		if _, dup := set[string(prefix)]; !dup {
+			set[string(prefix)] = struct{}{}
+			size++
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.byLength, l.size = byLength, size
+}
+
+// Len returns the number of prefixes in the list
+func (l *List) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.size
+}
+
+// Match reports whether any expression of rawURL hashes to a listed prefix
+// and returns that expression. rawURL should already be canonical (see
+// urlnorm.Normalize).
+func (l *List) Match(rawURL string) (string, bool) {
+	expressions, err := Expressions(rawURL)
+	if err != nil {
+		return "", false
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.size == 0 {
+		return "", false
+	}
+
+	for _, expression := range expressions {
+		hash := sha256.Sum256([]byte(expression))
+		for length, set := range l.byLength {
+			if _, ok := set[string(hash[:length])]; ok {
+				return expression, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Expressions returns the host suffix and path prefix combinations of rawURL
+// that are hashed and checked: the exact host plus up to four suffixes of its
+// last five labels, each with the exact path and query, the exact path and up
+// to four leading directories. http://a.b.c/1/2.html?p=1 gives
+// a.b.c/1/2.html?p=1, a.b.c/1/2.html, a.b.c/, a.b.c/1/ and the same for b.c.
+func Expressions(rawURL string) ([]string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return nil, fmt.Errorf("invalid URL: missing host")
+	}
+
+	paths := pathPrefixes(u.EscapedPath(), u.RawQuery)
+	var expressions []string
+	for _, suffix := range hostSuffixes(host) {
+		for _, path := range paths {
+			expressions = append(expressions, suffix+path)
+		}
+	}
+	return expressions, nil
+}
+
+func hostSuffixes(host string) []string {
+	hosts := []string{host}
+	if _, err := netip.ParseAddr(host); err == nil {
+		return hosts
+	}
+
+	// suffixes start from the last five labels and never shrink to the bare TLD
+	labels := strings.Split(host, ".")
+	for i := max(1, len(labels)-maxHostSuffixes-1); i < len(labels)-1; i++ {
+		hosts = append(hosts, strings.Join(labels[i:], "."))
+	}
+	return hosts
+}
+
+func pathPrefixes(path, query string) []string {
+	if path == "" {
+		path = "/"
+	}
+
+	var paths []string
+	seen := make(map[string]bool)
+	add := func(p string) {
+		if !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+
+	if query != "" {
+		add(path + "?" + query)
+	}
+	add(path)
+
+	// the root and the directories leading to the last segment
+	prefix := "/"
+	add(prefix)
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 0; i < len(segments)-1 && i < maxPathPrefixes-1; i++ {
+		prefix += segments[i] + "/"
+		add(prefix)
+	}
+	return paths
+}
+
+// HashPrefix returns the first length bytes of the SHA-256 of expression
+func HashPrefix(expression string, length int) []byte {
+	hash := sha256.Sum256([]byte(expression))
+	return hash[:length]
+}
+
+// ValidatePrefix checks that prefix has a usable length
+func ValidatePrefix(prefix []byte) error {
+	if len(prefix) < MinPrefixLength || len(prefix) > MaxPrefixLength {
+		return fmt.Errorf("hash prefixes must be %d-%d bytes, got %d", MinPrefixLength, MaxPrefixLength, len(prefix))
+	}
+	return nil
+}
+
+// ParsePrefixes reads hex-encoded hash prefixes, one per line. Blank lines and
+// lines starting with # are skipped.
+func ParsePrefixes(r io.Reader) ([][]byte, error) {
+	var prefixes [][]byte
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		prefix, err := hex.DecodeString(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid hex: %w", line, err)
+		}
+		if err := ValidatePrefix(prefix); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading hash prefixes: %w", err)
+	}
+	return prefixes, nil
+}
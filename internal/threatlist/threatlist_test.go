@@ -0,0 +1,106 @@
+package threatlist
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpressions(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected []string
+	}{
+		{
+			name: "safe browsing example",
+			url:  "http://a.b.c/1/2.html?param=1",
+			expected: []string{
+				"a.b.c/1/2.html?param=1", "a.b.c/1/2.html", "a.b.c/", "a.b.c/1/",
+				"b.c/1/2.html?param=1", "b.c/1/2.html", "b.c/", "b.c/1/",
+			},
+		},
+		{
+			name: "long host and path",
+			url:  "http://a.b.c.d.e.f.g/1.html",
+			expected: []string{
+				"a.b.c.d.e.f.g/1.html", "a.b.c.d.e.f.g/",
+				"c.d.e.f.g/1.html", "c.d.e.f.g/",
+				"d.e.f.g/1.html", "d.e.f.g/",
+				"e.f.g/1.html", "e.f.g/",
+				"f.g/1.html", "f.g/",
+			},
+		},
+		{
+			name:     "ip address",
+			url:      "http://1.2.3.4/1/",
+			expected: []string{"1.2.3.4/1/", "1.2.3.4/"},
+		},
+		{
+			name: "deep path stops at four prefixes",
+			url:  "https://example.com/1/2/3/4/5.html",
+			expected: []string{
+				"example.com/1/2/3/4/5.html", "example.com/", "example.com/1/", "example.com/1/2/", "example.com/1/2/3/",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			expressions, err := Expressions(tc.url)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, expressions)
+		})
+	}
+}
+
+func TestListMatch(t *testing.T) {
+	list := NewList()
+	_, ok := list.Match("https://evil.example/login")
+	require.False(t, ok)
+
+	list.Replace([][]byte{
+		HashPrefix("evil.example/", 4),
+		HashPrefix("phish.example.org/secure/", 32),
+	})
+	require.Equal(t, 2, list.Len())
+
+	tests := []struct {
+		url        string
+		expression string
+	}{
+		{url: "https://evil.example/login?next=/", expression: "evil.example/"},
+		{url: "https://www.evil.example/", expression: "evil.example/"},
+		{url: "http://phish.example.org/secure/bank.html", expression: "phish.example.org/secure/"},
+		{url: "http://phish.example.org/other/bank.html"},
+		{url: "https://example.com/"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.url, func(t *testing.T) {
+			expression, ok := list.Match(tc.url)
+			require.Equal(t, tc.expression != "", ok)
+			require.Equal(t, tc.expression, expression)
+		})
+	}
+
+	list.Replace(nil)
+	_, ok = list.Match("https://evil.example/")
+	require.False(t, ok)
+}
+
+func TestParsePrefixes(t *testing.T) {
+	full := HashPrefix("evil.example/", 32)
+	input := "# unsafe hosts\n\n" + hex.EncodeToString(full) + "\n  0A0B0C0D  \n"
+
+	prefixes, err := ParsePrefixes(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{full, {0x0a, 0x0b, 0x0c, 0x0d}}, prefixes)
+
+	_, err = ParsePrefixes(strings.NewReader("0a0b0c0d\nzz\n"))
+	require.ErrorContains(t, err, "line 2")
+
+	_, err = ParsePrefixes(strings.NewReader("0a0b0c\n"))
+	require.ErrorContains(t, err, "4-32 bytes")
+}
@@ -0,0 +1,233 @@
+// Package urlnorm canonicalizes destination URLs so that different spellings
+// of the same address are stored, compared and deduplicated as one.
+package urlnorm
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+var (
+	ErrInvalidURL        = errors.New("invalid URL")
+	ErrUnsupportedScheme = errors.New("only http and https URLs are supported")
+	ErrInvalidHost       = errors.New("invalid host")
+)
+
+// Options controls the optional, potentially meaning-changing steps
+type Options struct {
+	// StripFragment drops "#..." parts. Servers never see them, but pages use
+	// them to pick a section, so they're kept by default.
+	StripFragment bool
+	// SortQuery orders query parameters by name, keeping the order of repeats
+	SortQuery bool
+	// CleanQuery drops empty parameters such as "a=" and stray "&"s
+	CleanQuery bool
+}
+
+func DefaultOptions() Options {
+	return Options{}
+}
+
+// hostProfile maps hosts for lookup like browsers do, but still accepts the
+// underscores and "--" labels that show up in real hostnames
+var hostProfile = idna.New(
+	idna.MapForLookup(),
+	idna.BidiRule(),
+	idna.CheckHyphens(false),
+	idna.StrictDomainName(false),
+)
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Normalize returns the canonical form of an http(s) URL: scheme and host
+// lowercased, unicode hosts IDNA-encoded, default ports removed, percent
+// escapes normalized, dot segments resolved and an empty path turned into
+// "/", plus whatever opts enables. Any other scheme is rejected.
+func Normalize(rawURL string, opts Options) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if _, ok := defaultPorts[u.Scheme]; !ok {
+		return "", ErrUnsupportedScheme
+	}
+	if u.Opaque != "" {
+		return "", fmt.Errorf("%w: %s URLs need a host", ErrInvalidURL, u.Scheme)
+	}
+
+	if err := normalizeHost(u); err != nil {
+		return "", err
+	}
+
+	escapedPath := removeDotSegments(normalizeEscapes(u.EscapedPath()))
+	if escapedPath == "" {
+		escapedPath = "/"
+	}
+	u.Path, err = url.PathUnescape(escapedPath)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	u.RawPath = escapedPath
+
+	u.RawQuery = normalizeQuery(normalizeEscapes(u.RawQuery), opts)
+	u.ForceQuery = false
+
+	if opts.StripFragment {
+		u.Fragment, u.RawFragment = "", ""
+	}
+
+	return u.String(), nil
+}
+
+func normalizeHost(u *url.URL) error {
+	host := strings.TrimSuffix(u.Hostname(), ".")
+	port := u.Port()
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrInvalidHost)
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if addr.Zone() != "" {
+			return fmt.Errorf("%w: zoned addresses aren't reachable", ErrInvalidHost)
+		}
+		host = addr.String()
+	} else {
+		ascii, err := hostProfile.ToASCII(host)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidHost, err)
+		}
+		host = ascii
+	}
+
+	if port == defaultPorts[u.Scheme] {
+		port = ""
+	}
+
+	switch {
+	case port != "":
+		u.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		u.Host = "[" + host + "]"
+	default:
+		u.Host = host
+	}
+	return nil
+}
+
+// normalizeQuery applies the optional query steps without re-encoding
+// parameters, which would change how some servers read them
+func normalizeQuery(rawQuery string, opts Options) string {
+	if rawQuery == "" || (!opts.CleanQuery && !opts.SortQuery) {
+		return rawQuery
+	}
+
+	params := strings.Split(rawQuery, "&")
+	if opts.CleanQuery {
+		kept := params[:0]
+		for _, param := range params {
+			if param == "" || param == "=" || strings.HasPrefix(param, "=") || strings.HasSuffix(param, "=") {
+				continue
+			}
+			kept = append(kept, param)
+		}
+		params = kept
+	}
+	if opts.SortQuery {
+		sort.SliceStable(params, func(i, j int) bool {
+			return paramName(params[i]) < paramName(params[j])
+		})
+	}
+	return strings.Join(params, "&")
+}
+
+func paramName(param string) string {
+	name, _, _ := strings.Cut(param, "=")
+	return name
+}
+
+// removeDotSegments resolves "." and ".." in an absolute path (RFC 3986 5.2.4)
+func removeDotSegments(path string) string {
+	if path == "" {
+		return path
+	}
+
+	segments := strings.Split(path, "/")
+	out := make([]string, 0, len(segments))
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		switch segment {
+		case ".":
+		case "..":
+			// out[0] is the empty segment before the leading "/"
+			if len(out) > 1 {
+				out = out[:len(out)-1]
+			}
+		default:
+			out = append(out, segment)
+			continue
+		}
+		// a trailing dot segment still names a directory
+		if last {
+			out = append(out, "")
+		}
+	}
+	return strings.Join(out, "/")
+}
+
+// normalizeEscapes uppercases percent escapes and decodes the ones that stand
+// for unreserved characters, which are equivalent either way (RFC 3986 6.2.2)
+func normalizeEscapes(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			c := unhex(s[i+1])<<4 | unhex(s[i+2])
+			if isUnreserved(c) {
+				b.WriteByte(c)
+			} else {
+				b.WriteByte('%')
+				b.WriteString(strings.ToUpper(s[i+1 : i+3]))
+			}
+			i += 2
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
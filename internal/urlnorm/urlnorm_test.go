@@ -0,0 +1,88 @@
+package urlnorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		opts     Options
+		expected string
+	}{
+		{name: "already canonical", url: "https://example.com/a?b=1#c", expected: "https://example.com/a?b=1#c"},
+		{name: "case", url: "HTTP://Example.COM/Path", expected: "http://example.com/Path"},
+		{name: "default http port", url: "http://example.com:80/a", expected: "http://example.com/a"},
+		{name: "default https port", url: "https://example.com:443/a", expected: "https://example.com/a"},
+		{name: "other port kept", url: "http://example.com:443/a", expected: "http://example.com:443/a"},
+		{name: "empty path", url: "https://example.com", expected: "https://example.com/"},
+		{name: "empty query", url: "https://example.com/b?", expected: "https://example.com/b"},
+		{name: "dot segments", url: "HTTP://Example.com:80/a/../b?", expected: "http://example.com/b"},
+		{name: "trailing dot segment", url: "https://example.com/a/b/..", expected: "https://example.com/a/"},
+		{name: "dot segments above root", url: "https://example.com/../../a/./b/", expected: "https://example.com/a/b/"},
+		{name: "unreserved escapes decoded", url: "https://example.com/%7Euser/%61", expected: "https://example.com/~user/a"},
+		{name: "reserved escapes uppercased", url: "https://example.com/a%2fb?q=%3d", expected: "https://example.com/a%2Fb?q=%3D"},
+		{name: "trailing dot in host", url: "https://example.com./a", expected: "https://example.com/a"},
+		{name: "unicode host", url: "https://Bücher.example/", expected: "https://xn--bcher-kva.example/"},
+		{name: "hyphenated labels", url: "https://r3---sn-abc.googlevideo.com/", expected: "https://r3---sn-abc.googlevideo.com/"},
+		{name: "ipv4", url: "http://192.168.0.1:8080", expected: "http://192.168.0.1:8080/"},
+		{name: "ipv6", url: "http://[2001:DB8::0001]:80/", expected: "http://[2001:db8::1]/"},
+		{name: "ipv6 with port", url: "http://[2001:db8::1]:8080/", expected: "http://[2001:db8::1]:8080/"},
+		{name: "fragment kept", url: "https://example.com/docs#install", expected: "https://example.com/docs#install"},
+		{name: "fragment stripped", url: "https://example.com/docs#install", opts: Options{StripFragment: true}, expected: "https://example.com/docs"},
+		{name: "query untouched by default", url: "https://example.com/?b=2&a=1&&c=", expected: "https://example.com/?b=2&a=1&&c="},
+		{name: "query sorted", url: "https://example.com/?b=2&a=1&b=1", opts: Options{SortQuery: true}, expected: "https://example.com/?a=1&b=2&b=1"},
+		{name: "query cleaned", url: "https://example.com/?a=1&&b=&=x&flag&", opts: Options{CleanQuery: true}, expected: "https://example.com/?a=1&flag"},
+		{name: "query cleaned to nothing", url: "https://example.com/?a=&&", opts: Options{CleanQuery: true}, expected: "https://example.com/"},
+		{name: "surrounding space", url: "  https://example.com/a  ", expected: "https://example.com/a"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			normalized, err := Normalize(tc.url, tc.opts)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, normalized)
+
+			// normalizing is idempotent
+			again, err := Normalize(normalized, tc.opts)
+			require.NoError(t, err)
+			require.Equal(t, normalized, again)
+		})
+	}
+}
+
+func TestNormalize_Equivalent(t *testing.T) {
+	a, err := Normalize("HTTP://Example.com:80/a/../b?", DefaultOptions())
+	require.NoError(t, err)
+	b, err := Normalize("http://example.com/b", DefaultOptions())
+	require.NoError(t, err)
+	require.Equal(t, a, b)
+}
+
+func TestNormalize_Rejects(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		err  error
+	}{
+		{name: "javascript", url: "javascript:alert(1)", err: ErrUnsupportedScheme},
+		{name: "javascript with slashes", url: "JavaScript://example.com/%0Aalert(1)", err: ErrUnsupportedScheme},
+		{name: "data", url: "data:text/html;base64,PHNjcmlwdD4=", err: ErrUnsupportedScheme},
+		{name: "ftp", url: "ftp://example.com/file", err: ErrUnsupportedScheme},
+		{name: "no scheme", url: "example.com/a", err: ErrUnsupportedScheme},
+		{name: "opaque", url: "http:example.com", err: ErrInvalidURL},
+		{name: "missing host", url: "https:///path", err: ErrInvalidHost},
+		{name: "zoned address", url: "http://[fe80::1%25en0]/", err: ErrInvalidHost},
+		{name: "bad escape", url: "https://example.com/%zz", err: ErrInvalidURL},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Normalize(tc.url, DefaultOptions())
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
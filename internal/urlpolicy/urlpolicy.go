@@ -0,0 +1,289 @@
+// Package urlpolicy decides which destinations links may point at: it rejects
+// IP literals, hosts resolving to private networks, links back to the
+// shortener itself and domains excluded by a hot-reloaded rules file.
+package urlpolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// Reason says which rule rejected a destination, values are used as metric labels
+type Reason string
+
+const (
+	ReasonBlockedDomain    Reason = "blocked_domain"
+	ReasonDomainNotAllowed Reason = "domain_not_allowed"
+	ReasonIPLiteral        Reason = "ip_literal"
+	ReasonPrivateAddress   Reason = "private_address"
+	ReasonSelfReference    Reason = "self_reference"
+)
+
+// ErrUnresolvedHost is returned by Check when the destination's host doesn't
+// resolve, unless Config.AllowUnresolved is set
+var ErrUnresolvedHost = errors.New("destination host does not resolve")
+
+// Violation is returned by Check when a destination breaks the policy
+type Violation struct {
+	Reason Reason
+	Host   string
+}
+
+func (v *Violation) Error() string {
+	switch v.Reason {
+	case ReasonBlockedDomain:
+		return fmt.Sprintf("destination %s is blocked", v.Host)
+	case ReasonDomainNotAllowed:
+		return fmt.Sprintf("destination %s is not on the allowlist", v.Host)
+	case ReasonIPLiteral:
+		return fmt.Sprintf("destination %s is an IP address, use a hostname", v.Host)
+	case ReasonPrivateAddress:
+		return fmt.Sprintf("destination %s resolves to a private network", v.Host)
+	case ReasonSelfReference:
+		return fmt.Sprintf("destination %s is this shortener, links can't point at other short links", v.Host)
+	default:
+		return fmt.Sprintf("destination %s is not allowed", v.Host)
+	}
+}
+
+// Resolver looks up the addresses of a host, *net.Resolver satisfies it
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// Rules is the format of the rules file. Entries match the domain and all of
+// its subdomains. When Allow is non-empty only matching destinations are
+// accepted, and Block always wins over Allow.
+type Rules struct {
+	Block []string `yaml:"block"`
+	Allow []string `yaml:"allow"`
+}
+
+type Config struct {
+	// SelfHosts are the hosts short links are served from
+	SelfHosts []string
+	// RulesFile is an optional YAML file of Rules, reloaded by Watch
+	RulesFile string
+	// Resolver is used to catch hostnames pointing at private networks,
+	// nil skips the lookup
+	Resolver Resolver
+	// ResolveTimeout bounds each lookup
+	ResolveTimeout time.Duration
+	// AllowUnresolved lets hosts through when the lookup fails or times out
+	AllowUnresolved bool
+}
+
+// sharedAddressSpace is carrier-grade NAT space (RFC 6598), which
+// netip.Addr.IsPrivate doesn't cover
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+type Policy struct {
+	selfHosts       map[string]bool
+	rulesFile       string
+	resolver        Resolver
+	resolveTimeout  time.Duration
+	allowUnresolved bool
+	logger          *zap.Logger
+
+	mu      sync.RWMutex
+	block   domainSet
+	allow   domainSet
+	modTime time.Time
+}
+
+// New builds a policy and loads cfg.RulesFile if one is set
+func New(cfg Config, logger *zap.Logger) (*Policy, error) {
+	p := &Policy{
+		selfHosts:       make(map[string]bool, len(cfg.SelfHosts)),
+		rulesFile:       cfg.RulesFile,
+		resolver:        cfg.Resolver,
+		resolveTimeout:  cfg.ResolveTimeout,
+		allowUnresolved: cfg.AllowUnresolved,
+		logger:          logger,
+	}
+	for _, host := range cfg.SelfHosts {
+		if host = normalizeDomain(host); host != "" {
+			p.selfHosts[host] = true
+		}
+	}
+	if p.resolveTimeout <= 0 {
+		p.resolveTimeout = 2 * time.Second
+	}
+
+	if p.rulesFile != "" {
+		if _, err := p.Reload(); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// SetRules replaces the block and allow lists
+func (p *Policy) SetRules(rules Rules) {
+	block, allow := newDomainSet(rules.Block), newDomainSet(rules.Allow)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.block, p.allow = block, allow
+}
+
+// Reload reads the rules file again if it changed since the last load and
+// reports whether it did. On error the current rules are kept.
+func (p *Policy) Reload() (bool, error) {
+	info, err := os.Stat(p.rulesFile)
+	if err != nil {
+		return false, fmt.Errorf("error reading destination rules: %w", err)
+	}
+
+	p.mu.RLock()
+	unchanged := info.ModTime().Equal(p.modTime)
+	p.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(p.rulesFile)
+	if err != nil {
+		return false, fmt.Errorf("error reading destination rules: %w", err)
+	}
+	var rules Rules
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return false, fmt.Errorf("error parsing destination rules %s: %w", p.rulesFile, err)
+	}
+
+	p.SetRules(rules)
+	p.mu.Lock()
+	p.modTime = info.ModTime()
+	p.mu.Unlock()
+
+	p.logger.Info("Loaded destination rules", zap.String("file", p.rulesFile), zap.Int("blocked", len(rules.Block)), zap.Int("allowed", len(rules.Allow)))
+	return true, nil
+}
+
+// Watch reloads the rules file every interval until ctx is cancelled
+func (p *Policy) Watch(ctx context.Context, interval time.Duration) {
+	if p.rulesFile == "" {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := p.Reload(); err != nil {
+			p.logger.Warn("Failed to reload destination rules, keeping the current ones", zap.Error(err))
+		}
+	}
+}
+
+// Check returns a *Violation if the policy rejects rawURL, which should
+// already be canonical (see urlnorm.Normalize)
+func (p *Policy) Check(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	host := normalizeDomain(u.Hostname())
+
+	if p.selfHosts[host] {
+		return &Violation{Reason: ReasonSelfReference, Host: host}
+	}
+	if _, err := netip.ParseAddr(host); err == nil {
+		return &Violation{Reason: ReasonIPLiteral, Host: host}
+	}
+
+	p.mu.RLock()
+	blocked := p.block.contains(host)
+	allowed := len(p.allow) == 0 || p.allow.contains(host)
+	p.mu.RUnlock()
+	if blocked {
+		return &Violation{Reason: ReasonBlockedDomain, Host: host}
+	}
+	if !allowed {
+		return &Violation{Reason: ReasonDomainNotAllowed, Host: host}
+	}
+
+	return p.checkResolved(ctx, host)
+}
+
+// checkResolved rejects hosts like 10.0.0.5.nip.io that resolve to private
+// networks. A failed lookup is rejected too, the host could resolve to a
+// private network later.
+func (p *Policy) checkResolved(ctx context.Context, host string) error {
+	if p.resolver == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.resolveTimeout)
+	defer cancel()
+
+	addrs, err := p.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		p.logger.Debug("Failed to resolve destination host", zap.String("host", host), zap.Error(err))
+		if p.allowUnresolved {
+			return nil
+		}
+		return fmt.Errorf("%w: %s", ErrUnresolvedHost, host)
+	}
+	for _, addr := range addrs {
+		if isPrivate(addr) {
+			return &Violation{Reason: ReasonPrivateAddress, Host: host}
+		}
+	}
+	return nil
+}
+
+// isPrivate reports whether addr is anything but a public unicast address:
+// private, loopback, link-local, multicast, unspecified or shared
+func isPrivate(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return !addr.IsGlobalUnicast() || addr.IsPrivate() || sharedAddressSpace.Contains(addr)
+}
+
+// domainSet matches hosts against domains and their subdomains
+type domainSet map[string]bool
+
+func newDomainSet(domains []string) domainSet {
+	set := make(domainSet, len(domains))
+	for _, domain := range domains {
+		if domain = normalizeDomain(domain); domain != "" {
+			set[domain] = true
+		}
+	}
+	return set
+}
+
+func (s domainSet) contains(host string) bool {
+	for {
+		if s[host] {
+			return true
+		}
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok {
+			return false
+		}
+		host = parent
+	}
+}
+
+func normalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	domain = strings.TrimPrefix(domain, "*.")
+	return strings.TrimSuffix(domain, ".")
+}
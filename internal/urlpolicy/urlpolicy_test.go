@@ -0,0 +1,151 @@
+package urlpolicy
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeResolver answers lookups from a fixed table
+type fakeResolver map[string][]string
+
+func (r fakeResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	ips, ok := r[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	addrs := make([]netip.Addr, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, netip.MustParseAddr(ip))
+	}
+	return addrs, nil
+}
+
+func TestCheck(t *testing.T) {
+	policy, err := New(Config{
+		SelfHosts: []string{"sho.rt", "Localhost"},
+		Resolver: fakeResolver{
+			"example.com":      {"93.184.215.14"},
+			"10.0.0.5.nip.io":  {"10.0.0.5"},
+			"loop.example.net": {"203.0.113.9", "127.0.0.1"},
+			"mapped.example":   {"::ffff:192.168.1.1"},
+			"cgnat.example":    {"100.64.0.1"},
+			"v6.example":       {"2606:4700::1111"},
+			"metadata.example": {"169.254.169.254"},
+			"notbit.ly":        {"203.0.113.10"},
+		},
+	}, zap.NewNop())
+	require.NoError(t, err)
+	policy.SetRules(Rules{Block: []string{"bit.ly", "*.evil.example"}})
+
+	tests := []struct {
+		name       string
+		url        string
+		reason     Reason
+		unresolved bool
+	}{
+		{name: "public", url: "https://example.com/"},
+		{name: "unresolvable", url: "https://not-registered.example/", unresolved: true},
+		{name: "public ipv6", url: "https://v6.example/"},
+		{name: "ipv4 literal", url: "http://192.168.0.1/", reason: ReasonIPLiteral},
+		{name: "public ipv4 literal", url: "http://8.8.8.8/", reason: ReasonIPLiteral},
+		{name: "ipv6 literal", url: "http://[::1]:8080/", reason: ReasonIPLiteral},
+		{name: "resolves private", url: "https://10.0.0.5.nip.io/", reason: ReasonPrivateAddress},
+		{name: "any address private", url: "https://loop.example.net/", reason: ReasonPrivateAddress},
+		{name: "mapped private", url: "https://mapped.example/", reason: ReasonPrivateAddress},
+		{name: "shared address space", url: "https://cgnat.example/", reason: ReasonPrivateAddress},
+		{name: "link-local", url: "https://metadata.example/latest", reason: ReasonPrivateAddress},
+		{name: "self", url: "https://sho.rt/abc123", reason: ReasonSelfReference},
+		{name: "self with port", url: "http://localhost:8080/abc123", reason: ReasonSelfReference},
+		{name: "blocked", url: "https://bit.ly/x", reason: ReasonBlockedDomain},
+		{name: "blocked subdomain", url: "https://www.bit.ly/x", reason: ReasonBlockedDomain},
+		{name: "wildcard entry", url: "https://a.b.evil.example/", reason: ReasonBlockedDomain},
+		{name: "lookalike not blocked", url: "https://notbit.ly/", reason: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := policy.Check(context.Background(), tc.url)
+			if tc.unresolved {
+				require.ErrorIs(t, err, ErrUnresolvedHost)
+				return
+			}
+			if tc.reason == "" {
+				require.NoError(t, err)
+				return
+			}
+			var violation *Violation
+			require.ErrorAs(t, err, &violation)
+			require.Equal(t, tc.reason, violation.Reason)
+		})
+	}
+}
+
+func TestCheck_AllowUnresolved(t *testing.T) {
+	policy, err := New(Config{Resolver: fakeResolver{}, AllowUnresolved: true}, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, policy.Check(context.Background(), "https://not-registered.example/"))
+}
+
+func TestCheck_Allowlist(t *testing.T) {
+	policy, err := New(Config{}, zap.NewNop())
+	require.NoError(t, err)
+	policy.SetRules(Rules{Allow: []string{"example.com"}, Block: []string{"private.example.com"}})
+
+	require.NoError(t, policy.Check(context.Background(), "https://example.com/"))
+	require.NoError(t, policy.Check(context.Background(), "https://docs.example.com/"))
+
+	var violation *Violation
+	require.ErrorAs(t, policy.Check(context.Background(), "https://example.org/"), &violation)
+	require.Equal(t, ReasonDomainNotAllowed, violation.Reason)
+
+	// block wins over allow
+	require.ErrorAs(t, policy.Check(context.Background(), "https://private.example.com/"), &violation)
+	require.Equal(t, ReasonBlockedDomain, violation.Reason)
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("block:\n  - bit.ly\n"), 0o644))
+
+	policy, err := New(Config{RulesFile: path}, zap.NewNop())
+	require.NoError(t, err)
+	require.Error(t, policy.Check(context.Background(), "https://bit.ly/x"))
+	require.NoError(t, policy.Check(context.Background(), "https://tinyurl.com/x"))
+
+	// unchanged files aren't parsed again
+	changed, err := policy.Reload()
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	require.NoError(t, os.WriteFile(path, []byte("block:\n  - tinyurl.com\n"), 0o644))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	changed, err = policy.Reload()
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.NoError(t, policy.Check(context.Background(), "https://bit.ly/x"))
+	require.Error(t, policy.Check(context.Background(), "https://tinyurl.com/x"))
+
+	// a broken file keeps the last good rules
+	require.NoError(t, os.WriteFile(path, []byte("block: [unclosed\n"), 0o644))
+	later = later.Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	_, err = policy.Reload()
+	require.Error(t, err)
+	require.Error(t, policy.Check(context.Background(), "https://tinyurl.com/x"))
+}
+
+func TestNew_MissingRulesFile(t *testing.T) {
+	_, err := New(Config{RulesFile: filepath.Join(t.TempDir(), "missing.yaml")}, zap.NewNop())
+	require.Error(t, err)
+}
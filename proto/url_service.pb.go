@@ -25,28 +25,2569 @@ const (
 
 // These replace your JSON structs
 type CreateURLRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	OriginalUrl string                 `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	UserId      string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Optional human-readable short code, e.g. "spring-sale"
+	CustomAlias string `protobuf:"bytes,3,opt,name=custom_alias,json=customAlias,proto3" json:"custom_alias,omitempty"`
+	// Optional absolute expiry as unix seconds, mutually exclusive with ttl_seconds
+	ExpiresAt int64 `protobuf:"varint,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// Optional lifetime in seconds from creation
+	TtlSeconds int64 `protobuf:"varint,5,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	// Return the user's existing live link to the same URL instead of creating another.
+	// Ignored when custom_alias is set.
+	Dedupe bool `protobuf:"varint,6,opt,name=dedupe,proto3" json:"dedupe,omitempty"`
+	// Show a "you are leaving" page instead of redirecting straight away
+	Interstitial bool `protobuf:"varint,7,opt,name=interstitial,proto3" json:"interstitial,omitempty"`
+	// HTTP status redirects answer with: 301, 302, 307 or 308, 0 means 302
+	RedirectType int32 `protobuf:"varint,8,opt,name=redirect_type,json=redirectType,proto3" json:"redirect_type,omitempty"`
+	// How the incoming query string is merged into the destination's:
+	// "off" (default), "prefer_destination" or "prefer_request"
+	QueryPassthrough string `protobuf:"bytes,9,opt,name=query_passthrough,json=queryPassthrough,proto3" json:"query_passthrough,omitempty"`
+	// Wildcard link, /{short_code}/rest/of/path appends rest/of/path to the destination
+	PathPassthrough bool `protobuf:"varint,10,opt,name=path_passthrough,json=pathPassthrough,proto3" json:"path_passthrough,omitempty"`
+	// Optional campaign of the same user whose UTM parameters are added on redirect
+	CampaignId int64 `protobuf:"varint,11,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	// Optional per-country destinations keyed by ISO 3166-1 alpha-2 code,
+	// visitors from other countries go to original_url
+	GeoRoutes map[string]string `protobuf:"bytes,12,rep,name=geo_routes,json=geoRoutes,proto3" json:"geo_routes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Optional per-platform destinations keyed by "ios", "android" or
+	// "desktop", android may use intent:// URLs. They win over geo_routes.
+	DeviceRoutes map[string]string `protobuf:"bytes,13,rep,name=device_routes,json=deviceRoutes,proto3" json:"device_routes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Optional A/B split, each visitor sticks to one variant picked by
+	// weight. Variants replace original_url as the destination, device and
+	// geo routes still win over them.
+	Variants []*Variant `protobuf:"bytes,14,rep,name=variants,proto3" json:"variants,omitempty"`
+	// Optional launch time as unix seconds, the link doesn't resolve before it
+	ActiveFrom int64 `protobuf:"varint,15,opt,name=active_from,json=activeFrom,proto3" json:"active_from,omitempty"`
+	// Optional time windows with their own destination, the first window
+	// containing the current time wins over every other destination
+	Schedule []*ScheduleEntry `protobuf:"bytes,16,rep,name=schedule,proto3" json:"schedule,omitempty"`
+	// Optional number of redirects the link answers, 1 for a one-time link
+	MaxClicks     int64 `protobuf:"varint,17,opt,name=max_clicks,json=maxClicks,proto3" json:"max_clicks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateURLRequest) Reset() {
+	*x = CreateURLRequest{}
+	mi := &file_proto_url_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateURLRequest) ProtoMessage() {}
+
+func (x *CreateURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateURLRequest.ProtoReflect.Descriptor instead.
+func (*CreateURLRequest) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateURLRequest) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *CreateURLRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateURLRequest) GetCustomAlias() string {
+	if x != nil {
+		return x.CustomAlias
+	}
+	return ""
+}
+
+func (x *CreateURLRequest) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *CreateURLRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+func (x *CreateURLRequest) GetDedupe() bool {
+	if x != nil {
+		return x.Dedupe
+	}
+	return false
+}
+
+func (x *CreateURLRequest) GetInterstitial() bool {
+	if x != nil {
+		return x.Interstitial
+	}
+	return false
+}
+
+func (x *CreateURLRequest) GetRedirectType() int32 {
+	if x != nil {
+		return x.RedirectType
+	}
+	return 0
+}
+
+func (x *CreateURLRequest) GetQueryPassthrough() string {
+	if x != nil {
+		return x.QueryPassthrough
+	}
+	return ""
+}
+
+func (x *CreateURLRequest) GetPathPassthrough() bool {
+	if x != nil {
+		return x.PathPassthrough
+	}
+	return false
+}
+
+func (x *CreateURLRequest) GetCampaignId() int64 {
+	if x != nil {
+		return x.CampaignId
+	}
+	return 0
+}
+
+func (x *CreateURLRequest) GetGeoRoutes() map[string]string {
+	if x != nil {
+		return x.GeoRoutes
+	}
+	return nil
+}
+
+func (x *CreateURLRequest) GetDeviceRoutes() map[string]string {
+	if x != nil {
+		return x.DeviceRoutes
+	}
+	return nil
+}
+
+func (x *CreateURLRequest) GetVariants() []*Variant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+func (x *CreateURLRequest) GetActiveFrom() int64 {
+	if x != nil {
+		return x.ActiveFrom
+	}
+	return 0
+}
+
+func (x *CreateURLRequest) GetSchedule() []*ScheduleEntry {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+func (x *CreateURLRequest) GetMaxClicks() int64 {
+	if x != nil {
+		return x.MaxClicks
+	}
+	return 0
+}
+
+// Variant is one destination of an A/B split
+type Variant struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Reported with clicks: 1-32 letters, digits, - or _, unique per link
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Url  string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	// Share of visitors relative to the other variants' weights, 1-1000
+	Weight        int32 `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Variant) Reset() {
+	*x = Variant{}
+	mi := &file_proto_url_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Variant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Variant) ProtoMessage() {}
+
+func (x *Variant) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Variant.ProtoReflect.Descriptor instead.
+func (*Variant) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Variant) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Variant) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Variant) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+// ScheduleEntry sends a link elsewhere for a time window, times are unix
+// seconds and 0 leaves that side of the window open
+type ScheduleEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Start         int64                  `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End           int64                  `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	Url           string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScheduleEntry) Reset() {
+	*x = ScheduleEntry{}
+	mi := &file_proto_url_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduleEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleEntry) ProtoMessage() {}
+
+func (x *ScheduleEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleEntry.ProtoReflect.Descriptor instead.
+func (*ScheduleEntry) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ScheduleEntry) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *ScheduleEntry) GetEnd() int64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *ScheduleEntry) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+type CreateURLResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	ShortUrl  string                 `protobuf:"bytes,2,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	Success   bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	Error     string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	// Expiry as unix seconds, 0 if the link never expires
+	ExpiresAt int64 `protobuf:"varint,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// Set when dedupe returned an existing link instead of creating one
+	Existing         bool              `protobuf:"varint,6,opt,name=existing,proto3" json:"existing,omitempty"`
+	Interstitial     bool              `protobuf:"varint,7,opt,name=interstitial,proto3" json:"interstitial,omitempty"`
+	RedirectType     int32             `protobuf:"varint,8,opt,name=redirect_type,json=redirectType,proto3" json:"redirect_type,omitempty"`
+	QueryPassthrough string            `protobuf:"bytes,9,opt,name=query_passthrough,json=queryPassthrough,proto3" json:"query_passthrough,omitempty"`
+	PathPassthrough  bool              `protobuf:"varint,10,opt,name=path_passthrough,json=pathPassthrough,proto3" json:"path_passthrough,omitempty"`
+	CampaignId       int64             `protobuf:"varint,11,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	GeoRoutes        map[string]string `protobuf:"bytes,12,rep,name=geo_routes,json=geoRoutes,proto3" json:"geo_routes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	DeviceRoutes     map[string]string `protobuf:"bytes,13,rep,name=device_routes,json=deviceRoutes,proto3" json:"device_routes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Variants         []*Variant        `protobuf:"bytes,14,rep,name=variants,proto3" json:"variants,omitempty"`
+	ActiveFrom       int64             `protobuf:"varint,15,opt,name=active_from,json=activeFrom,proto3" json:"active_from,omitempty"`
+	Schedule         []*ScheduleEntry  `protobuf:"bytes,16,rep,name=schedule,proto3" json:"schedule,omitempty"`
+	MaxClicks        int64             `protobuf:"varint,17,opt,name=max_clicks,json=maxClicks,proto3" json:"max_clicks,omitempty"`
+	// The destination as stored, canonicalized, or the existing link's on a dedupe hit
+	OriginalUrl   string `protobuf:"bytes,18,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateURLResponse) Reset() {
+	*x = CreateURLResponse{}
+	mi := &file_proto_url_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateURLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateURLResponse) ProtoMessage() {}
+
+func (x *CreateURLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateURLResponse.ProtoReflect.Descriptor instead.
+func (*CreateURLResponse) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateURLResponse) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *CreateURLResponse) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *CreateURLResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CreateURLResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *CreateURLResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *CreateURLResponse) GetExisting() bool {
+	if x != nil {
+		return x.Existing
+	}
+	return false
+}
+
+func (x *CreateURLResponse) GetInterstitial() bool {
+	if x != nil {
+		return x.Interstitial
+	}
+	return false
+}
+
+func (x *CreateURLResponse) GetRedirectType() int32 {
+	if x != nil {
+		return x.RedirectType
+	}
+	return 0
+}
+
+func (x *CreateURLResponse) GetQueryPassthrough() string {
+	if x != nil {
+		return x.QueryPassthrough
+	}
+	return ""
+}
+
+func (x *CreateURLResponse) GetPathPassthrough() bool {
+	if x != nil {
+		return x.PathPassthrough
+	}
+	return false
+}
+
+func (x *CreateURLResponse) GetCampaignId() int64 {
+	if x != nil {
+		return x.CampaignId
+	}
+	return 0
+}
+
+func (x *CreateURLResponse) GetGeoRoutes() map[string]string {
+	if x != nil {
+		return x.GeoRoutes
+	}
+	return nil
+}
+
+func (x *CreateURLResponse) GetDeviceRoutes() map[string]string {
+	if x != nil {
+		return x.DeviceRoutes
+	}
+	return nil
+}
+
+func (x *CreateURLResponse) GetVariants() []*Variant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+func (x *CreateURLResponse) GetActiveFrom() int64 {
+	if x != nil {
+		return x.ActiveFrom
+	}
+	return 0
+}
+
+func (x *CreateURLResponse) GetSchedule() []*ScheduleEntry {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+func (x *CreateURLResponse) GetMaxClicks() int64 {
+	if x != nil {
+		return x.MaxClicks
+	}
+	return 0
+}
+
+func (x *CreateURLResponse) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+type GetURLRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	// Set when a path follows the short code, only path_passthrough links match
+	WithPath bool `protobuf:"varint,2,opt,name=with_path,json=withPath,proto3" json:"with_path,omitempty"`
+	// Visitor address, picks the destination of links with geo_routes
+	ClientIp string `protobuf:"bytes,3,opt,name=client_ip,json=clientIp,proto3" json:"client_ip,omitempty"`
+	// Visitor User-Agent, picks the destination of links with device_routes
+	UserAgent string `protobuf:"bytes,4,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	// Sticky visitor identifier, picks the variant of links with variants.
+	// The gateway always sends one, derived from the visitor's address and
+	// User-Agent until they have a cookie. Without one client_ip and
+	// user_agent are used.
+	VisitorId     string `protobuf:"bytes,5,opt,name=visitor_id,json=visitorId,proto3" json:"visitor_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetURLRequest) Reset() {
+	*x = GetURLRequest{}
+	mi := &file_proto_url_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetURLRequest) ProtoMessage() {}
+
+func (x *GetURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetURLRequest.ProtoReflect.Descriptor instead.
+func (*GetURLRequest) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetURLRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *GetURLRequest) GetWithPath() bool {
+	if x != nil {
+		return x.WithPath
+	}
+	return false
+}
+
+func (x *GetURLRequest) GetClientIp() string {
+	if x != nil {
+		return x.ClientIp
+	}
+	return ""
+}
+
+func (x *GetURLRequest) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+func (x *GetURLRequest) GetVisitorId() string {
+	if x != nil {
+		return x.VisitorId
+	}
+	return ""
+}
+
+type GetURLResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	OriginalUrl string                 `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	Found       bool                   `protobuf:"varint,2,opt,name=found,proto3" json:"found,omitempty"`
+	Error       string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	// Set when the link exists but is past its expiry
+	Expired bool `protobuf:"varint,4,opt,name=expired,proto3" json:"expired,omitempty"`
+	// Set when the destination matched the threat list, original_url is left empty
+	Quarantined bool `protobuf:"varint,5,opt,name=quarantined,proto3" json:"quarantined,omitempty"`
+	// Set when the link asks for an interstitial page before redirecting
+	Interstitial bool `protobuf:"varint,6,opt,name=interstitial,proto3" json:"interstitial,omitempty"`
+	// HTTP status to redirect with
+	RedirectType int32 `protobuf:"varint,7,opt,name=redirect_type,json=redirectType,proto3" json:"redirect_type,omitempty"`
+	// Expiry as unix seconds, 0 if the link never expires. Bounds how long
+	// a permanent redirect may be cached.
+	ExpiresAt int64 `protobuf:"varint,8,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// Passthrough settings, applied by the gateway which has the incoming request
+	QueryPassthrough string `protobuf:"bytes,9,opt,name=query_passthrough,json=queryPassthrough,proto3" json:"query_passthrough,omitempty"`
+	PathPassthrough  bool   `protobuf:"varint,10,opt,name=path_passthrough,json=pathPassthrough,proto3" json:"path_passthrough,omitempty"`
+	// The link's campaign, its UTM parameters are already in original_url
+	CampaignId int64 `protobuf:"varint,11,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	// Visitor country resolved from client_ip, empty if unknown
+	Country string `protobuf:"bytes,12,opt,name=country,proto3" json:"country,omitempty"`
+	// Set when the destination depends on the visitor, as with geo_routes,
+	// so shared caches must not keep the redirect
+	PerVisitor bool `protobuf:"varint,13,opt,name=per_visitor,json=perVisitor,proto3" json:"per_visitor,omitempty"`
+	// Visitor platform classified from user_agent, empty if unknown
+	Platform string `protobuf:"bytes,14,opt,name=platform,proto3" json:"platform,omitempty"`
+	// Name of the variant the visitor was assigned, empty for links without variants
+	Variant string `protobuf:"bytes,15,opt,name=variant,proto3" json:"variant,omitempty"`
+	// Set when the link exists but its active_from hasn't come yet
+	Inactive bool `protobuf:"varint,16,opt,name=inactive,proto3" json:"inactive,omitempty"`
+	// Unix seconds when the destination may change on a schedule, 0 if it
+	// won't. Bounds how long a permanent redirect may be cached.
+	ChangesAt int64 `protobuf:"varint,17,opt,name=changes_at,json=changesAt,proto3" json:"changes_at,omitempty"`
+	// Set when the link has used up its max_clicks
+	Exhausted bool `protobuf:"varint,18,opt,name=exhausted,proto3" json:"exhausted,omitempty"`
+	// Set for links with max_clicks, their redirects must not be cached
+	ClickLimited  bool `protobuf:"varint,19,opt,name=click_limited,json=clickLimited,proto3" json:"click_limited,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetURLResponse) Reset() {
+	*x = GetURLResponse{}
+	mi := &file_proto_url_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetURLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetURLResponse) ProtoMessage() {}
+
+func (x *GetURLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetURLResponse.ProtoReflect.Descriptor instead.
+func (*GetURLResponse) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetURLResponse) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *GetURLResponse) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+func (x *GetURLResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *GetURLResponse) GetExpired() bool {
+	if x != nil {
+		return x.Expired
+	}
+	return false
+}
+
+func (x *GetURLResponse) GetQuarantined() bool {
+	if x != nil {
+		return x.Quarantined
+	}
+	return false
+}
+
+func (x *GetURLResponse) GetInterstitial() bool {
+	if x != nil {
+		return x.Interstitial
+	}
+	return false
+}
+
+func (x *GetURLResponse) GetRedirectType() int32 {
+	if x != nil {
+		return x.RedirectType
+	}
+	return 0
+}
+
+func (x *GetURLResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *GetURLResponse) GetQueryPassthrough() string {
+	if x != nil {
+		return x.QueryPassthrough
+	}
+	return ""
+}
+
+func (x *GetURLResponse) GetPathPassthrough() bool {
+	if x != nil {
+		return x.PathPassthrough
+	}
+	return false
+}
+
+func (x *GetURLResponse) GetCampaignId() int64 {
+	if x != nil {
+		return x.CampaignId
+	}
+	return 0
+}
+
+func (x *GetURLResponse) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *GetURLResponse) GetPerVisitor() bool {
+	if x != nil {
+		return x.PerVisitor
+	}
+	return false
+}
+
+func (x *GetURLResponse) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *GetURLResponse) GetVariant() string {
+	if x != nil {
+		return x.Variant
+	}
+	return ""
+}
+
+func (x *GetURLResponse) GetInactive() bool {
+	if x != nil {
+		return x.Inactive
+	}
+	return false
+}
+
+func (x *GetURLResponse) GetChangesAt() int64 {
+	if x != nil {
+		return x.ChangesAt
+	}
+	return 0
+}
+
+func (x *GetURLResponse) GetExhausted() bool {
+	if x != nil {
+		return x.Exhausted
+	}
+	return false
+}
+
+func (x *GetURLResponse) GetClickLimited() bool {
+	if x != nil {
+		return x.ClickLimited
+	}
+	return false
+}
+
+type HealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthRequest) Reset() {
+	*x = HealthRequest{}
+	mi := &file_proto_url_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthRequest) ProtoMessage() {}
+
+func (x *HealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
+func (*HealthRequest) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{6}
+}
+
+type HealthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Healthy       bool                   `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthResponse) Reset() {
+	*x = HealthResponse{}
+	mi := &file_proto_url_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthResponse) ProtoMessage() {}
+
+func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
+func (*HealthResponse) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *HealthResponse) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+// URLDetails is the full view of a stored link, timestamps are unix seconds
+type URLDetails struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ShortCode   string                 `protobuf:"bytes,2,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	ShortUrl    string                 `protobuf:"bytes,3,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	OriginalUrl string                 `protobuf:"bytes,4,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	UserId      string                 `protobuf:"bytes,5,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	CreatedAt   int64                  `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt   int64                  `protobuf:"varint,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	ClickCount  int64                  `protobuf:"varint,8,opt,name=click_count,json=clickCount,proto3" json:"click_count,omitempty"`
+	ExpiresAt   int64                  `protobuf:"varint,9,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// Set while the destination matches the threat list
+	Quarantined      bool              `protobuf:"varint,10,opt,name=quarantined,proto3" json:"quarantined,omitempty"`
+	Interstitial     bool              `protobuf:"varint,11,opt,name=interstitial,proto3" json:"interstitial,omitempty"`
+	RedirectType     int32             `protobuf:"varint,12,opt,name=redirect_type,json=redirectType,proto3" json:"redirect_type,omitempty"`
+	QueryPassthrough string            `protobuf:"bytes,13,opt,name=query_passthrough,json=queryPassthrough,proto3" json:"query_passthrough,omitempty"`
+	PathPassthrough  bool              `protobuf:"varint,14,opt,name=path_passthrough,json=pathPassthrough,proto3" json:"path_passthrough,omitempty"`
+	CampaignId       int64             `protobuf:"varint,15,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	GeoRoutes        map[string]string `protobuf:"bytes,16,rep,name=geo_routes,json=geoRoutes,proto3" json:"geo_routes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	DeviceRoutes     map[string]string `protobuf:"bytes,17,rep,name=device_routes,json=deviceRoutes,proto3" json:"device_routes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Variants         []*Variant        `protobuf:"bytes,18,rep,name=variants,proto3" json:"variants,omitempty"`
+	ActiveFrom       int64             `protobuf:"varint,19,opt,name=active_from,json=activeFrom,proto3" json:"active_from,omitempty"`
+	Schedule         []*ScheduleEntry  `protobuf:"bytes,20,rep,name=schedule,proto3" json:"schedule,omitempty"`
+	// 0 if the link has no click limit
+	MaxClicks     int64 `protobuf:"varint,21,opt,name=max_clicks,json=maxClicks,proto3" json:"max_clicks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *URLDetails) Reset() {
+	*x = URLDetails{}
+	mi := &file_proto_url_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *URLDetails) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*URLDetails) ProtoMessage() {}
+
+func (x *URLDetails) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use URLDetails.ProtoReflect.Descriptor instead.
+func (*URLDetails) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *URLDetails) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *URLDetails) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *URLDetails) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *URLDetails) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *URLDetails) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *URLDetails) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *URLDetails) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+func (x *URLDetails) GetClickCount() int64 {
+	if x != nil {
+		return x.ClickCount
+	}
+	return 0
+}
+
+func (x *URLDetails) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *URLDetails) GetQuarantined() bool {
+	if x != nil {
+		return x.Quarantined
+	}
+	return false
+}
+
+func (x *URLDetails) GetInterstitial() bool {
+	if x != nil {
+		return x.Interstitial
+	}
+	return false
+}
+
+func (x *URLDetails) GetRedirectType() int32 {
+	if x != nil {
+		return x.RedirectType
+	}
+	return 0
+}
+
+func (x *URLDetails) GetQueryPassthrough() string {
+	if x != nil {
+		return x.QueryPassthrough
+	}
+	return ""
+}
+
+func (x *URLDetails) GetPathPassthrough() bool {
+	if x != nil {
+		return x.PathPassthrough
+	}
+	return false
+}
+
+func (x *URLDetails) GetCampaignId() int64 {
+	if x != nil {
+		return x.CampaignId
+	}
+	return 0
+}
+
+func (x *URLDetails) GetGeoRoutes() map[string]string {
+	if x != nil {
+		return x.GeoRoutes
+	}
+	return nil
+}
+
+func (x *URLDetails) GetDeviceRoutes() map[string]string {
+	if x != nil {
+		return x.DeviceRoutes
+	}
+	return nil
+}
+
+func (x *URLDetails) GetVariants() []*Variant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+func (x *URLDetails) GetActiveFrom() int64 {
+	if x != nil {
+		return x.ActiveFrom
+	}
+	return 0
+}
+
+func (x *URLDetails) GetSchedule() []*ScheduleEntry {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+func (x *URLDetails) GetMaxClicks() int64 {
+	if x != nil {
+		return x.MaxClicks
+	}
+	return 0
+}
+
+type UpdateURLRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	UserId    string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// New destination, left unchanged when empty
+	OriginalUrl string `protobuf:"bytes,3,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	// New expiry, same semantics as CreateURLRequest
+	ExpiresAt  int64 `protobuf:"varint,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	TtlSeconds int64 `protobuf:"varint,5,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	// Removes any expiry from the link
+	ClearExpiry bool `protobuf:"varint,6,opt,name=clear_expiry,json=clearExpiry,proto3" json:"clear_expiry,omitempty"`
+	// Turns the interstitial page on or off, left unchanged when unset
+	Interstitial *bool `protobuf:"varint,7,opt,name=interstitial,proto3,oneof" json:"interstitial,omitempty"`
+	// New redirect status, same values as CreateURLRequest, left unchanged when 0
+	RedirectType int32 `protobuf:"varint,8,opt,name=redirect_type,json=redirectType,proto3" json:"redirect_type,omitempty"`
+	// New query passthrough mode, left unchanged when empty
+	QueryPassthrough string `protobuf:"bytes,9,opt,name=query_passthrough,json=queryPassthrough,proto3" json:"query_passthrough,omitempty"`
+	PathPassthrough  *bool  `protobuf:"varint,10,opt,name=path_passthrough,json=pathPassthrough,proto3,oneof" json:"path_passthrough,omitempty"`
+	// Moves the link to another campaign, left unchanged when 0
+	CampaignId int64 `protobuf:"varint,11,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	// Takes the link out of its campaign
+	ClearCampaign bool `protobuf:"varint,12,opt,name=clear_campaign,json=clearCampaign,proto3" json:"clear_campaign,omitempty"`
+	// Replaces all per-country destinations, left unchanged when empty
+	GeoRoutes map[string]string `protobuf:"bytes,13,rep,name=geo_routes,json=geoRoutes,proto3" json:"geo_routes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Removes all per-country destinations
+	ClearGeoRoutes bool `protobuf:"varint,14,opt,name=clear_geo_routes,json=clearGeoRoutes,proto3" json:"clear_geo_routes,omitempty"`
+	// Replaces all per-platform destinations, left unchanged when empty
+	DeviceRoutes map[string]string `protobuf:"bytes,15,rep,name=device_routes,json=deviceRoutes,proto3" json:"device_routes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Removes all per-platform destinations
+	ClearDeviceRoutes bool `protobuf:"varint,16,opt,name=clear_device_routes,json=clearDeviceRoutes,proto3" json:"clear_device_routes,omitempty"`
+	// Replaces all variants, left unchanged when empty. Changing the weights
+	// moves some visitors to another variant.
+	Variants []*Variant `protobuf:"bytes,17,rep,name=variants,proto3" json:"variants,omitempty"`
+	// Removes the A/B split, visitors go to original_url again
+	ClearVariants bool `protobuf:"varint,18,opt,name=clear_variants,json=clearVariants,proto3" json:"clear_variants,omitempty"`
+	// New launch time, left unchanged when 0
+	ActiveFrom int64 `protobuf:"varint,19,opt,name=active_from,json=activeFrom,proto3" json:"active_from,omitempty"`
+	// Makes the link resolve straight away
+	ClearActiveFrom bool `protobuf:"varint,20,opt,name=clear_active_from,json=clearActiveFrom,proto3" json:"clear_active_from,omitempty"`
+	// Replaces the whole schedule, left unchanged when empty
+	Schedule []*ScheduleEntry `protobuf:"bytes,21,rep,name=schedule,proto3" json:"schedule,omitempty"`
+	// Removes the schedule
+	ClearSchedule bool `protobuf:"varint,22,opt,name=clear_schedule,json=clearSchedule,proto3" json:"clear_schedule,omitempty"`
+	// New click limit, counting clicks already made, left unchanged when 0
+	MaxClicks int64 `protobuf:"varint,23,opt,name=max_clicks,json=maxClicks,proto3" json:"max_clicks,omitempty"`
+	// Removes the click limit
+	ClearMaxClicks bool `protobuf:"varint,24,opt,name=clear_max_clicks,json=clearMaxClicks,proto3" json:"clear_max_clicks,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UpdateURLRequest) Reset() {
+	*x = UpdateURLRequest{}
+	mi := &file_proto_url_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateURLRequest) ProtoMessage() {}
+
+func (x *UpdateURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateURLRequest.ProtoReflect.Descriptor instead.
+func (*UpdateURLRequest) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdateURLRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *UpdateURLRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UpdateURLRequest) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *UpdateURLRequest) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *UpdateURLRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+func (x *UpdateURLRequest) GetClearExpiry() bool {
+	if x != nil {
+		return x.ClearExpiry
+	}
+	return false
+}
+
+func (x *UpdateURLRequest) GetInterstitial() bool {
+	if x != nil && x.Interstitial != nil {
+		return *x.Interstitial
+	}
+	return false
+}
+
+func (x *UpdateURLRequest) GetRedirectType() int32 {
+	if x != nil {
+		return x.RedirectType
+	}
+	return 0
+}
+
+func (x *UpdateURLRequest) GetQueryPassthrough() string {
+	if x != nil {
+		return x.QueryPassthrough
+	}
+	return ""
+}
+
+func (x *UpdateURLRequest) GetPathPassthrough() bool {
+	if x != nil && x.PathPassthrough != nil {
+		return *x.PathPassthrough
+	}
+	return false
+}
+
+func (x *UpdateURLRequest) GetCampaignId() int64 {
+	if x != nil {
+		return x.CampaignId
+	}
+	return 0
+}
+
+func (x *UpdateURLRequest) GetClearCampaign() bool {
+	if x != nil {
+		return x.ClearCampaign
+	}
+	return false
+}
+
+func (x *UpdateURLRequest) GetGeoRoutes() map[string]string {
+	if x != nil {
+		return x.GeoRoutes
+	}
+	return nil
+}
+
+func (x *UpdateURLRequest) GetClearGeoRoutes() bool {
+	if x != nil {
+		return x.ClearGeoRoutes
+	}
+	return false
+}
+
+func (x *UpdateURLRequest) GetDeviceRoutes() map[string]string {
+	if x != nil {
+		return x.DeviceRoutes
+	}
+	return nil
+}
+
+func (x *UpdateURLRequest) GetClearDeviceRoutes() bool {
+	if x != nil {
+		return x.ClearDeviceRoutes
+	}
+	return false
+}
+
+func (x *UpdateURLRequest) GetVariants() []*Variant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+func (x *UpdateURLRequest) GetClearVariants() bool {
+	if x != nil {
+		return x.ClearVariants
+	}
+	return false
+}
+
+func (x *UpdateURLRequest) GetActiveFrom() int64 {
+	if x != nil {
+		return x.ActiveFrom
+	}
+	return 0
+}
+
+func (x *UpdateURLRequest) GetClearActiveFrom() bool {
+	if x != nil {
+		return x.ClearActiveFrom
+	}
+	return false
+}
+
+func (x *UpdateURLRequest) GetSchedule() []*ScheduleEntry {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+func (x *UpdateURLRequest) GetClearSchedule() bool {
+	if x != nil {
+		return x.ClearSchedule
+	}
+	return false
+}
+
+func (x *UpdateURLRequest) GetMaxClicks() int64 {
+	if x != nil {
+		return x.MaxClicks
+	}
+	return 0
+}
+
+func (x *UpdateURLRequest) GetClearMaxClicks() bool {
+	if x != nil {
+		return x.ClearMaxClicks
+	}
+	return false
+}
+
+type UpdateURLResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           *URLDetails            `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateURLResponse) Reset() {
+	*x = UpdateURLResponse{}
+	mi := &file_proto_url_service_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateURLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateURLResponse) ProtoMessage() {}
+
+func (x *UpdateURLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateURLResponse.ProtoReflect.Descriptor instead.
+func (*UpdateURLResponse) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UpdateURLResponse) GetUrl() *URLDetails {
+	if x != nil {
+		return x.Url
+	}
+	return nil
+}
+
+type DeleteURLRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteURLRequest) Reset() {
+	*x = DeleteURLRequest{}
+	mi := &file_proto_url_service_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteURLRequest) ProtoMessage() {}
+
+func (x *DeleteURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteURLRequest.ProtoReflect.Descriptor instead.
+func (*DeleteURLRequest) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DeleteURLRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *DeleteURLRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type DeleteURLResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteURLResponse) Reset() {
+	*x = DeleteURLResponse{}
+	mi := &file_proto_url_service_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteURLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteURLResponse) ProtoMessage() {}
+
+func (x *DeleteURLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteURLResponse.ProtoReflect.Descriptor instead.
+func (*DeleteURLResponse) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DeleteURLResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type GetURLDetailsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetURLDetailsRequest) Reset() {
+	*x = GetURLDetailsRequest{}
+	mi := &file_proto_url_service_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetURLDetailsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetURLDetailsRequest) ProtoMessage() {}
+
+func (x *GetURLDetailsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetURLDetailsRequest.ProtoReflect.Descriptor instead.
+func (*GetURLDetailsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetURLDetailsRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *GetURLDetailsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type GetURLDetailsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           *URLDetails            `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetURLDetailsResponse) Reset() {
+	*x = GetURLDetailsResponse{}
+	mi := &file_proto_url_service_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetURLDetailsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetURLDetailsResponse) ProtoMessage() {}
+
+func (x *GetURLDetailsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetURLDetailsResponse.ProtoReflect.Descriptor instead.
+func (*GetURLDetailsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetURLDetailsResponse) GetUrl() *URLDetails {
+	if x != nil {
+		return x.Url
+	}
+	return nil
+}
+
+type ListURLsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListURLsRequest) Reset() {
+	*x = ListURLsRequest{}
+	mi := &file_proto_url_service_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListURLsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListURLsRequest) ProtoMessage() {}
+
+func (x *ListURLsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListURLsRequest.ProtoReflect.Descriptor instead.
+func (*ListURLsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListURLsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListURLsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListURLsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListURLsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Urls          []*URLDetails          `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListURLsResponse) Reset() {
+	*x = ListURLsResponse{}
+	mi := &file_proto_url_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListURLsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListURLsResponse) ProtoMessage() {}
+
+func (x *ListURLsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListURLsResponse.ProtoReflect.Descriptor instead.
+func (*ListURLsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListURLsResponse) GetUrls() []*URLDetails {
+	if x != nil {
+		return x.Urls
+	}
+	return nil
+}
+
+type GetURLPreviewRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetURLPreviewRequest) Reset() {
+	*x = GetURLPreviewRequest{}
+	mi := &file_proto_url_service_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetURLPreviewRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetURLPreviewRequest) ProtoMessage() {}
+
+func (x *GetURLPreviewRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetURLPreviewRequest.ProtoReflect.Descriptor instead.
+func (*GetURLPreviewRequest) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetURLPreviewRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+// GetURLPreviewResponse is safe to show to anyone holding the short code,
+// timestamps are unix seconds
+type GetURLPreviewResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	// The current destination, following the schedule. Left empty for
+	// quarantined links and links that aren't active yet.
+	OriginalUrl  string `protobuf:"bytes,2,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	CreatedAt    int64  `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ClickCount   int64  `protobuf:"varint,4,opt,name=click_count,json=clickCount,proto3" json:"click_count,omitempty"`
+	ExpiresAt    int64  `protobuf:"varint,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Expired      bool   `protobuf:"varint,6,opt,name=expired,proto3" json:"expired,omitempty"`
+	Quarantined  bool   `protobuf:"varint,7,opt,name=quarantined,proto3" json:"quarantined,omitempty"`
+	Interstitial bool   `protobuf:"varint,8,opt,name=interstitial,proto3" json:"interstitial,omitempty"`
+	// Launch time as unix seconds, 0 if the link has none
+	ActiveFrom int64 `protobuf:"varint,9,opt,name=active_from,json=activeFrom,proto3" json:"active_from,omitempty"`
+	Inactive   bool  `protobuf:"varint,10,opt,name=inactive,proto3" json:"inactive,omitempty"`
+	// 0 if the link has no click limit. original_url is left empty for
+	// click-limited links, a preview mustn't stand in for a click.
+	MaxClicks     int64 `protobuf:"varint,11,opt,name=max_clicks,json=maxClicks,proto3" json:"max_clicks,omitempty"`
+	Exhausted     bool  `protobuf:"varint,12,opt,name=exhausted,proto3" json:"exhausted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetURLPreviewResponse) Reset() {
+	*x = GetURLPreviewResponse{}
+	mi := &file_proto_url_service_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetURLPreviewResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetURLPreviewResponse) ProtoMessage() {}
+
+func (x *GetURLPreviewResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetURLPreviewResponse.ProtoReflect.Descriptor instead.
+func (*GetURLPreviewResponse) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetURLPreviewResponse) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *GetURLPreviewResponse) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *GetURLPreviewResponse) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *GetURLPreviewResponse) GetClickCount() int64 {
+	if x != nil {
+		return x.ClickCount
+	}
+	return 0
+}
+
+func (x *GetURLPreviewResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *GetURLPreviewResponse) GetExpired() bool {
+	if x != nil {
+		return x.Expired
+	}
+	return false
+}
+
+func (x *GetURLPreviewResponse) GetQuarantined() bool {
+	if x != nil {
+		return x.Quarantined
+	}
+	return false
+}
+
+func (x *GetURLPreviewResponse) GetInterstitial() bool {
+	if x != nil {
+		return x.Interstitial
+	}
+	return false
+}
+
+func (x *GetURLPreviewResponse) GetActiveFrom() int64 {
+	if x != nil {
+		return x.ActiveFrom
+	}
+	return 0
+}
+
+func (x *GetURLPreviewResponse) GetInactive() bool {
+	if x != nil {
+		return x.Inactive
+	}
+	return false
+}
+
+func (x *GetURLPreviewResponse) GetMaxClicks() int64 {
+	if x != nil {
+		return x.MaxClicks
+	}
+	return 0
+}
+
+func (x *GetURLPreviewResponse) GetExhausted() bool {
+	if x != nil {
+		return x.Exhausted
+	}
+	return false
+}
+
+type IssueAPIKeyRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Label to tell keys apart, e.g. "ci-bot"
+	Name          string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IssueAPIKeyRequest) Reset() {
+	*x = IssueAPIKeyRequest{}
+	mi := &file_proto_url_service_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IssueAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssueAPIKeyRequest) ProtoMessage() {}
+
+func (x *IssueAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssueAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*IssueAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *IssueAPIKeyRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *IssueAPIKeyRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type IssueAPIKeyResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	KeyId  int64                  `protobuf:"varint,1,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	ApiKey string                 `protobuf:"bytes,2,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+	// First characters of the key, safe to display
+	Prefix        string `protobuf:"bytes,3,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IssueAPIKeyResponse) Reset() {
+	*x = IssueAPIKeyResponse{}
+	mi := &file_proto_url_service_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IssueAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssueAPIKeyResponse) ProtoMessage() {}
+
+func (x *IssueAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssueAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*IssueAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *IssueAPIKeyResponse) GetKeyId() int64 {
+	if x != nil {
+		return x.KeyId
+	}
+	return 0
+}
+
+func (x *IssueAPIKeyResponse) GetApiKey() string {
+	if x != nil {
+		return x.ApiKey
+	}
+	return ""
+}
+
+func (x *IssueAPIKeyResponse) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+type RevokeAPIKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	KeyId         int64                  `protobuf:"varint,1,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAPIKeyRequest) Reset() {
+	*x = RevokeAPIKeyRequest{}
+	mi := &file_proto_url_service_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAPIKeyRequest) ProtoMessage() {}
+
+func (x *RevokeAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*RevokeAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *RevokeAPIKeyRequest) GetKeyId() int64 {
+	if x != nil {
+		return x.KeyId
+	}
+	return 0
+}
+
+func (x *RevokeAPIKeyRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type RevokeAPIKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAPIKeyResponse) Reset() {
+	*x = RevokeAPIKeyResponse{}
+	mi := &file_proto_url_service_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAPIKeyResponse) ProtoMessage() {}
+
+func (x *RevokeAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*RevokeAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *RevokeAPIKeyResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type ValidateAPIKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ApiKey        string                 `protobuf:"bytes,1,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateAPIKeyRequest) Reset() {
+	*x = ValidateAPIKeyRequest{}
+	mi := &file_proto_url_service_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateAPIKeyRequest) ProtoMessage() {}
+
+func (x *ValidateAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*ValidateAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ValidateAPIKeyRequest) GetApiKey() string {
+	if x != nil {
+		return x.ApiKey
+	}
+	return ""
+}
+
+type ValidateAPIKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Valid         bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	KeyId         int64                  `protobuf:"varint,3,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateAPIKeyResponse) Reset() {
+	*x = ValidateAPIKeyResponse{}
+	mi := &file_proto_url_service_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateAPIKeyResponse) ProtoMessage() {}
+
+func (x *ValidateAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*ValidateAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ValidateAPIKeyResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *ValidateAPIKeyResponse) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ValidateAPIKeyResponse) GetKeyId() int64 {
+	if x != nil {
+		return x.KeyId
+	}
+	return 0
+}
+
+// Hash prefixes are the first 4-32 bytes of SHA-256 hashes of URL expressions
+type UpdateThreatListRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Additions [][]byte               `protobuf:"bytes,1,rep,name=additions,proto3" json:"additions,omitempty"`
+	Removals  [][]byte               `protobuf:"bytes,2,rep,name=removals,proto3" json:"removals,omitempty"`
+	// Drop every stored prefix before adding additions, removals are ignored
+	Replace       bool `protobuf:"varint,3,opt,name=replace,proto3" json:"replace,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateThreatListRequest) Reset() {
+	*x = UpdateThreatListRequest{}
+	mi := &file_proto_url_service_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateThreatListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateThreatListRequest) ProtoMessage() {}
+
+func (x *UpdateThreatListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateThreatListRequest.ProtoReflect.Descriptor instead.
+func (*UpdateThreatListRequest) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *UpdateThreatListRequest) GetAdditions() [][]byte {
+	if x != nil {
+		return x.Additions
+	}
+	return nil
+}
+
+func (x *UpdateThreatListRequest) GetRemovals() [][]byte {
+	if x != nil {
+		return x.Removals
+	}
+	return nil
+}
+
+func (x *UpdateThreatListRequest) GetReplace() bool {
+	if x != nil {
+		return x.Replace
+	}
+	return false
+}
+
+type UpdateThreatListResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Added   int64                  `protobuf:"varint,1,opt,name=added,proto3" json:"added,omitempty"`
+	Removed int64                  `protobuf:"varint,2,opt,name=removed,proto3" json:"removed,omitempty"`
+	// Prefixes in the list after the update
+	Total         int64 `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateThreatListResponse) Reset() {
+	*x = UpdateThreatListResponse{}
+	mi := &file_proto_url_service_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateThreatListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateThreatListResponse) ProtoMessage() {}
+
+func (x *UpdateThreatListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateThreatListResponse.ProtoReflect.Descriptor instead.
+func (*UpdateThreatListResponse) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *UpdateThreatListResponse) GetAdded() int64 {
+	if x != nil {
+		return x.Added
+	}
+	return 0
+}
+
+func (x *UpdateThreatListResponse) GetRemoved() int64 {
+	if x != nil {
+		return x.Removed
+	}
+	return 0
+}
+
+func (x *UpdateThreatListResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+// Campaign is a set of UTM parameters, timestamps are unix seconds
+type Campaign struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	UtmSource     string                 `protobuf:"bytes,4,opt,name=utm_source,json=utmSource,proto3" json:"utm_source,omitempty"`
+	UtmMedium     string                 `protobuf:"bytes,5,opt,name=utm_medium,json=utmMedium,proto3" json:"utm_medium,omitempty"`
+	UtmCampaign   string                 `protobuf:"bytes,6,opt,name=utm_campaign,json=utmCampaign,proto3" json:"utm_campaign,omitempty"`
+	UtmTerm       string                 `protobuf:"bytes,7,opt,name=utm_term,json=utmTerm,proto3" json:"utm_term,omitempty"`
+	UtmContent    string                 `protobuf:"bytes,8,opt,name=utm_content,json=utmContent,proto3" json:"utm_content,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     int64                  `protobuf:"varint,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Campaign) Reset() {
+	*x = Campaign{}
+	mi := &file_proto_url_service_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Campaign) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Campaign) ProtoMessage() {}
+
+func (x *Campaign) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Campaign.ProtoReflect.Descriptor instead.
+func (*Campaign) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *Campaign) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Campaign) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Campaign) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Campaign) GetUtmSource() string {
+	if x != nil {
+		return x.UtmSource
+	}
+	return ""
+}
+
+func (x *Campaign) GetUtmMedium() string {
+	if x != nil {
+		return x.UtmMedium
+	}
+	return ""
+}
+
+func (x *Campaign) GetUtmCampaign() string {
+	if x != nil {
+		return x.UtmCampaign
+	}
+	return ""
+}
+
+func (x *Campaign) GetUtmTerm() string {
+	if x != nil {
+		return x.UtmTerm
+	}
+	return ""
+}
+
+func (x *Campaign) GetUtmContent() string {
+	if x != nil {
+		return x.UtmContent
+	}
+	return ""
+}
+
+func (x *Campaign) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Campaign) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+type CreateCampaignRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Name   string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Empty parameters are left out of destinations
+	UtmSource     string `protobuf:"bytes,3,opt,name=utm_source,json=utmSource,proto3" json:"utm_source,omitempty"`
+	UtmMedium     string `protobuf:"bytes,4,opt,name=utm_medium,json=utmMedium,proto3" json:"utm_medium,omitempty"`
+	UtmCampaign   string `protobuf:"bytes,5,opt,name=utm_campaign,json=utmCampaign,proto3" json:"utm_campaign,omitempty"`
+	UtmTerm       string `protobuf:"bytes,6,opt,name=utm_term,json=utmTerm,proto3" json:"utm_term,omitempty"`
+	UtmContent    string `protobuf:"bytes,7,opt,name=utm_content,json=utmContent,proto3" json:"utm_content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCampaignRequest) Reset() {
+	*x = CreateCampaignRequest{}
+	mi := &file_proto_url_service_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCampaignRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCampaignRequest) ProtoMessage() {}
+
+func (x *CreateCampaignRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCampaignRequest.ProtoReflect.Descriptor instead.
+func (*CreateCampaignRequest) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *CreateCampaignRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateCampaignRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateCampaignRequest) GetUtmSource() string {
+	if x != nil {
+		return x.UtmSource
+	}
+	return ""
+}
+
+func (x *CreateCampaignRequest) GetUtmMedium() string {
+	if x != nil {
+		return x.UtmMedium
+	}
+	return ""
+}
+
+func (x *CreateCampaignRequest) GetUtmCampaign() string {
+	if x != nil {
+		return x.UtmCampaign
+	}
+	return ""
+}
+
+func (x *CreateCampaignRequest) GetUtmTerm() string {
+	if x != nil {
+		return x.UtmTerm
+	}
+	return ""
+}
+
+func (x *CreateCampaignRequest) GetUtmContent() string {
+	if x != nil {
+		return x.UtmContent
+	}
+	return ""
+}
+
+type CreateCampaignResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Campaign      *Campaign              `protobuf:"bytes,1,opt,name=campaign,proto3" json:"campaign,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCampaignResponse) Reset() {
+	*x = CreateCampaignResponse{}
+	mi := &file_proto_url_service_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCampaignResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCampaignResponse) ProtoMessage() {}
+
+func (x *CreateCampaignResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCampaignResponse.ProtoReflect.Descriptor instead.
+func (*CreateCampaignResponse) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *CreateCampaignResponse) GetCampaign() *Campaign {
+	if x != nil {
+		return x.Campaign
+	}
+	return nil
+}
+
+type GetCampaignRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	OriginalUrl   string                 `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateURLRequest) Reset() {
-	*x = CreateURLRequest{}
-	mi := &file_proto_url_service_proto_msgTypes[0]
+func (x *GetCampaignRequest) Reset() {
+	*x = GetCampaignRequest{}
+	mi := &file_proto_url_service_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateURLRequest) String() string {
+func (x *GetCampaignRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateURLRequest) ProtoMessage() {}
+func (*GetCampaignRequest) ProtoMessage() {}
 
-func (x *CreateURLRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_url_service_proto_msgTypes[0]
+func (x *GetCampaignRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -57,50 +2598,47 @@ func (x *CreateURLRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateURLRequest.ProtoReflect.Descriptor instead.
-func (*CreateURLRequest) Descriptor() ([]byte, []int) {
-	return file_proto_url_service_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use GetCampaignRequest.ProtoReflect.Descriptor instead.
+func (*GetCampaignRequest) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{30}
 }
 
-func (x *CreateURLRequest) GetOriginalUrl() string {
+func (x *GetCampaignRequest) GetId() int64 {
 	if x != nil {
-		return x.OriginalUrl
+		return x.Id
 	}
-	return ""
+	return 0
 }
 
-func (x *CreateURLRequest) GetUserId() string {
+func (x *GetCampaignRequest) GetUserId() string {
 	if x != nil {
 		return x.UserId
 	}
 	return ""
 }
 
-type CreateURLResponse struct {
+type GetCampaignResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
-	ShortUrl      string                 `protobuf:"bytes,2,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
-	Success       bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
-	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	Campaign      *Campaign              `protobuf:"bytes,1,opt,name=campaign,proto3" json:"campaign,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateURLResponse) Reset() {
-	*x = CreateURLResponse{}
-	mi := &file_proto_url_service_proto_msgTypes[1]
+func (x *GetCampaignResponse) Reset() {
+	*x = GetCampaignResponse{}
+	mi := &file_proto_url_service_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateURLResponse) String() string {
+func (x *GetCampaignResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateURLResponse) ProtoMessage() {}
+func (*GetCampaignResponse) ProtoMessage() {}
 
-func (x *CreateURLResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_url_service_proto_msgTypes[1]
+func (x *GetCampaignResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -111,61 +2649,84 @@ func (x *CreateURLResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateURLResponse.ProtoReflect.Descriptor instead.
-func (*CreateURLResponse) Descriptor() ([]byte, []int) {
-	return file_proto_url_service_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use GetCampaignResponse.ProtoReflect.Descriptor instead.
+func (*GetCampaignResponse) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{31}
 }
 
-func (x *CreateURLResponse) GetShortCode() string {
+func (x *GetCampaignResponse) GetCampaign() *Campaign {
 	if x != nil {
-		return x.ShortCode
+		return x.Campaign
 	}
-	return ""
+	return nil
 }
 
-func (x *CreateURLResponse) GetShortUrl() string {
-	if x != nil {
-		return x.ShortUrl
-	}
-	return ""
+type ListCampaignsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateURLResponse) GetSuccess() bool {
+func (x *ListCampaignsRequest) Reset() {
+	*x = ListCampaignsRequest{}
+	mi := &file_proto_url_service_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCampaignsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCampaignsRequest) ProtoMessage() {}
+
+func (x *ListCampaignsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[32]
 	if x != nil {
-		return x.Success
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return false
+	return mi.MessageOf(x)
 }
 
-func (x *CreateURLResponse) GetError() string {
+// Deprecated: Use ListCampaignsRequest.ProtoReflect.Descriptor instead.
+func (*ListCampaignsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ListCampaignsRequest) GetUserId() string {
 	if x != nil {
-		return x.Error
+		return x.UserId
 	}
 	return ""
 }
 
-type GetURLRequest struct {
+type ListCampaignsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	Campaigns     []*Campaign            `protobuf:"bytes,1,rep,name=campaigns,proto3" json:"campaigns,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetURLRequest) Reset() {
-	*x = GetURLRequest{}
-	mi := &file_proto_url_service_proto_msgTypes[2]
+func (x *ListCampaignsResponse) Reset() {
+	*x = ListCampaignsResponse{}
+	mi := &file_proto_url_service_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetURLRequest) String() string {
+func (x *ListCampaignsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetURLRequest) ProtoMessage() {}
+func (*ListCampaignsResponse) ProtoMessage() {}
 
-func (x *GetURLRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_url_service_proto_msgTypes[2]
+func (x *ListCampaignsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -176,42 +2737,48 @@ func (x *GetURLRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetURLRequest.ProtoReflect.Descriptor instead.
-func (*GetURLRequest) Descriptor() ([]byte, []int) {
-	return file_proto_url_service_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use ListCampaignsResponse.ProtoReflect.Descriptor instead.
+func (*ListCampaignsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *GetURLRequest) GetShortCode() string {
+func (x *ListCampaignsResponse) GetCampaigns() []*Campaign {
 	if x != nil {
-		return x.ShortCode
+		return x.Campaigns
 	}
-	return ""
+	return nil
 }
 
-type GetURLResponse struct {
+// Unset fields are left unchanged, set them to "" to remove a parameter
+type UpdateCampaignRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	OriginalUrl   string                 `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
-	Found         bool                   `protobuf:"varint,2,opt,name=found,proto3" json:"found,omitempty"`
-	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Name          *string                `protobuf:"bytes,3,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	UtmSource     *string                `protobuf:"bytes,4,opt,name=utm_source,json=utmSource,proto3,oneof" json:"utm_source,omitempty"`
+	UtmMedium     *string                `protobuf:"bytes,5,opt,name=utm_medium,json=utmMedium,proto3,oneof" json:"utm_medium,omitempty"`
+	UtmCampaign   *string                `protobuf:"bytes,6,opt,name=utm_campaign,json=utmCampaign,proto3,oneof" json:"utm_campaign,omitempty"`
+	UtmTerm       *string                `protobuf:"bytes,7,opt,name=utm_term,json=utmTerm,proto3,oneof" json:"utm_term,omitempty"`
+	UtmContent    *string                `protobuf:"bytes,8,opt,name=utm_content,json=utmContent,proto3,oneof" json:"utm_content,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetURLResponse) Reset() {
-	*x = GetURLResponse{}
-	mi := &file_proto_url_service_proto_msgTypes[3]
+func (x *UpdateCampaignRequest) Reset() {
+	*x = UpdateCampaignRequest{}
+	mi := &file_proto_url_service_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetURLResponse) String() string {
+func (x *UpdateCampaignRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetURLResponse) ProtoMessage() {}
+func (*UpdateCampaignRequest) ProtoMessage() {}
 
-func (x *GetURLResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_url_service_proto_msgTypes[3]
+func (x *UpdateCampaignRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -222,53 +2789,134 @@ func (x *GetURLResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetURLResponse.ProtoReflect.Descriptor instead.
-func (*GetURLResponse) Descriptor() ([]byte, []int) {
-	return file_proto_url_service_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use UpdateCampaignRequest.ProtoReflect.Descriptor instead.
+func (*UpdateCampaignRequest) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *GetURLResponse) GetOriginalUrl() string {
+func (x *UpdateCampaignRequest) GetId() int64 {
 	if x != nil {
-		return x.OriginalUrl
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateCampaignRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
 	}
 	return ""
 }
 
-func (x *GetURLResponse) GetFound() bool {
+func (x *UpdateCampaignRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *UpdateCampaignRequest) GetUtmSource() string {
+	if x != nil && x.UtmSource != nil {
+		return *x.UtmSource
+	}
+	return ""
+}
+
+func (x *UpdateCampaignRequest) GetUtmMedium() string {
+	if x != nil && x.UtmMedium != nil {
+		return *x.UtmMedium
+	}
+	return ""
+}
+
+func (x *UpdateCampaignRequest) GetUtmCampaign() string {
+	if x != nil && x.UtmCampaign != nil {
+		return *x.UtmCampaign
+	}
+	return ""
+}
+
+func (x *UpdateCampaignRequest) GetUtmTerm() string {
+	if x != nil && x.UtmTerm != nil {
+		return *x.UtmTerm
+	}
+	return ""
+}
+
+func (x *UpdateCampaignRequest) GetUtmContent() string {
+	if x != nil && x.UtmContent != nil {
+		return *x.UtmContent
+	}
+	return ""
+}
+
+type UpdateCampaignResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Campaign      *Campaign              `protobuf:"bytes,1,opt,name=campaign,proto3" json:"campaign,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateCampaignResponse) Reset() {
+	*x = UpdateCampaignResponse{}
+	mi := &file_proto_url_service_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCampaignResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCampaignResponse) ProtoMessage() {}
+
+func (x *UpdateCampaignResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[35]
 	if x != nil {
-		return x.Found
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return false
+	return mi.MessageOf(x)
 }
 
-func (x *GetURLResponse) GetError() string {
+// Deprecated: Use UpdateCampaignResponse.ProtoReflect.Descriptor instead.
+func (*UpdateCampaignResponse) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *UpdateCampaignResponse) GetCampaign() *Campaign {
 	if x != nil {
-		return x.Error
+		return x.Campaign
 	}
-	return ""
+	return nil
 }
 
-type HealthRequest struct {
+type DeleteCampaignRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthRequest) Reset() {
-	*x = HealthRequest{}
-	mi := &file_proto_url_service_proto_msgTypes[4]
+func (x *DeleteCampaignRequest) Reset() {
+	*x = DeleteCampaignRequest{}
+	mi := &file_proto_url_service_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthRequest) String() string {
+func (x *DeleteCampaignRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthRequest) ProtoMessage() {}
+func (*DeleteCampaignRequest) ProtoMessage() {}
 
-func (x *HealthRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_url_service_proto_msgTypes[4]
+func (x *DeleteCampaignRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -279,33 +2927,47 @@ func (x *HealthRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
-func (*HealthRequest) Descriptor() ([]byte, []int) {
-	return file_proto_url_service_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use DeleteCampaignRequest.ProtoReflect.Descriptor instead.
+func (*DeleteCampaignRequest) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{36}
 }
 
-type HealthResponse struct {
+func (x *DeleteCampaignRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeleteCampaignRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type DeleteCampaignResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Healthy       bool                   `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthResponse) Reset() {
-	*x = HealthResponse{}
-	mi := &file_proto_url_service_proto_msgTypes[5]
+func (x *DeleteCampaignResponse) Reset() {
+	*x = DeleteCampaignResponse{}
+	mi := &file_proto_url_service_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthResponse) String() string {
+func (x *DeleteCampaignResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthResponse) ProtoMessage() {}
+func (*DeleteCampaignResponse) ProtoMessage() {}
 
-func (x *HealthResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_url_service_proto_msgTypes[5]
+func (x *DeleteCampaignResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_url_service_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -316,14 +2978,14 @@ func (x *HealthResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
-func (*HealthResponse) Descriptor() ([]byte, []int) {
-	return file_proto_url_service_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use DeleteCampaignResponse.ProtoReflect.Descriptor instead.
+func (*DeleteCampaignResponse) Descriptor() ([]byte, []int) {
+	return file_proto_url_service_proto_rawDescGZIP(), []int{37}
 }
 
-func (x *HealthResponse) GetHealthy() bool {
+func (x *DeleteCampaignResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Healthy
+		return x.Success
 	}
 	return false
 }
@@ -333,31 +2995,350 @@ var File_proto_url_service_proto protoreflect.FileDescriptor
 const file_proto_url_service_proto_rawDesc = "" +
 	"\n" +
 	"\x17proto/url_service.proto\x12\n" +
-	"urlservice\"N\n" +
+	"urlservice\"\xd3\x06\n" +
 	"\x10CreateURLRequest\x12!\n" +
 	"\foriginal_url\x18\x01 \x01(\tR\voriginalUrl\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\tR\x06userId\"\x7f\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12!\n" +
+	"\fcustom_alias\x18\x03 \x01(\tR\vcustomAlias\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\x03R\texpiresAt\x12\x1f\n" +
+	"\vttl_seconds\x18\x05 \x01(\x03R\n" +
+	"ttlSeconds\x12\x16\n" +
+	"\x06dedupe\x18\x06 \x01(\bR\x06dedupe\x12\"\n" +
+	"\finterstitial\x18\a \x01(\bR\finterstitial\x12#\n" +
+	"\rredirect_type\x18\b \x01(\x05R\fredirectType\x12+\n" +
+	"\x11query_passthrough\x18\t \x01(\tR\x10queryPassthrough\x12)\n" +
+	"\x10path_passthrough\x18\n" +
+	" \x01(\bR\x0fpathPassthrough\x12\x1f\n" +
+	"\vcampaign_id\x18\v \x01(\x03R\n" +
+	"campaignId\x12J\n" +
+	"\n" +
+	"geo_routes\x18\f \x03(\v2+.urlservice.CreateURLRequest.GeoRoutesEntryR\tgeoRoutes\x12S\n" +
+	"\rdevice_routes\x18\r \x03(\v2..urlservice.CreateURLRequest.DeviceRoutesEntryR\fdeviceRoutes\x12/\n" +
+	"\bvariants\x18\x0e \x03(\v2\x13.urlservice.VariantR\bvariants\x12\x1f\n" +
+	"\vactive_from\x18\x0f \x01(\x03R\n" +
+	"activeFrom\x125\n" +
+	"\bschedule\x18\x10 \x03(\v2\x19.urlservice.ScheduleEntryR\bschedule\x12\x1d\n" +
+	"\n" +
+	"max_clicks\x18\x11 \x01(\x03R\tmaxClicks\x1a<\n" +
+	"\x0eGeoRoutesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a?\n" +
+	"\x11DeviceRoutesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"G\n" +
+	"\aVariant\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x16\n" +
+	"\x06weight\x18\x03 \x01(\x05R\x06weight\"I\n" +
+	"\rScheduleEntry\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\x03R\x05start\x12\x10\n" +
+	"\x03end\x18\x02 \x01(\x03R\x03end\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\"\xe9\x06\n" +
 	"\x11CreateURLResponse\x12\x1d\n" +
 	"\n" +
 	"short_code\x18\x01 \x01(\tR\tshortCode\x12\x1b\n" +
 	"\tshort_url\x18\x02 \x01(\tR\bshortUrl\x12\x18\n" +
 	"\asuccess\x18\x03 \x01(\bR\asuccess\x12\x14\n" +
-	"\x05error\x18\x04 \x01(\tR\x05error\".\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\x03R\texpiresAt\x12\x1a\n" +
+	"\bexisting\x18\x06 \x01(\bR\bexisting\x12\"\n" +
+	"\finterstitial\x18\a \x01(\bR\finterstitial\x12#\n" +
+	"\rredirect_type\x18\b \x01(\x05R\fredirectType\x12+\n" +
+	"\x11query_passthrough\x18\t \x01(\tR\x10queryPassthrough\x12)\n" +
+	"\x10path_passthrough\x18\n" +
+	" \x01(\bR\x0fpathPassthrough\x12\x1f\n" +
+	"\vcampaign_id\x18\v \x01(\x03R\n" +
+	"campaignId\x12K\n" +
+	"\n" +
+	"geo_routes\x18\f \x03(\v2,.urlservice.CreateURLResponse.GeoRoutesEntryR\tgeoRoutes\x12T\n" +
+	"\rdevice_routes\x18\r \x03(\v2/.urlservice.CreateURLResponse.DeviceRoutesEntryR\fdeviceRoutes\x12/\n" +
+	"\bvariants\x18\x0e \x03(\v2\x13.urlservice.VariantR\bvariants\x12\x1f\n" +
+	"\vactive_from\x18\x0f \x01(\x03R\n" +
+	"activeFrom\x125\n" +
+	"\bschedule\x18\x10 \x03(\v2\x19.urlservice.ScheduleEntryR\bschedule\x12\x1d\n" +
+	"\n" +
+	"max_clicks\x18\x11 \x01(\x03R\tmaxClicks\x12!\n" +
+	"\foriginal_url\x18\x12 \x01(\tR\voriginalUrl\x1a<\n" +
+	"\x0eGeoRoutesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a?\n" +
+	"\x11DeviceRoutesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa6\x01\n" +
 	"\rGetURLRequest\x12\x1d\n" +
 	"\n" +
-	"short_code\x18\x01 \x01(\tR\tshortCode\"_\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12\x1b\n" +
+	"\twith_path\x18\x02 \x01(\bR\bwithPath\x12\x1b\n" +
+	"\tclient_ip\x18\x03 \x01(\tR\bclientIp\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x04 \x01(\tR\tuserAgent\x12\x1d\n" +
+	"\n" +
+	"visitor_id\x18\x05 \x01(\tR\tvisitorId\"\xeb\x04\n" +
 	"\x0eGetURLResponse\x12!\n" +
 	"\foriginal_url\x18\x01 \x01(\tR\voriginalUrl\x12\x14\n" +
 	"\x05found\x18\x02 \x01(\bR\x05found\x12\x14\n" +
-	"\x05error\x18\x03 \x01(\tR\x05error\"\x0f\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x18\n" +
+	"\aexpired\x18\x04 \x01(\bR\aexpired\x12 \n" +
+	"\vquarantined\x18\x05 \x01(\bR\vquarantined\x12\"\n" +
+	"\finterstitial\x18\x06 \x01(\bR\finterstitial\x12#\n" +
+	"\rredirect_type\x18\a \x01(\x05R\fredirectType\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\b \x01(\x03R\texpiresAt\x12+\n" +
+	"\x11query_passthrough\x18\t \x01(\tR\x10queryPassthrough\x12)\n" +
+	"\x10path_passthrough\x18\n" +
+	" \x01(\bR\x0fpathPassthrough\x12\x1f\n" +
+	"\vcampaign_id\x18\v \x01(\x03R\n" +
+	"campaignId\x12\x18\n" +
+	"\acountry\x18\f \x01(\tR\acountry\x12\x1f\n" +
+	"\vper_visitor\x18\r \x01(\bR\n" +
+	"perVisitor\x12\x1a\n" +
+	"\bplatform\x18\x0e \x01(\tR\bplatform\x12\x18\n" +
+	"\avariant\x18\x0f \x01(\tR\avariant\x12\x1a\n" +
+	"\binactive\x18\x10 \x01(\bR\binactive\x12\x1d\n" +
+	"\n" +
+	"changes_at\x18\x11 \x01(\x03R\tchangesAt\x12\x1c\n" +
+	"\texhausted\x18\x12 \x01(\bR\texhausted\x12#\n" +
+	"\rclick_limited\x18\x13 \x01(\bR\fclickLimited\"\x0f\n" +
 	"\rHealthRequest\"*\n" +
 	"\x0eHealthResponse\x12\x18\n" +
-	"\ahealthy\x18\x01 \x01(\bR\ahealthy2\xea\x01\n" +
+	"\ahealthy\x18\x01 \x01(\bR\ahealthy\"\xb2\a\n" +
+	"\n" +
+	"URLDetails\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x02 \x01(\tR\tshortCode\x12\x1b\n" +
+	"\tshort_url\x18\x03 \x01(\tR\bshortUrl\x12!\n" +
+	"\foriginal_url\x18\x04 \x01(\tR\voriginalUrl\x12\x17\n" +
+	"\auser_id\x18\x05 \x01(\tR\x06userId\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\x03R\tupdatedAt\x12\x1f\n" +
+	"\vclick_count\x18\b \x01(\x03R\n" +
+	"clickCount\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\t \x01(\x03R\texpiresAt\x12 \n" +
+	"\vquarantined\x18\n" +
+	" \x01(\bR\vquarantined\x12\"\n" +
+	"\finterstitial\x18\v \x01(\bR\finterstitial\x12#\n" +
+	"\rredirect_type\x18\f \x01(\x05R\fredirectType\x12+\n" +
+	"\x11query_passthrough\x18\r \x01(\tR\x10queryPassthrough\x12)\n" +
+	"\x10path_passthrough\x18\x0e \x01(\bR\x0fpathPassthrough\x12\x1f\n" +
+	"\vcampaign_id\x18\x0f \x01(\x03R\n" +
+	"campaignId\x12D\n" +
+	"\n" +
+	"geo_routes\x18\x10 \x03(\v2%.urlservice.URLDetails.GeoRoutesEntryR\tgeoRoutes\x12M\n" +
+	"\rdevice_routes\x18\x11 \x03(\v2(.urlservice.URLDetails.DeviceRoutesEntryR\fdeviceRoutes\x12/\n" +
+	"\bvariants\x18\x12 \x03(\v2\x13.urlservice.VariantR\bvariants\x12\x1f\n" +
+	"\vactive_from\x18\x13 \x01(\x03R\n" +
+	"activeFrom\x125\n" +
+	"\bschedule\x18\x14 \x03(\v2\x19.urlservice.ScheduleEntryR\bschedule\x12\x1d\n" +
+	"\n" +
+	"max_clicks\x18\x15 \x01(\x03R\tmaxClicks\x1a<\n" +
+	"\x0eGeoRoutesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a?\n" +
+	"\x11DeviceRoutesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xaf\t\n" +
+	"\x10UpdateURLRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12!\n" +
+	"\foriginal_url\x18\x03 \x01(\tR\voriginalUrl\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\x03R\texpiresAt\x12\x1f\n" +
+	"\vttl_seconds\x18\x05 \x01(\x03R\n" +
+	"ttlSeconds\x12!\n" +
+	"\fclear_expiry\x18\x06 \x01(\bR\vclearExpiry\x12'\n" +
+	"\finterstitial\x18\a \x01(\bH\x00R\finterstitial\x88\x01\x01\x12#\n" +
+	"\rredirect_type\x18\b \x01(\x05R\fredirectType\x12+\n" +
+	"\x11query_passthrough\x18\t \x01(\tR\x10queryPassthrough\x12.\n" +
+	"\x10path_passthrough\x18\n" +
+	" \x01(\bH\x01R\x0fpathPassthrough\x88\x01\x01\x12\x1f\n" +
+	"\vcampaign_id\x18\v \x01(\x03R\n" +
+	"campaignId\x12%\n" +
+	"\x0eclear_campaign\x18\f \x01(\bR\rclearCampaign\x12J\n" +
+	"\n" +
+	"geo_routes\x18\r \x03(\v2+.urlservice.UpdateURLRequest.GeoRoutesEntryR\tgeoRoutes\x12(\n" +
+	"\x10clear_geo_routes\x18\x0e \x01(\bR\x0eclearGeoRoutes\x12S\n" +
+	"\rdevice_routes\x18\x0f \x03(\v2..urlservice.UpdateURLRequest.DeviceRoutesEntryR\fdeviceRoutes\x12.\n" +
+	"\x13clear_device_routes\x18\x10 \x01(\bR\x11clearDeviceRoutes\x12/\n" +
+	"\bvariants\x18\x11 \x03(\v2\x13.urlservice.VariantR\bvariants\x12%\n" +
+	"\x0eclear_variants\x18\x12 \x01(\bR\rclearVariants\x12\x1f\n" +
+	"\vactive_from\x18\x13 \x01(\x03R\n" +
+	"activeFrom\x12*\n" +
+	"\x11clear_active_from\x18\x14 \x01(\bR\x0fclearActiveFrom\x125\n" +
+	"\bschedule\x18\x15 \x03(\v2\x19.urlservice.ScheduleEntryR\bschedule\x12%\n" +
+	"\x0eclear_schedule\x18\x16 \x01(\bR\rclearSchedule\x12\x1d\n" +
+	"\n" +
+	"max_clicks\x18\x17 \x01(\x03R\tmaxClicks\x12(\n" +
+	"\x10clear_max_clicks\x18\x18 \x01(\bR\x0eclearMaxClicks\x1a<\n" +
+	"\x0eGeoRoutesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a?\n" +
+	"\x11DeviceRoutesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x0f\n" +
+	"\r_interstitialB\x13\n" +
+	"\x11_path_passthrough\"=\n" +
+	"\x11UpdateURLResponse\x12(\n" +
+	"\x03url\x18\x01 \x01(\v2\x16.urlservice.URLDetailsR\x03url\"J\n" +
+	"\x10DeleteURLRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"-\n" +
+	"\x11DeleteURLResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"N\n" +
+	"\x14GetURLDetailsRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"A\n" +
+	"\x15GetURLDetailsResponse\x12(\n" +
+	"\x03url\x18\x01 \x01(\v2\x16.urlservice.URLDetailsR\x03url\"X\n" +
+	"\x0fListURLsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\">\n" +
+	"\x10ListURLsResponse\x12*\n" +
+	"\x04urls\x18\x01 \x03(\v2\x16.urlservice.URLDetailsR\x04urls\"5\n" +
+	"\x14GetURLPreviewRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\"\x92\x03\n" +
+	"\x15GetURLPreviewResponse\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12!\n" +
+	"\foriginal_url\x18\x02 \x01(\tR\voriginalUrl\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\x03R\tcreatedAt\x12\x1f\n" +
+	"\vclick_count\x18\x04 \x01(\x03R\n" +
+	"clickCount\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\x03R\texpiresAt\x12\x18\n" +
+	"\aexpired\x18\x06 \x01(\bR\aexpired\x12 \n" +
+	"\vquarantined\x18\a \x01(\bR\vquarantined\x12\"\n" +
+	"\finterstitial\x18\b \x01(\bR\finterstitial\x12\x1f\n" +
+	"\vactive_from\x18\t \x01(\x03R\n" +
+	"activeFrom\x12\x1a\n" +
+	"\binactive\x18\n" +
+	" \x01(\bR\binactive\x12\x1d\n" +
+	"\n" +
+	"max_clicks\x18\v \x01(\x03R\tmaxClicks\x12\x1c\n" +
+	"\texhausted\x18\f \x01(\bR\texhausted\"A\n" +
+	"\x12IssueAPIKeyRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"]\n" +
+	"\x13IssueAPIKeyResponse\x12\x15\n" +
+	"\x06key_id\x18\x01 \x01(\x03R\x05keyId\x12\x17\n" +
+	"\aapi_key\x18\x02 \x01(\tR\x06apiKey\x12\x16\n" +
+	"\x06prefix\x18\x03 \x01(\tR\x06prefix\"E\n" +
+	"\x13RevokeAPIKeyRequest\x12\x15\n" +
+	"\x06key_id\x18\x01 \x01(\x03R\x05keyId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"0\n" +
+	"\x14RevokeAPIKeyResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"0\n" +
+	"\x15ValidateAPIKeyRequest\x12\x17\n" +
+	"\aapi_key\x18\x01 \x01(\tR\x06apiKey\"^\n" +
+	"\x16ValidateAPIKeyResponse\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x15\n" +
+	"\x06key_id\x18\x03 \x01(\x03R\x05keyId\"m\n" +
+	"\x17UpdateThreatListRequest\x12\x1c\n" +
+	"\tadditions\x18\x01 \x03(\fR\tadditions\x12\x1a\n" +
+	"\bremovals\x18\x02 \x03(\fR\bremovals\x12\x18\n" +
+	"\areplace\x18\x03 \x01(\bR\areplace\"`\n" +
+	"\x18UpdateThreatListResponse\x12\x14\n" +
+	"\x05added\x18\x01 \x01(\x03R\x05added\x12\x18\n" +
+	"\aremoved\x18\x02 \x01(\x03R\aremoved\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x03R\x05total\"\xa2\x02\n" +
+	"\bCampaign\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"utm_source\x18\x04 \x01(\tR\tutmSource\x12\x1d\n" +
+	"\n" +
+	"utm_medium\x18\x05 \x01(\tR\tutmMedium\x12!\n" +
+	"\futm_campaign\x18\x06 \x01(\tR\vutmCampaign\x12\x19\n" +
+	"\butm_term\x18\a \x01(\tR\autmTerm\x12\x1f\n" +
+	"\vutm_content\x18\b \x01(\tR\n" +
+	"utmContent\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\t \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\x03R\tupdatedAt\"\xe1\x01\n" +
+	"\x15CreateCampaignRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"utm_source\x18\x03 \x01(\tR\tutmSource\x12\x1d\n" +
+	"\n" +
+	"utm_medium\x18\x04 \x01(\tR\tutmMedium\x12!\n" +
+	"\futm_campaign\x18\x05 \x01(\tR\vutmCampaign\x12\x19\n" +
+	"\butm_term\x18\x06 \x01(\tR\autmTerm\x12\x1f\n" +
+	"\vutm_content\x18\a \x01(\tR\n" +
+	"utmContent\"J\n" +
+	"\x16CreateCampaignResponse\x120\n" +
+	"\bcampaign\x18\x01 \x01(\v2\x14.urlservice.CampaignR\bcampaign\"=\n" +
+	"\x12GetCampaignRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"G\n" +
+	"\x13GetCampaignResponse\x120\n" +
+	"\bcampaign\x18\x01 \x01(\v2\x14.urlservice.CampaignR\bcampaign\"/\n" +
+	"\x14ListCampaignsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"K\n" +
+	"\x15ListCampaignsResponse\x122\n" +
+	"\tcampaigns\x18\x01 \x03(\v2\x14.urlservice.CampaignR\tcampaigns\"\xe4\x02\n" +
+	"\x15UpdateCampaignRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x17\n" +
+	"\x04name\x18\x03 \x01(\tH\x00R\x04name\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"utm_source\x18\x04 \x01(\tH\x01R\tutmSource\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"utm_medium\x18\x05 \x01(\tH\x02R\tutmMedium\x88\x01\x01\x12&\n" +
+	"\futm_campaign\x18\x06 \x01(\tH\x03R\vutmCampaign\x88\x01\x01\x12\x1e\n" +
+	"\butm_term\x18\a \x01(\tH\x04R\autmTerm\x88\x01\x01\x12$\n" +
+	"\vutm_content\x18\b \x01(\tH\x05R\n" +
+	"utmContent\x88\x01\x01B\a\n" +
+	"\x05_nameB\r\n" +
+	"\v_utm_sourceB\r\n" +
+	"\v_utm_mediumB\x0f\n" +
+	"\r_utm_campaignB\v\n" +
+	"\t_utm_termB\x0e\n" +
+	"\f_utm_content\"J\n" +
+	"\x16UpdateCampaignResponse\x120\n" +
+	"\bcampaign\x18\x01 \x01(\v2\x14.urlservice.CampaignR\bcampaign\"@\n" +
+	"\x15DeleteCampaignRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"2\n" +
+	"\x16DeleteCampaignResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess2\xfb\x04\n" +
 	"\n" +
 	"URLService\x12M\n" +
 	"\x0eCreateShortURL\x12\x1c.urlservice.CreateURLRequest\x1a\x1d.urlservice.CreateURLResponse\x12G\n" +
 	"\x0eGetOriginalURL\x12\x19.urlservice.GetURLRequest\x1a\x1a.urlservice.GetURLResponse\x12D\n" +
-	"\vHealthCheck\x12\x19.urlservice.HealthRequest\x1a\x1a.urlservice.HealthResponseB+Z)github.com/sammyqtran/url-shortener/protob\x06proto3"
+	"\vHealthCheck\x12\x19.urlservice.HealthRequest\x1a\x1a.urlservice.HealthResponse\x12M\n" +
+	"\x0eUpdateShortURL\x12\x1c.urlservice.UpdateURLRequest\x1a\x1d.urlservice.UpdateURLResponse\x12M\n" +
+	"\x0eDeleteShortURL\x12\x1c.urlservice.DeleteURLRequest\x1a\x1d.urlservice.DeleteURLResponse\x12T\n" +
+	"\rGetURLDetails\x12 .urlservice.GetURLDetailsRequest\x1a!.urlservice.GetURLDetailsResponse\x12E\n" +
+	"\bListURLs\x12\x1b.urlservice.ListURLsRequest\x1a\x1c.urlservice.ListURLsResponse\x12T\n" +
+	"\rGetURLPreview\x12 .urlservice.GetURLPreviewRequest\x1a!.urlservice.GetURLPreviewResponse2\x8b\x02\n" +
+	"\rAPIKeyService\x12N\n" +
+	"\vIssueAPIKey\x12\x1e.urlservice.IssueAPIKeyRequest\x1a\x1f.urlservice.IssueAPIKeyResponse\x12Q\n" +
+	"\fRevokeAPIKey\x12\x1f.urlservice.RevokeAPIKeyRequest\x1a .urlservice.RevokeAPIKeyResponse\x12W\n" +
+	"\x0eValidateAPIKey\x12!.urlservice.ValidateAPIKeyRequest\x1a\".urlservice.ValidateAPIKeyResponse2r\n" +
+	"\x11ThreatListService\x12]\n" +
+	"\x10UpdateThreatList\x12#.urlservice.UpdateThreatListRequest\x1a$.urlservice.UpdateThreatListResponse2\xc2\x03\n" +
+	"\x0fCampaignService\x12W\n" +
+	"\x0eCreateCampaign\x12!.urlservice.CreateCampaignRequest\x1a\".urlservice.CreateCampaignResponse\x12N\n" +
+	"\vGetCampaign\x12\x1e.urlservice.GetCampaignRequest\x1a\x1f.urlservice.GetCampaignResponse\x12T\n" +
+	"\rListCampaigns\x12 .urlservice.ListCampaignsRequest\x1a!.urlservice.ListCampaignsResponse\x12W\n" +
+	"\x0eUpdateCampaign\x12!.urlservice.UpdateCampaignRequest\x1a\".urlservice.UpdateCampaignResponse\x12W\n" +
+	"\x0eDeleteCampaign\x12!.urlservice.DeleteCampaignRequest\x1a\".urlservice.DeleteCampaignResponseB+Z)github.com/sammyqtran/url-shortener/protob\x06proto3"
 
 var (
 	file_proto_url_service_proto_rawDescOnce sync.Once
@@ -371,27 +3352,118 @@ func file_proto_url_service_proto_rawDescGZIP() []byte {
 	return file_proto_url_service_proto_rawDescData
 }
 
-var file_proto_url_service_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_proto_url_service_proto_msgTypes = make([]protoimpl.MessageInfo, 46)
 var file_proto_url_service_proto_goTypes = []any{
-	(*CreateURLRequest)(nil),  // 0: urlservice.CreateURLRequest
-	(*CreateURLResponse)(nil), // 1: urlservice.CreateURLResponse
-	(*GetURLRequest)(nil),     // 2: urlservice.GetURLRequest
-	(*GetURLResponse)(nil),    // 3: urlservice.GetURLResponse
-	(*HealthRequest)(nil),     // 4: urlservice.HealthRequest
-	(*HealthResponse)(nil),    // 5: urlservice.HealthResponse
+	(*CreateURLRequest)(nil),         // 0: urlservice.CreateURLRequest
+	(*Variant)(nil),                  // 1: urlservice.Variant
+	(*ScheduleEntry)(nil),            // 2: urlservice.ScheduleEntry
+	(*CreateURLResponse)(nil),        // 3: urlservice.CreateURLResponse
+	(*GetURLRequest)(nil),            // 4: urlservice.GetURLRequest
+	(*GetURLResponse)(nil),           // 5: urlservice.GetURLResponse
+	(*HealthRequest)(nil),            // 6: urlservice.HealthRequest
+	(*HealthResponse)(nil),           // 7: urlservice.HealthResponse
+	(*URLDetails)(nil),               // 8: urlservice.URLDetails
+	(*UpdateURLRequest)(nil),         // 9: urlservice.UpdateURLRequest
+	(*UpdateURLResponse)(nil),        // 10: urlservice.UpdateURLResponse
+	(*DeleteURLRequest)(nil),         // 11: urlservice.DeleteURLRequest
+	(*DeleteURLResponse)(nil),        // 12: urlservice.DeleteURLResponse
+	(*GetURLDetailsRequest)(nil),     // 13: urlservice.GetURLDetailsRequest
+	(*GetURLDetailsResponse)(nil),    // 14: urlservice.GetURLDetailsResponse
+	(*ListURLsRequest)(nil),          // 15: urlservice.ListURLsRequest
+	(*ListURLsResponse)(nil),         // 16: urlservice.ListURLsResponse
+	(*GetURLPreviewRequest)(nil),     // 17: urlservice.GetURLPreviewRequest
+	(*GetURLPreviewResponse)(nil),    // 18: urlservice.GetURLPreviewResponse
+	(*IssueAPIKeyRequest)(nil),       // 19: urlservice.IssueAPIKeyRequest
+	(*IssueAPIKeyResponse)(nil),      // 20: urlservice.IssueAPIKeyResponse
+	(*RevokeAPIKeyRequest)(nil),      // 21: urlservice.RevokeAPIKeyRequest
+	(*RevokeAPIKeyResponse)(nil),     // 22: urlservice.RevokeAPIKeyResponse
+	(*ValidateAPIKeyRequest)(nil),    // 23: urlservice.ValidateAPIKeyRequest
+	(*ValidateAPIKeyResponse)(nil),   // 24: urlservice.ValidateAPIKeyResponse
+	(*UpdateThreatListRequest)(nil),  // 25: urlservice.UpdateThreatListRequest
+	(*UpdateThreatListResponse)(nil), // 26: urlservice.UpdateThreatListResponse
+	(*Campaign)(nil),                 // 27: urlservice.Campaign
+	(*CreateCampaignRequest)(nil),    // 28: urlservice.CreateCampaignRequest
+	(*CreateCampaignResponse)(nil),   // 29: urlservice.CreateCampaignResponse
+	(*GetCampaignRequest)(nil),       // 30: urlservice.GetCampaignRequest
+	(*GetCampaignResponse)(nil),      // 31: urlservice.GetCampaignResponse
+	(*ListCampaignsRequest)(nil),     // 32: urlservice.ListCampaignsRequest
+	(*ListCampaignsResponse)(nil),    // 33: urlservice.ListCampaignsResponse
+	(*UpdateCampaignRequest)(nil),    // 34: urlservice.UpdateCampaignRequest
+	(*UpdateCampaignResponse)(nil),   // 35: urlservice.UpdateCampaignResponse
+	(*DeleteCampaignRequest)(nil),    // 36: urlservice.DeleteCampaignRequest
+	(*DeleteCampaignResponse)(nil),   // 37: urlservice.DeleteCampaignResponse
+	nil,                              // 38: urlservice.CreateURLRequest.GeoRoutesEntry
+	nil,                              // 39: urlservice.CreateURLRequest.DeviceRoutesEntry
+	nil,                              // 40: urlservice.CreateURLResponse.GeoRoutesEntry
+	nil,                              // 41: urlservice.CreateURLResponse.DeviceRoutesEntry
+	nil,                              // 42: urlservice.URLDetails.GeoRoutesEntry
+	nil,                              // 43: urlservice.URLDetails.DeviceRoutesEntry
+	nil,                              // 44: urlservice.UpdateURLRequest.GeoRoutesEntry
+	nil,                              // 45: urlservice.UpdateURLRequest.DeviceRoutesEntry
 }
 var file_proto_url_service_proto_depIdxs = []int32{
-	0, // 0: urlservice.URLService.CreateShortURL:input_type -> urlservice.CreateURLRequest
-	2, // 1: urlservice.URLService.GetOriginalURL:input_type -> urlservice.GetURLRequest
-	4, // 2: urlservice.URLService.HealthCheck:input_type -> urlservice.HealthRequest
-	1, // 3: urlservice.URLService.CreateShortURL:output_type -> urlservice.CreateURLResponse
-	3, // 4: urlservice.URLService.GetOriginalURL:output_type -> urlservice.GetURLResponse
-	5, // 5: urlservice.URLService.HealthCheck:output_type -> urlservice.HealthResponse
-	3, // [3:6] is the sub-list for method output_type
-	0, // [0:3] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	38, // 0: urlservice.CreateURLRequest.geo_routes:type_name -> urlservice.CreateURLRequest.GeoRoutesEntry
+	39, // 1: urlservice.CreateURLRequest.device_routes:type_name -> urlservice.CreateURLRequest.DeviceRoutesEntry
+	1,  // 2: urlservice.CreateURLRequest.variants:type_name -> urlservice.Variant
+	2,  // 3: urlservice.CreateURLRequest.schedule:type_name -> urlservice.ScheduleEntry
+	40, // 4: urlservice.CreateURLResponse.geo_routes:type_name -> urlservice.CreateURLResponse.GeoRoutesEntry
+	41, // 5: urlservice.CreateURLResponse.device_routes:type_name -> urlservice.CreateURLResponse.DeviceRoutesEntry
+	1,  // 6: urlservice.CreateURLResponse.variants:type_name -> urlservice.Variant
+	2,  // 7: urlservice.CreateURLResponse.schedule:type_name -> urlservice.ScheduleEntry
+	42, // 8: urlservice.URLDetails.geo_routes:type_name -> urlservice.URLDetails.GeoRoutesEntry
+	43, // 9: urlservice.URLDetails.device_routes:type_name -> urlservice.URLDetails.DeviceRoutesEntry
+	1,  // 10: urlservice.URLDetails.variants:type_name -> urlservice.Variant
+	2,  // 11: urlservice.URLDetails.schedule:type_name -> urlservice.ScheduleEntry
+	44, // 12: urlservice.UpdateURLRequest.geo_routes:type_name -> urlservice.UpdateURLRequest.GeoRoutesEntry
+	45, // 13: urlservice.UpdateURLRequest.device_routes:type_name -> urlservice.UpdateURLRequest.DeviceRoutesEntry
+	1,  // 14: urlservice.UpdateURLRequest.variants:type_name -> urlservice.Variant
+	2,  // 15: urlservice.UpdateURLRequest.schedule:type_name -> urlservice.ScheduleEntry
+	8,  // 16: urlservice.UpdateURLResponse.url:type_name -> urlservice.URLDetails
+	8,  // 17: urlservice.GetURLDetailsResponse.url:type_name -> urlservice.URLDetails
+	8,  // 18: urlservice.ListURLsResponse.urls:type_name -> urlservice.URLDetails
+	27, // 19: urlservice.CreateCampaignResponse.campaign:type_name -> urlservice.Campaign
+	27, // 20: urlservice.GetCampaignResponse.campaign:type_name -> urlservice.Campaign
+	27, // 21: urlservice.ListCampaignsResponse.campaigns:type_name -> urlservice.Campaign
+	27, // 22: urlservice.UpdateCampaignResponse.campaign:type_name -> urlservice.Campaign
+	0,  // 23: urlservice.URLService.CreateShortURL:input_type -> urlservice.CreateURLRequest
+	4,  // 24: urlservice.URLService.GetOriginalURL:input_type -> urlservice.GetURLRequest
+	6,  // 25: urlservice.URLService.HealthCheck:input_type -> urlservice.HealthRequest
+	9,  // 26: urlservice.URLService.UpdateShortURL:input_type -> urlservice.UpdateURLRequest
+	11, // 27: urlservice.URLService.DeleteShortURL:input_type -> urlservice.DeleteURLRequest
+	13, // 28: urlservice.URLService.GetURLDetails:input_type -> urlservice.GetURLDetailsRequest
+	15, // 29: urlservice.URLService.ListURLs:input_type -> urlservice.ListURLsRequest
+	17, // 30: urlservice.URLService.GetURLPreview:input_type -> urlservice.GetURLPreviewRequest
+	19, // 31: urlservice.APIKeyService.IssueAPIKey:input_type -> urlservice.IssueAPIKeyRequest
+	21, // 32: urlservice.APIKeyService.RevokeAPIKey:input_type -> urlservice.RevokeAPIKeyRequest
+	23, // 33: urlservice.APIKeyService.ValidateAPIKey:input_type -> urlservice.ValidateAPIKeyRequest
+	25, // 34: urlservice.ThreatListService.UpdateThreatList:input_type -> urlservice.UpdateThreatListRequest
+	28, // 35: urlservice.CampaignService.CreateCampaign:input_type -> urlservice.CreateCampaignRequest
+	30, // 36: urlservice.CampaignService.GetCampaign:input_type -> urlservice.GetCampaignRequest
+	32, // 37: urlservice.CampaignService.ListCampaigns:input_type -> urlservice.ListCampaignsRequest
+	34, // 38: urlservice.CampaignService.UpdateCampaign:input_type -> urlservice.UpdateCampaignRequest
+	36, // 39: urlservice.CampaignService.DeleteCampaign:input_type -> urlservice.DeleteCampaignRequest
+	3,  // 40: urlservice.URLService.CreateShortURL:output_type -> urlservice.CreateURLResponse
+	5,  // 41: urlservice.URLService.GetOriginalURL:output_type -> urlservice.GetURLResponse
+	7,  // 42: urlservice.URLService.HealthCheck:output_type -> urlservice.HealthResponse
+	10, // 43: urlservice.URLService.UpdateShortURL:output_type -> urlservice.UpdateURLResponse
+	12, // 44: urlservice.URLService.DeleteShortURL:output_type -> urlservice.DeleteURLResponse
+	14, // 45: urlservice.URLService.GetURLDetails:output_type -> urlservice.GetURLDetailsResponse
+	16, // 46: urlservice.URLService.ListURLs:output_type -> urlservice.ListURLsResponse
+	18, // 47: urlservice.URLService.GetURLPreview:output_type -> urlservice.GetURLPreviewResponse
+	20, // 48: urlservice.APIKeyService.IssueAPIKey:output_type -> urlservice.IssueAPIKeyResponse
+	22, // 49: urlservice.APIKeyService.RevokeAPIKey:output_type -> urlservice.RevokeAPIKeyResponse
+	24, // 50: urlservice.APIKeyService.ValidateAPIKey:output_type -> urlservice.ValidateAPIKeyResponse
+	26, // 51: urlservice.ThreatListService.UpdateThreatList:output_type -> urlservice.UpdateThreatListResponse
+	29, // 52: urlservice.CampaignService.CreateCampaign:output_type -> urlservice.CreateCampaignResponse
+	31, // 53: urlservice.CampaignService.GetCampaign:output_type -> urlservice.GetCampaignResponse
+	33, // 54: urlservice.CampaignService.ListCampaigns:output_type -> urlservice.ListCampaignsResponse
+	35, // 55: urlservice.CampaignService.UpdateCampaign:output_type -> urlservice.UpdateCampaignResponse
+	37, // 56: urlservice.CampaignService.DeleteCampaign:output_type -> urlservice.DeleteCampaignResponse
+	40, // [40:57] is the sub-list for method output_type
+	23, // [23:40] is the sub-list for method input_type
+	23, // [23:23] is the sub-list for extension type_name
+	23, // [23:23] is the sub-list for extension extendee
+	0,  // [0:23] is the sub-list for field type_name
 }
 
 func init() { file_proto_url_service_proto_init() }
@@ -399,15 +3471,17 @@ func file_proto_url_service_proto_init() {
 	if File_proto_url_service_proto != nil {
 		return
 	}
+	file_proto_url_service_proto_msgTypes[9].OneofWrappers = []any{}
+	file_proto_url_service_proto_msgTypes[34].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_url_service_proto_rawDesc), len(file_proto_url_service_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   46,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   4,
 		},
 		GoTypes:           file_proto_url_service_proto_goTypes,
 		DependencyIndexes: file_proto_url_service_proto_depIdxs,
@@ -14,7 +14,7 @@ export default function() {
   // Create short URL
   let payload = JSON.stringify({ url: "https://example.com" });
   let createRes = http.post('http://localhost:8080/create', payload, {
-    headers: { 'Content-Type': 'application/json' },
+    headers: { 'Content-Type': 'application/json', 'Authorization': `Bearer ${__ENV.API_KEY}` },
   });
   check(createRes, { "create status is 200": (r) => r.status === 200 });
 
@@ -38,7 +38,7 @@ let getRes = http.get(`http://localhost:8080/${shortcode}`, { redirects: 0 });
   // bad request
   payload = JSON.stringify({ url: "example.com" });
   createRes = http.post('http://localhost:8080/create', payload, {
-    headers: { 'Content-Type': 'application/json' },
+    headers: { 'Content-Type': 'application/json', 'Authorization': `Bearer ${__ENV.API_KEY}` },
   });
   check(createRes, { "create status is 400 or error": (r) => r.status >= 400 });
 